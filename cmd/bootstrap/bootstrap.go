@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/cmd/worker"
@@ -29,6 +30,19 @@ func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lock
 	installmentRepo := repository.NewIntallmentRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
 	userDocumentRepo := repository.NewDocumentRepository(db)
+	penaltyPolicyRepo := repository.NewPenaltyPolicyRepository(db)
+	penaltyRepo := repository.NewPenaltyRepository(db)
+	paymentCallbackRepo := repository.NewPaymentCallbackRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	limitPolicyRepo := repository.NewLimitPolicyRepository(db)
+	creditScoreRepo := repository.NewCreditScoreRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	holidayRepo := repository.NewHolidayRepository(db)
+	merchantRepo := repository.NewMerchantRepository(db)
+	disbursementRepo := repository.NewDisbursementRepository(db)
+	partnerKeyRepo := repository.NewPartnerKeyRepository(db)
+	partnerConsentRepo := repository.NewPartnerConsentRepository(db)
+	checkoutSessionRepo := repository.NewCheckoutSessionRepository(db)
 
 	// Service
 	userService := service.NewUserService(userRepo, roleRepo)
@@ -36,13 +50,22 @@ func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lock
 	moduleService := service.NewModuleService(moduleRepo)
 	roleService := service.NewRoleService(roleRepo, permissionRepo)
 	authService := service.NewAuthService(refreshTokenRepo, userRepo)
-	registrationService := service.NewRegistrationService(userRepo, roleRepo, limitRepo)
+	registrationService := service.NewRegistrationService(userRepo, roleRepo, limitRepo, limitPolicyRepo, installmentRepo, creditScoreRepo)
 	profileService := service.NewProfileService(userRepo, profileRepo)
-	limitService := service.NewLimitService(userRepo, limitRepo)
-	transactionService := service.NewTransactionService(transactionRepo, userRepo, limitRepo, installmentRepo, lockRedis)
+	limitService := service.NewLimitService(userRepo, limitRepo, lockRedis)
+	limitPolicyService := service.NewLimitPolicyService(limitPolicyRepo, limitRepo, userRepo, lockRedis)
+	transactionService := service.NewTransactionService(transactionRepo, userRepo, limitRepo, installmentRepo, ledgerRepo, productRepo, holidayRepo, creditScoreRepo, merchantRepo, disbursementRepo, partnerConsentRepo, checkoutSessionRepo, lockRedis)
 	installmentService := service.NewInstallmentService(installmentRepo, userRepo)
-	paymentService := service.NewPaymentService(paymentRepo, userRepo, transactionRepo, installmentRepo, limitRepo, lockRedis)
+	paymentService := service.NewPaymentService(paymentRepo, userRepo, transactionRepo, installmentRepo, limitRepo, penaltyRepo, paymentCallbackRepo, ledgerRepo, lockRedis)
 	userDocumentService := service.NewDocumentService(userRepo, userDocumentRepo)
+	penaltyService := service.NewPenaltyService(penaltyPolicyRepo, penaltyRepo, installmentRepo, lockRedis)
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	creditScoreService := service.NewCreditScoreService(creditScoreRepo, userRepo, installmentRepo)
+	productService := service.NewProductService(productRepo)
+	holidayService := service.NewHolidayService(holidayRepo)
+	merchantService := service.NewMerchantService(merchantRepo)
+	disbursementService := service.NewDisbursementService(disbursementRepo)
+	partnerService := service.NewPartnerService(partnerKeyRepo, partnerConsentRepo, merchantRepo, userRepo, limitRepo)
 
 	// Handler
 	userHandler := handler.NewUserHandler(userService)
@@ -53,10 +76,20 @@ func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lock
 	registrationHandler := handler.NewRegistrationHandler(registrationService)
 	profileHandler := handler.NewProfileHandler(profileService)
 	limitHandler := handler.NewLimitHandler(limitService)
+	limitPolicyHandler := handler.NewLimitPolicyHandler(limitPolicyService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 	installmentHandler := handler.NewInstallmetHandler(installmentService)
 	paymentHandler := handler.NewPaymentHandler(paymentService)
 	userDocumentHandler := handler.NewDocumentHandler(userDocumentService)
+	penaltyHandler := handler.NewPenaltyHandler(penaltyService)
+	webhookHandler := handler.NewWebhookHandler(paymentService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	creditScoreHandler := handler.NewCreditScoreHandler(creditScoreService)
+	productHandler := handler.NewProductHandler(productService)
+	holidayHandler := handler.NewHolidayHandler(holidayService)
+	merchantHandler := handler.NewMerchantHandler(merchantService)
+	disbursementHandler := handler.NewDisbursementHandler(disbursementService)
+	partnerHandler := handler.NewPartnerHandler(partnerService, transactionService)
 
 	// Setup handler to send to routes setup
 	handler := &handler.Handlers{
@@ -69,18 +102,59 @@ func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lock
 			DocumentHandler:   userDocumentHandler,
 		},
 		TransactionManagementHandler: &handler.TransactionManagementHandler{
-			LimitHandler:       limitHandler,
-			TransactionHandler: transactionHandler,
-			InstallmentHandler: installmentHandler,
-			PaymentHandler:     paymentHandler,
+			LimitHandler:        limitHandler,
+			LimitPolicyHandler:  limitPolicyHandler,
+			ProductHandler:      productHandler,
+			TransactionHandler:  transactionHandler,
+			InstallmentHandler:  installmentHandler,
+			PaymentHandler:      paymentHandler,
+			PenaltyHandler:      penaltyHandler,
+			LedgerHandler:       ledgerHandler,
+			CreditScoreHandler:  creditScoreHandler,
+			HolidayHandler:      holidayHandler,
+			MerchantHandler:     merchantHandler,
+			DisbursementHandler: disbursementHandler,
+			PartnerHandler:      partnerHandler,
 		},
 		AuthHandler:         authHandler,
 		RegistrationHandler: registrationHandler,
+		WebhookHandler:      webhookHandler,
 	}
 
+	middleware.InitIdempotency(cacheRedis)
+	middleware.InitPartnerSignature(partnerKeyRepo, cacheRedis)
+
 	routes.Setup(app, handler)
 }
 
+// InitWorker start background jobs that need database and redis
+func InitWorker(db *gorm.DB, lockRedis *redis.LockClient) {
+	userRepo := repository.NewUserRepository(db)
+	installmentRepo := repository.NewIntallmentRepository(db)
+	penaltyPolicyRepo := repository.NewPenaltyPolicyRepository(db)
+	penaltyRepo := repository.NewPenaltyRepository(db)
+	limitRepo := repository.NewLimitRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	holidayRepo := repository.NewHolidayRepository(db)
+	creditScoreRepo := repository.NewCreditScoreRepository(db)
+	merchantRepo := repository.NewMerchantRepository(db)
+	disbursementRepo := repository.NewDisbursementRepository(db)
+	partnerConsentRepo := repository.NewPartnerConsentRepository(db)
+	checkoutSessionRepo := repository.NewCheckoutSessionRepository(db)
+
+	installmentService := service.NewInstallmentService(installmentRepo, userRepo)
+	penaltyService := service.NewPenaltyService(penaltyPolicyRepo, penaltyRepo, installmentRepo, lockRedis)
+	transactionService := service.NewTransactionService(transactionRepo, userRepo, limitRepo, installmentRepo, ledgerRepo, productRepo, holidayRepo, creditScoreRepo, merchantRepo, disbursementRepo, partnerConsentRepo, checkoutSessionRepo, lockRedis)
+
+	overdueInterval := time.Duration(config.AppConfig.OverdueJobInterval) * time.Minute
+	worker.StartOverdueWorker(installmentService, penaltyService, lockRedis, overdueInterval)
+
+	checkoutInterval := time.Duration(config.AppConfig.CheckoutJobInterval) * time.Minute
+	worker.StartCheckoutWorker(transactionService, lockRedis, checkoutInterval)
+}
+
 func InitApp() {
 	if err := config.LoadConfig(); err != nil {
 		log.Println(err.Error())
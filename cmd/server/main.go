@@ -38,6 +38,8 @@ func main() {
 		app, db, redisClient.CacheClient, redisClient.LockClient,
 	)
 
+	bootstrap.InitWorker(db, redisClient.LockClient)
+
 	app.Static("/files", "./storage/uploads", fiber.Static{
 		Browse: false,
 		MaxAge: 3600,
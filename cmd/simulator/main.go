@@ -0,0 +1,86 @@
+// Simulator fire signed payment gateway callbacks to the webhook endpoint, so the
+// gateway flow can be tested end to end without the real provider.
+//
+//	go run ./cmd/simulator -transaction <uuid> -amount 150000
+//	go run ./cmd/simulator -installment <uuid> -event qr.paid -amount 50000 -repeat 2
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+func main() {
+	if err := config.LoadConfig(); err != nil {
+		log.Println(err.Error())
+	}
+	cfg := config.AppConfig
+
+	url := flag.String("url", fmt.Sprintf("http://localhost:%s/api/v1/webhooks/payment", cfg.Port), "webhook endpoint")
+	secret := flag.String("secret", cfg.PaymentWebhookSecret, "HMAC secret, default PAYMENT_WEBHOOK_SECRET")
+	provider := flag.String("provider", "simulator", "gateway provider name")
+	providerTransactionID := flag.String("txn", "", "provider transaction id, random when empty")
+	event := flag.String("event", "va.paid", "callback event: va.paid, qr.paid or expired")
+	transactionUUID := flag.String("transaction", "", "transaction uuid referenced by the callback")
+	installmentUUID := flag.String("installment", "", "installment uuid referenced by the callback")
+	amount := flag.String("amount", "0", "paid amount")
+	repeat := flag.Int("repeat", 1, "send the same callback n times, to check deduplication")
+	tamper := flag.Bool("tamper", false, "send an invalid signature")
+	flag.Parse()
+
+	if *transactionUUID == "" && *installmentUUID == "" {
+		log.Fatal("either -transaction or -installment is required")
+	}
+
+	if *providerTransactionID == "" {
+		*providerTransactionID = uuid.NewString()
+	}
+
+	payload, err := json.Marshal(model.PaymentCallbackInput{
+		Provider:              *provider,
+		ProviderTransactionID: *providerTransactionID,
+		Event:                 *event,
+		TransactionUUID:       *transactionUUID,
+		InstallmentUUID:       *installmentUUID,
+		Amount:                *amount,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	signature := helpers.SignPayload(*secret, payload)
+	if *tamper {
+		signature = helpers.SignPayload(*secret+"tamper", payload)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	for i := 1; i <= *repeat; i++ {
+		req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(payload))
+		if err != nil {
+			log.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.WebhookSignatureHeader, signature)
+
+		res, err := client.Do(req)
+		if err != nil {
+			log.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		fmt.Printf("#%d %s %s\n%s\n", i, *providerTransactionID, res.Status, body)
+	}
+}
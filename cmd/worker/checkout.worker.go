@@ -0,0 +1,18 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+)
+
+// StartCheckoutWorker periodically expire checkout sessions that were not confirmed in
+// time and release their limit hold.
+func StartCheckoutWorker(transactionService service.TransactionService, lockRedis *redis.LockClient, interval time.Duration) {
+	startJob("checkout", lockRedis, interval, func(ctx context.Context) error {
+		_, err := transactionService.ExpireCheckouts(ctx, time.Now())
+		return err
+	})
+}
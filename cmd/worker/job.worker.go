@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// startJob run the job now then every interval. Only one instance run a job at a time,
+// the others skip the tick while the lock:job:<name> lock is taken.
+func startJob(name string, lockRedis *redis.LockClient, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		runJob(name, lockRedis, interval, job)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			runJob(name, lockRedis, interval, job)
+		}
+	}()
+}
+
+func runJob(name string, lockRedis *redis.LockClient, interval time.Duration, job func(ctx context.Context) error) {
+	ctx := context.WithValue(context.Background(), helpers.CtxKeyUsername, name+"-worker")
+	logData := helpers.InitialLogSystem()
+	logData.Location = "cmd/worker/job.worker.runJob." + name
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	lock_name := "lock:job:" + name
+	acquired, err := lockRedis.AcquireLock(ctx, lock_name, interval)
+	if err != nil {
+		logData.Message = fmt.Sprintf("Failed acquiring %s job lock", name)
+		logData.Err = err.Error()
+		return
+	}
+	if !acquired {
+		logData.Message = fmt.Sprintf("Job %s already running on another instance", name)
+		return
+	}
+	defer lockRedis.ReleaseLock(ctx, lock_name)
+
+	if err := job(ctx); err != nil {
+		logData.Message = fmt.Sprintf("Job %s failed", name)
+		logData.Err = err.Error()
+		return
+	}
+
+	logData.Message = fmt.Sprintf("Job %s finished", name)
+}
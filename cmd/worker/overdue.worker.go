@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+)
+
+// StartOverdueWorker periodically move past due installments to overdue, then accrue
+// penalties on them.
+func StartOverdueWorker(installmentService service.InstallmentService, penaltyService service.PenaltyService, lockRedis *redis.LockClient, interval time.Duration) {
+	startJob("overdue", lockRedis, interval, func(ctx context.Context) error {
+		now := time.Now()
+		if _, err := installmentService.MarkOverdue(ctx, now); err != nil {
+			return err
+		}
+
+		if _, err := penaltyService.AccruePenalties(ctx, now); err != nil {
+			return fmt.Errorf("penalty accrual: %w", err)
+		}
+
+		return nil
+	})
+}
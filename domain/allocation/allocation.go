@@ -0,0 +1,115 @@
+package allocation
+
+import (
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// PenaltySettlement is the part of a payment applied to one penalty row
+type PenaltySettlement struct {
+	PenaltyID uint
+	Amount    decimal.Decimal
+}
+
+// Line is the part of a payment applied to one installment
+type Line struct {
+	InstallmentID uint
+	Penalties     []PenaltySettlement
+	Penalty       decimal.Decimal
+	Interest      decimal.Decimal
+	Principal     decimal.Decimal
+	Amount        decimal.Decimal
+	// Settled is true when the installment is fully paid after this line
+	Settled bool
+}
+
+type Result struct {
+	Lines  []Line
+	Credit decimal.Decimal
+}
+
+// Allocate spread amount over unpaid installments, oldest due first. Inside an
+// installment unpaid penalties are settled first, then interest, then principal.
+// Installments must be ordered by due date with their penalties loaded, what is
+// left after every installment is settled is returned as credit.
+func Allocate(installments []entity.TransactionInstallment, amount decimal.Decimal) Result {
+	result := Result{Lines: []Line{}, Credit: decimal.Zero}
+	remaining := amount
+
+	for _, installment := range installments {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		line := Line{
+			InstallmentID: installment.ID,
+			Penalties:     []PenaltySettlement{},
+			Penalty:       decimal.Zero,
+		}
+
+		for _, penalty := range installment.Penalties {
+			settled := decimal.Min(remaining, penalty.Unpaid())
+			if !settled.IsPositive() {
+				continue
+			}
+
+			line.Penalties = append(line.Penalties, PenaltySettlement{PenaltyID: penalty.ID, Amount: settled})
+			line.Penalty = line.Penalty.Add(settled)
+			remaining = remaining.Sub(settled)
+		}
+
+		interestDue := InterestOutstanding(&installment)
+		line.Interest = decimal.Min(remaining, interestDue)
+		remaining = remaining.Sub(line.Interest)
+
+		principalDue := PrincipalOutstanding(&installment)
+		line.Principal = decimal.Min(remaining, principalDue)
+		remaining = remaining.Sub(line.Principal)
+
+		line.Amount = line.Penalty.Add(line.Interest).Add(line.Principal)
+		line.Settled = line.Penalty.Equal(unpaidPenalty(&installment)) &&
+			line.Interest.Equal(interestDue) && line.Principal.Equal(principalDue)
+
+		if line.Amount.IsPositive() {
+			result.Lines = append(result.Lines, line)
+		}
+	}
+
+	if remaining.IsPositive() {
+		result.Credit = remaining
+	}
+
+	return result
+}
+
+// InterestOutstanding return interest not yet paid, payment on an installment
+// always cover interest before principal
+func InterestOutstanding(installment *entity.TransactionInstallment) decimal.Decimal {
+	return decimal.Max(decimal.Zero, installment.InterestDue.Sub(installment.AmountPaid))
+}
+
+// PrincipalOutstanding return principal not yet paid
+func PrincipalOutstanding(installment *entity.TransactionInstallment) decimal.Decimal {
+	return decimal.Max(decimal.Zero, installment.AmountDue.Sub(installment.AmountPaid).Sub(InterestOutstanding(installment)))
+}
+
+// Outstanding return what is left to settle every installment, penalties included
+func Outstanding(installments []entity.TransactionInstallment) decimal.Decimal {
+	total := decimal.Zero
+	for i := range installments {
+		total = total.Add(unpaidPenalty(&installments[i])).
+			Add(InterestOutstanding(&installments[i])).
+			Add(PrincipalOutstanding(&installments[i]))
+	}
+
+	return total
+}
+
+func unpaidPenalty(installment *entity.TransactionInstallment) decimal.Decimal {
+	total := decimal.Zero
+	for _, penalty := range installment.Penalties {
+		total = total.Add(penalty.Unpaid())
+	}
+
+	return total
+}
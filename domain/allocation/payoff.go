@@ -0,0 +1,91 @@
+package allocation
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Payoff is the early settlement quote of a contract
+type Payoff struct {
+	RemainingPrincipal decimal.Decimal
+	AccruedInterest    decimal.Decimal
+	InterestRebate     decimal.Decimal
+	OutstandingPenalty decimal.Decimal
+	TerminationFee     decimal.Decimal
+	TotalPayoff        decimal.Decimal
+	// Lines settle every installment, in the same order
+	Lines []Line
+}
+
+// QuotePayoff quote early settlement of unpaid installments ordered by number.
+// Interest of the current and overdue installment stay due, interest of later installment
+// get rebate_percent rebate, and fee_percent of remaining principal is charged as early
+// termination fee. Unpaid penalties are added in full.
+func QuotePayoff(installments []entity.TransactionInstallment, now time.Time, rebate_percent, fee_percent decimal.Decimal) Payoff {
+	hundred := decimal.NewFromInt(100)
+	rebateRate := rebate_percent.Div(hundred)
+	feeRate := fee_percent.Div(hundred)
+
+	payoff := Payoff{
+		RemainingPrincipal: decimal.Zero,
+		AccruedInterest:    decimal.Zero,
+		InterestRebate:     decimal.Zero,
+		OutstandingPenalty: decimal.Zero,
+		Lines:              make([]Line, len(installments)),
+	}
+
+	for i, installment := range installments {
+		principal := PrincipalOutstanding(&installment)
+		interest := InterestOutstanding(&installment)
+
+		rebate := decimal.Zero
+		if i > 0 && installment.DueDate.After(now) {
+			rebate = interest.Mul(rebateRate).Round(2)
+		}
+
+		line := Line{
+			InstallmentID: installment.ID,
+			Penalties:     []PenaltySettlement{},
+			Penalty:       decimal.Zero,
+			Interest:      interest.Sub(rebate),
+			Principal:     principal,
+			Settled:       true,
+		}
+		for _, penalty := range installment.Penalties {
+			if unpaid := penalty.Unpaid(); unpaid.IsPositive() {
+				line.Penalties = append(line.Penalties, PenaltySettlement{PenaltyID: penalty.ID, Amount: unpaid})
+				line.Penalty = line.Penalty.Add(unpaid)
+			}
+		}
+		line.Amount = line.Penalty.Add(line.Interest).Add(line.Principal)
+
+		payoff.RemainingPrincipal = payoff.RemainingPrincipal.Add(principal)
+		payoff.AccruedInterest = payoff.AccruedInterest.Add(line.Interest)
+		payoff.InterestRebate = payoff.InterestRebate.Add(rebate)
+		payoff.OutstandingPenalty = payoff.OutstandingPenalty.Add(line.Penalty)
+		payoff.Lines[i] = line
+	}
+
+	payoff.TerminationFee = payoff.RemainingPrincipal.Mul(feeRate).Round(2)
+	payoff.TotalPayoff = payoff.RemainingPrincipal.Add(payoff.AccruedInterest).
+		Add(payoff.OutstandingPenalty).Add(payoff.TerminationFee)
+
+	return payoff
+}
+
+// Covered tell if amount settle the payoff
+func (p Payoff) Covered(amount decimal.Decimal) bool {
+	return !amount.LessThan(p.TotalPayoff)
+}
+
+// Excess is what amount pay above the payoff, kept as customer credit. Zero when
+// amount does not cover the payoff
+func (p Payoff) Excess(amount decimal.Decimal) decimal.Decimal {
+	if !p.Covered(amount) {
+		return decimal.Zero
+	}
+
+	return amount.Sub(p.TotalPayoff)
+}
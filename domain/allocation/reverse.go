@@ -0,0 +1,60 @@
+package allocation
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Reverse take back what an allocation row of a payment settled on its installment.
+// Penalties accrued last are reopened first, then paid amount and status of the
+// installment are recomputed. The reopened penalties are returned so they can be stored
+func Reverse(installment *entity.TransactionInstallment, line entity.PaymentAllocation, now time.Time) []*entity.InstallmentPenalty {
+	sort.SliceStable(installment.Penalties, func(a, b int) bool {
+		return installment.Penalties[a].AccrualDate.Before(installment.Penalties[b].AccrualDate)
+	})
+
+	reopened := []*entity.InstallmentPenalty{}
+	penaltyAmount := line.PenaltyAmount
+	for j := len(installment.Penalties) - 1; j >= 0 && penaltyAmount.IsPositive(); j-- {
+		penalty := &installment.Penalties[j]
+		if !penalty.AmountPaid.IsPositive() {
+			continue
+		}
+
+		amount := decimal.Min(penaltyAmount, penalty.AmountPaid)
+		penalty.AmountPaid = penalty.AmountPaid.Sub(amount)
+		penalty.PaidAt = sql.NullTime{}
+		penaltyAmount = penaltyAmount.Sub(amount)
+		reopened = append(reopened, penalty)
+	}
+
+	installment.AmountPaid = installment.AmountPaid.Sub(line.InterestAmount).Sub(line.PrincipalAmount)
+	if installment.AmountPaid.IsNegative() {
+		installment.AmountPaid = decimal.Zero
+	}
+	installment.PaymentStatus = ReversedStatus(installment, now)
+	if installment.PaymentStatus != entity.PaymentStatusPaid {
+		installment.PaidAt = sql.NullTime{}
+	}
+
+	return reopened
+}
+
+// ReversedStatus recompute installment status once paid amount is taken back
+func ReversedStatus(installment *entity.TransactionInstallment, now time.Time) entity.PaymentStatus {
+	switch {
+	case installment.AmountPaid.GreaterThanOrEqual(installment.AmountDue) &&
+		!unpaidPenalty(installment).IsPositive():
+		return entity.PaymentStatusPaid
+	case installment.DueDate.Before(now):
+		return entity.PaymentStatusOverdue
+	case installment.AmountPaid.IsPositive():
+		return entity.PaymentStatusPartial
+	default:
+		return entity.PaymentStatusPending
+	}
+}
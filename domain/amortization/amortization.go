@@ -0,0 +1,194 @@
+package amortization
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Row is a single installment of a schedule, with the amount due split into
+// the principal and interest portion. Balance is the outstanding principal
+// after the row is paid.
+type Row struct {
+	Number    uint
+	Principal decimal.Decimal
+	Interest  decimal.Decimal
+	Amount    decimal.Decimal
+	Balance   decimal.Decimal
+}
+
+type Schedule struct {
+	Method        entity.InterestMethod
+	Principal     decimal.Decimal
+	Rate          decimal.Decimal
+	Tenor         uint
+	TotalInterest decimal.Decimal
+	TotalPayable  decimal.Decimal
+	Rows          []Row
+}
+
+// Calculator produce schedule rows for a principal, a monthly rate as fraction
+// (0.015 for 1.5%) and a tenor in month.
+type Calculator interface {
+	Rows(principal decimal.Decimal, rate decimal.Decimal, tenor uint) []Row
+}
+
+var calculators = map[entity.InterestMethod]Calculator{
+	entity.InterestFlat:      flatCalculator{},
+	entity.InterestAnnuity:   annuityCalculator{},
+	entity.InterestEffective: effectiveCalculator{},
+}
+
+// Register add or replace calculator used for an interest method
+func Register(method entity.InterestMethod, calculator Calculator) {
+	calculators[method] = calculator
+}
+
+// Generate build full schedule for the given method. Rate is the monthly interest
+// rate in percent (1.5 means 1.5% per month). The last row always absorb
+// rounding, so sum of principal equal to the financed principal.
+func Generate(method entity.InterestMethod, principal decimal.Decimal, rate decimal.Decimal, tenor uint) (*Schedule, error) {
+	if method == "" {
+		method = entity.InterestFlat
+	}
+
+	calculator, ok := calculators[method]
+	if !ok {
+		return nil, fmt.Errorf("amortization: unknown interest method %q", method)
+	}
+
+	if tenor == 0 {
+		return nil, errors.New("amortization: tenor must be greater than zero")
+	}
+
+	if !principal.IsPositive() {
+		return nil, errors.New("amortization: principal must be greater than zero")
+	}
+
+	if rate.IsNegative() {
+		return nil, errors.New("amortization: rate must not be negative")
+	}
+
+	rows := calculator.Rows(principal.Round(2), rate.Div(hundred), tenor)
+
+	schedule := Schedule{
+		Method:        method,
+		Principal:     principal.Round(2),
+		Rate:          rate,
+		Tenor:         tenor,
+		TotalInterest: decimal.Zero,
+		TotalPayable:  decimal.Zero,
+		Rows:          rows,
+	}
+	for _, row := range rows {
+		schedule.TotalInterest = schedule.TotalInterest.Add(row.Interest)
+		schedule.TotalPayable = schedule.TotalPayable.Add(row.Amount)
+	}
+
+	return &schedule, nil
+}
+
+// MonthlyInstallment return amount of the first row, which is the fixed payment
+// for flat and annuity, and the highest payment for effective.
+func (s *Schedule) MonthlyInstallment() decimal.Decimal {
+	if len(s.Rows) == 0 {
+		return decimal.Zero
+	}
+
+	return s.Rows[0].Amount
+}
+
+// flatCalculator charge interest on the original principal for every month
+type flatCalculator struct{}
+
+func (flatCalculator) Rows(principal decimal.Decimal, rate decimal.Decimal, tenor uint) []Row {
+	n := decimal.NewFromInt(int64(tenor))
+	totalInterest := principal.Mul(rate).Mul(n).Round(2)
+	monthlyPrincipal := principal.Div(n).Round(2)
+	monthlyInterest := totalInterest.Div(n).Round(2)
+
+	rows := make([]Row, tenor)
+	balance := principal
+	paidInterest := decimal.Zero
+	for i := range rows {
+		rowPrincipal, rowInterest := monthlyPrincipal, monthlyInterest
+		if uint(i) == tenor-1 {
+			rowPrincipal = balance
+			rowInterest = totalInterest.Sub(paidInterest)
+		}
+
+		balance = balance.Sub(rowPrincipal)
+		paidInterest = paidInterest.Add(rowInterest)
+		rows[i] = newRow(i, rowPrincipal, rowInterest, balance)
+	}
+
+	return rows
+}
+
+// annuityCalculator charge interest on the outstanding balance with fixed monthly payment
+type annuityCalculator struct{}
+
+func (annuityCalculator) Rows(principal decimal.Decimal, rate decimal.Decimal, tenor uint) []Row {
+	n := decimal.NewFromInt(int64(tenor))
+
+	var payment decimal.Decimal
+	if rate.IsZero() {
+		payment = principal.Div(n).Round(2)
+	} else {
+		// payment = P * r / (1 - (1 + r)^-n)
+		growth := decimal.NewFromInt(1).Add(rate).Pow(n)
+		payment = principal.Mul(rate).Mul(growth).Div(growth.Sub(decimal.NewFromInt(1))).Round(2)
+	}
+
+	rows := make([]Row, tenor)
+	balance := principal
+	for i := range rows {
+		rowInterest := balance.Mul(rate).Round(2)
+		rowPrincipal := payment.Sub(rowInterest)
+		if uint(i) == tenor-1 || rowPrincipal.GreaterThan(balance) {
+			rowPrincipal = balance
+		}
+
+		balance = balance.Sub(rowPrincipal)
+		rows[i] = newRow(i, rowPrincipal, rowInterest, balance)
+	}
+
+	return rows
+}
+
+// effectiveCalculator pay equal principal each month with interest on the outstanding balance
+type effectiveCalculator struct{}
+
+func (effectiveCalculator) Rows(principal decimal.Decimal, rate decimal.Decimal, tenor uint) []Row {
+	n := decimal.NewFromInt(int64(tenor))
+	monthlyPrincipal := principal.Div(n).Round(2)
+
+	rows := make([]Row, tenor)
+	balance := principal
+	for i := range rows {
+		rowInterest := balance.Mul(rate).Round(2)
+		rowPrincipal := monthlyPrincipal
+		if uint(i) == tenor-1 {
+			rowPrincipal = balance
+		}
+
+		balance = balance.Sub(rowPrincipal)
+		rows[i] = newRow(i, rowPrincipal, rowInterest, balance)
+	}
+
+	return rows
+}
+
+func newRow(index int, principal decimal.Decimal, interest decimal.Decimal, balance decimal.Decimal) Row {
+	return Row{
+		Number:    uint(index + 1),
+		Principal: principal,
+		Interest:  interest,
+		Amount:    principal.Add(interest),
+		Balance:   balance,
+	}
+}
@@ -0,0 +1,66 @@
+package calendar
+
+import "time"
+
+const dayLayout = "2006-01-02"
+
+// Calendar tell which day is a business day. Saturday, Sunday and the given holidays
+// are not, holidays are compared by date so their time and location is ignored.
+type Calendar struct {
+	holidays map[string]struct{}
+}
+
+func New(holidays []time.Time) *Calendar {
+	calendar := &Calendar{holidays: make(map[string]struct{}, len(holidays))}
+	for _, holiday := range holidays {
+		calendar.holidays[holiday.Format(dayLayout)] = struct{}{}
+	}
+
+	return calendar
+}
+
+func (c *Calendar) IsBusinessDay(date time.Time) bool {
+	if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+
+	_, holiday := c.holidays[date.Format(dayLayout)]
+	return !holiday
+}
+
+// Adjust move the date forward to the nearest business day
+func (c *Calendar) Adjust(date time.Time) time.Time {
+	for !c.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date
+}
+
+// DueDates return the business day due date of every installment, see DueDate
+func (c *Calendar) DueDates(start time.Time, tenor uint, billing_day uint) []time.Time {
+	dates := make([]time.Time, tenor)
+	for i := range dates {
+		dates[i] = c.Adjust(DueDate(start, uint(i+1), billing_day))
+	}
+
+	return dates
+}
+
+// DueDate return the due date of installment number on the billing day, billing day 0
+// use the day of start (anniversary). Day past the end of the month is clamped to its
+// last day, so a contract started on Jan 31 is due on Feb 28, Mar 31, Apr 30 and so on.
+func DueDate(start time.Time, number uint, billing_day uint) time.Time {
+	day := int(billing_day)
+	if day == 0 {
+		day = start.Day()
+	}
+
+	first := time.Date(start.Year(), start.Month()+time.Month(number), 1,
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
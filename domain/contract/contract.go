@@ -0,0 +1,109 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	seqToken  = regexp.MustCompile(`\{seq(?::(\d+))?\}`)
+	anyToken  = regexp.MustCompile(`\{[^{}]*\}`)
+	knownDate = []struct {
+		token  string
+		layout string
+	}{
+		// Longest token first so {yyyyMM} is not read as {yyyy} and MM
+		{"{yyyyMMdd}", "20060102"},
+		{"{yyyyMM}", "200601"},
+		{"{yyyy}", "2006"},
+		{"{yy}", "06"},
+		{"{MM}", "01"},
+		{"{dd}", "02"},
+	}
+)
+
+// Validate check the format has exactly one sequence token and only known token.
+// Supported token are {branch}, {yyyyMMdd}, {yyyyMM}, {yyyy}, {yy}, {MM}, {dd} and
+// {seq} or {seq:N} with N the zero padded width.
+func Validate(format string) error {
+	if len(seqToken.FindAllString(format, -1)) != 1 {
+		return errors.New("contract number format must have one {seq} token")
+	}
+
+	for _, token := range anyToken.FindAllString(format, -1) {
+		if token == "{branch}" || seqToken.MatchString(token) || isDateToken(token) {
+			continue
+		}
+		return fmt.Errorf("contract number format has unknown token %s", token)
+	}
+
+	return nil
+}
+
+// Scope render every token except the sequence, contract numbers sharing a scope share
+// one sequence, so a format with {yyyyMM} restart the sequence every month
+func Scope(format string, branch string, at time.Time) string {
+	scope := strings.ReplaceAll(format, "{branch}", branch)
+	for _, date := range knownDate {
+		scope = strings.ReplaceAll(scope, date.token, at.Format(date.layout))
+	}
+
+	return scope
+}
+
+// Render build the contract number of the sequence, sequence wider than the padding
+// is written in full
+func Render(format string, branch string, at time.Time, seq uint64) string {
+	return seqToken.ReplaceAllStringFunc(Scope(format, branch, at), func(token string) string {
+		width := 0
+		if match := seqToken.FindStringSubmatch(token); match[1] != "" {
+			width, _ = strconv.Atoi(match[1])
+		}
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
+
+// Matches tell whether number could be generated by the format on any branch and date.
+// Such number can not be imported as legacy contract, it would collide with the sequence
+func Matches(format string, number string) bool {
+	pattern, last := "", 0
+	for _, loc := range anyToken.FindAllStringIndex(format, -1) {
+		pattern += regexp.QuoteMeta(format[last:loc[0]]) + tokenPattern(format[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(format[last:])
+
+	return regexp.MustCompile("^" + pattern + "$").MatchString(number)
+}
+
+func tokenPattern(token string) string {
+	if match := seqToken.FindStringSubmatch(token); match != nil {
+		if match[1] != "" {
+			return fmt.Sprintf(`\d{%s,}`, match[1])
+		}
+		return `\d+`
+	}
+
+	for _, date := range knownDate {
+		if date.token == token {
+			return fmt.Sprintf(`\d{%d}`, len(date.layout))
+		}
+	}
+
+	// {branch}
+	return `.+`
+}
+
+func isDateToken(token string) bool {
+	for _, date := range knownDate {
+		if date.token == token {
+			return true
+		}
+	}
+
+	return false
+}
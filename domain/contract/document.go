@@ -0,0 +1,156 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "02 Jan 2006"
+
+// Document render the contract of the transaction as pdf: customer data, contract terms,
+// the full installment table and the sign off section. Transaction need its user profile
+// and product loaded, installments are printed by installment number.
+func Document(transaction *entity.Transaction, installments []entity.TransactionInstallment) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Contract %s - page %d of {nb}", transaction.ContractNumber, pdf.PageNo()),
+			"", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "LOAN CONTRACT", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, transaction.ContractNumber, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	profile := entity.UserProfile{}
+	if transaction.User.Profile != nil {
+		profile = *transaction.User.Profile
+	}
+	section(pdf, "Customer")
+	row(pdf, "Name", profile.LegalName)
+	row(pdf, "NIK", profile.Nik)
+	row(pdf, "Place, date of birth", fmt.Sprintf("%s, %s", profile.BirthPlace, profile.BirthDate.Format(dateLayout)))
+	pdf.Ln(3)
+
+	product := "-"
+	if transaction.Product != nil {
+		product = fmt.Sprintf("%s (%s)", transaction.Product.Name, transaction.Product.Code)
+	}
+
+	section(pdf, "Contract terms")
+	row(pdf, "Product", product)
+	row(pdf, "Asset", transaction.AssetName)
+	row(pdf, "On the road", money(transaction.OnTheRoad))
+	row(pdf, "Admin fee", money(transaction.AdminFee))
+	row(pdf, "Financed amount", money(transaction.OnTheRoad.Add(transaction.AdminFee)))
+	row(pdf, "Interest", fmt.Sprintf("%s%% per month, %s", transaction.InterestRate.String(), transaction.InterestMethod))
+	row(pdf, "Total interest", money(transaction.InterestAmount))
+	row(pdf, "Monthly installment", money(transaction.MonthlyInstallment))
+	row(pdf, "Tenor", fmt.Sprintf("%d month", transaction.Tenor))
+	row(pdf, "Period", fmt.Sprintf("%s - %s", transaction.StartDate.Format(dateLayout), transaction.EndDate.Format(dateLayout)))
+	pdf.Ln(3)
+
+	sorted := append([]entity.TransactionInstallment{}, installments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].InstallmentNumber < sorted[j].InstallmentNumber
+	})
+
+	section(pdf, "Installment schedule")
+	widths := []float64{12, 32, 34, 34, 34, 34}
+	headers := []string{"No", "Due date", "Principal", "Interest", "Amount due", "Outstanding"}
+	pdf.SetFont("Helvetica", "B", 9)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	total := decimal.Zero
+	for _, installment := range sorted {
+		total = total.Add(installment.AmountDue)
+		cells := []string{
+			fmt.Sprintf("%d", installment.InstallmentNumber),
+			installment.DueDate.Format(dateLayout),
+			money(installment.PrincipalDue),
+			money(installment.InterestDue),
+			money(installment.AmountDue),
+			money(installment.Outstanding),
+		}
+		for i, cell := range cells {
+			align := "R"
+			if i < 2 {
+				align = "C"
+			}
+			pdf.CellFormat(widths[i], 6, cell, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3], 7, "Total payable", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 7, money(total), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[5], 7, "", "1", 1, "R", false, 0, "")
+	pdf.Ln(10)
+
+	// Sign off
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.MultiCell(0, 5, "By signing this contract the customer agree to pay every installment above on its due date.", "", "L", false)
+	pdf.Ln(15)
+	pdf.CellFormat(90, 5, "Customer", "", 0, "C", false, 0, "")
+	pdf.CellFormat(90, 5, "Company", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+	pdf.CellFormat(90, 5, fmt.Sprintf("( %s )", profile.LegalName), "", 0, "C", false, 0, "")
+	pdf.CellFormat(90, 5, "( Authorized officer )", "", 1, "C", false, 0, "")
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func section(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(1)
+}
+
+func row(pdf *fpdf.Fpdf, label string, value string) {
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(50, 6, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, value, "", 1, "L", false, 0, "")
+}
+
+// money format amount with thousand separator and two decimal, like 1,250,000.00
+func money(amount decimal.Decimal) string {
+	fixed := amount.Abs().StringFixed(2)
+	whole, fraction, _ := strings.Cut(fixed, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+	}
+
+	return sign + grouped.String() + "." + fraction
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type CheckoutStatus string
+
+const (
+	// CheckoutPending hold the on the road against the user limit until it is confirmed or expire
+	CheckoutPending CheckoutStatus = "pending"
+	// CheckoutConfirmed became a contract, the hold is consumed by it
+	CheckoutConfirmed CheckoutStatus = "confirmed"
+	// CheckoutExpired was not confirmed in time, the hold is released
+	CheckoutExpired CheckoutStatus = "expired"
+	// CheckoutCanceled was refused by the customer, the hold is released
+	CheckoutCanceled CheckoutStatus = "canceled"
+)
+
+// CheckoutSession is a merchant checkout waiting for the customer, the on the road is held
+// against the limit of the tenor under LimitPoolMode so the same limits are released or
+// consumed later
+type CheckoutSession struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	UUID          uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	UserID        uint            `json:"user_id" gorm:"index;not null"`
+	MerchantID    uint            `json:"merchant_id" gorm:"index;not null"`
+	TransactionID *uint           `json:"transaction_id" gorm:"uniqueIndex"`
+	ProductCode   string          `json:"product_code" gorm:"type:varchar(50);not null"`
+	AssetName     string          `json:"asset_name" gorm:"not null"`
+	OnTheRoad     decimal.Decimal `json:"on_the_road" gorm:"type:decimal(20,2);not null"`
+	Tenor         uint            `json:"tenor" gorm:"type:smallint unsigned;not null"`
+	BillingDay    uint            `json:"billing_day" gorm:"type:tinyint unsigned;not null;default:0"`
+	LimitPoolMode LimitPoolMode   `json:"limit_pool_mode" gorm:"type:enum('shared', 'per_tenor', 'global_cap');not null;default:'shared'"`
+	Status        CheckoutStatus  `json:"status" gorm:"type:enum('pending', 'confirmed', 'expired', 'canceled');not null;default:'pending';index:idx_checkout_status_expires"`
+	ExpiresAt     time.Time       `json:"expires_at" gorm:"not null;index:idx_checkout_status_expires"`
+	ConfirmedAt   *time.Time      `json:"confirmed_at"`
+
+	// Relationship
+	User        User         `json:"user" gorm:"foreignKey:UserID"`
+	Merchant    *Merchant    `json:"merchant" gorm:"foreignKey:MerchantID"`
+	Transaction *Transaction `json:"transaction" gorm:"foreignKey:TransactionID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CheckoutSession) TableName() string {
+	return "checkout_sessions"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (c *CheckoutSession) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return
+}
+
+// IsOpen tell whether the session still hold the limit and can be confirmed at the time
+func (c *CheckoutSession) IsOpen(at time.Time) bool {
+	return c.Status == CheckoutPending && at.Before(c.ExpiresAt)
+}
+
+func (s *CheckoutStatus) Scan(value interface{}) error {
+	*s = CheckoutStatus(value.([]byte))
+	return nil
+}
+
+func (s CheckoutStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// ContractSequence is the last contract number sequence used in a scope, the scope is
+// the contract number format rendered without the sequence. The row is locked and
+// incremented inside the transaction that insert the contract, so a rolled back
+// contract does not leave a gap.
+type ContractSequence struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Scope     string `json:"scope" gorm:"type:varchar(255);uniqueIndex;not null"`
+	LastValue uint64 `json:"last_value" gorm:"not null;default:0"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ContractSequence) TableName() string {
+	return "contract_sequences"
+}
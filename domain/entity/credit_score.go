@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type CreditGrade string
+
+const (
+	CreditGradeA CreditGrade = "A"
+	CreditGradeB CreditGrade = "B"
+	CreditGradeC CreditGrade = "C"
+	CreditGradeD CreditGrade = "D"
+	CreditGradeE CreditGrade = "E"
+)
+
+// CreditScore is one scoring of a user, kept with its factors so the limit given from it
+// can be explained. The latest one is the current score of the user.
+type CreditScore struct {
+	ID              uint            `json:"id" gorm:"primaryKey"`
+	UUID            uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	UserID          uint            `json:"user_id" gorm:"index;not null"`
+	Score           uint            `json:"score" gorm:"type:smallint unsigned;not null"`
+	Grade           CreditGrade     `json:"grade" gorm:"type:enum('A', 'B', 'C', 'D', 'E');not null"`
+	LimitMultiplier decimal.Decimal `json:"limit_multiplier" gorm:"type:decimal(5,2);not null"`
+
+	// Relationship
+	Factors []CreditScoreFactor `json:"factors" gorm:"foreignKey:CreditScoreID"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreditScoreFactor is the points one factor add to the score, Value is what was observed
+type CreditScoreFactor struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	CreditScoreID uint   `json:"credit_score_id" gorm:"index;not null"`
+	Code          string `json:"code" gorm:"type:varchar(50);not null"`
+	Description   string `json:"description" gorm:"type:varchar(255)"`
+	Value         string `json:"value" gorm:"type:varchar(100)"`
+	Points        int    `json:"points" gorm:"not null"`
+}
+
+func (CreditScore) TableName() string {
+	return "credit_scores"
+}
+
+func (CreditScoreFactor) TableName() string {
+	return "credit_score_factors"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (c *CreditScore) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return
+}
+
+func (g *CreditGrade) Scan(value interface{}) error {
+	*g = CreditGrade(value.([]byte))
+	return nil
+}
+
+func (g CreditGrade) Value() (driver.Value, error) {
+	return string(g), nil
+}
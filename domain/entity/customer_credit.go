@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// CustomerCredit keep payment amount left after every installment is settled
+type CustomerCredit struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	UUID          uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	UserID        uint            `json:"user_id" gorm:"index;not null"`
+	TransactionID uint            `json:"transaction_id" gorm:"index;not null"`
+	PaymentID     uint            `json:"payment_id" gorm:"uniqueIndex;not null"`
+	Amount        decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+	AmountUsed    decimal.Decimal `json:"amount_used" gorm:"type:decimal(20,2);not null;default:0"`
+
+	// Relationship
+	User User `json:"user" gorm:"foreignKey:UserID"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Available return credit amount not yet used
+func (c *CustomerCredit) Available() decimal.Decimal {
+	return c.Amount.Sub(c.AmountUsed)
+}
+
+func (CustomerCredit) TableName() string {
+	return "customer_credits"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (c *CustomerCredit) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type DisbursementStatus string
+
+const (
+	// DisbursementPending is owed to the merchant and not sent yet
+	DisbursementPending DisbursementStatus = "pending"
+	// DisbursementSent is paid to the merchant bank account
+	DisbursementSent DisbursementStatus = "sent"
+	// DisbursementFailed is rejected by the bank, it can be sent again
+	DisbursementFailed DisbursementStatus = "failed"
+	// DisbursementCanceled belong to a contract cancelled before it was sent
+	DisbursementCanceled DisbursementStatus = "canceled"
+)
+
+// disbursementTransitions list the status a disbursement can move to, sent is final
+var disbursementTransitions = map[DisbursementStatus][]DisbursementStatus{
+	DisbursementPending: {DisbursementSent, DisbursementFailed, DisbursementCanceled},
+	DisbursementFailed:  {DisbursementSent, DisbursementCanceled},
+}
+
+// Disbursement is the on the road of a contract owed to the merchant that sold the asset,
+// it is created when the contract become active
+type Disbursement struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	UUID          uuid.UUID          `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	TransactionID uint               `json:"transaction_id" gorm:"uniqueIndex;not null"`
+	MerchantID    uint               `json:"merchant_id" gorm:"index;not null"`
+	Amount        decimal.Decimal    `json:"amount" gorm:"type:decimal(20,2);not null"`
+	DueDate       time.Time          `json:"due_date" gorm:"type:date;not null"`
+	Status        DisbursementStatus `json:"status" gorm:"type:enum('pending', 'sent', 'failed', 'canceled');not null;default:'pending'"`
+	Reference     string             `json:"reference" gorm:"type:varchar(100)"`
+	Notes         string             `json:"notes" gorm:"type:varchar(255)"`
+	SentAt        *time.Time         `json:"sent_at"`
+
+	// Relationship
+	Transaction *Transaction `json:"transaction" gorm:"foreignKey:TransactionID"`
+	Merchant    *Merchant    `json:"merchant" gorm:"foreignKey:MerchantID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Disbursement) TableName() string {
+	return "disbursements"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (d *Disbursement) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.UUID == uuid.Nil {
+		d.UUID = uuid.New()
+	}
+	return
+}
+
+func (s DisbursementStatus) CanTransitionTo(to DisbursementStatus) bool {
+	for _, allowed := range disbursementTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOpen tell whether the amount is still owed to the merchant
+func (s DisbursementStatus) IsOpen() bool {
+	return s == DisbursementPending || s == DisbursementFailed
+}
+
+// Transition move the disbursement to status with the bank reference or failure notes,
+// SentAt is set when it is sent
+func (d *Disbursement) Transition(to DisbursementStatus, reference string, notes string, at time.Time) error {
+	if !d.Status.CanTransitionTo(to) {
+		return fmt.Errorf("disbursement can not move from %s to %s", d.Status, to)
+	}
+
+	d.Status = to
+	d.Reference = reference
+	d.Notes = notes
+	if to == DisbursementSent {
+		d.SentAt = &at
+	}
+
+	return nil
+}
+
+func (s *DisbursementStatus) Scan(value interface{}) error {
+	*s = DisbursementStatus(value.([]byte))
+	return nil
+}
+
+func (s DisbursementStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
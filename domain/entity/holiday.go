@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Holiday is a public holiday, installment due on it is moved to the next business day
+type Holiday struct {
+	ID   uint      `json:"id" gorm:"primaryKey"`
+	UUID uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Date time.Time `json:"date" gorm:"type:date;uniqueIndex;not null"`
+	Name string    `json:"name" gorm:"type:varchar(255);not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Holiday) TableName() string {
+	return "holidays"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (h *Holiday) BeforeCreate(tx *gorm.DB) (err error) {
+	if h.UUID == uuid.Nil {
+		h.UUID = uuid.New()
+	}
+	return
+}
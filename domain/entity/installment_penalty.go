@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// InstallmentPenalty is a penalty ledger row accrued on an overdue installment.
+// One row is written per policy per accrual date, Days is the number of days
+// charged by the row so later accrual only charge the remaining days.
+type InstallmentPenalty struct {
+	ID              uint            `json:"id" gorm:"primaryKey"`
+	UUID            uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	InstallmentID   uint            `json:"installment_id" gorm:"not null;index:idx_installment_penalty,unique"`
+	PenaltyPolicyID uint            `json:"penalty_policy_id" gorm:"not null;index:idx_installment_penalty,unique"`
+	AccrualDate     time.Time       `json:"accrual_date" gorm:"type:date;not null;index:idx_installment_penalty,unique"`
+	Days            uint            `json:"days" gorm:"type:smallint unsigned;not null;default:0"`
+	Amount          decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+	AmountPaid      decimal.Decimal `json:"amount_paid" gorm:"type:decimal(20,2);not null;default:0"`
+	PaidAt          sql.NullTime    `json:"paid_at"`
+
+	// Relationship
+	Installment   TransactionInstallment `json:"installment" gorm:"foreignKey:InstallmentID"`
+	PenaltyPolicy PenaltyPolicy          `json:"penalty_policy" gorm:"foreignKey:PenaltyPolicyID"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (InstallmentPenalty) TableName() string {
+	return "installment_penalties"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (p *InstallmentPenalty) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == uuid.Nil {
+		p.UUID = uuid.New()
+	}
+	return
+}
+
+// Unpaid return penalty amount not yet settled
+func (p *InstallmentPenalty) Unpaid() decimal.Decimal {
+	return p.Amount.Sub(p.AmountPaid)
+}
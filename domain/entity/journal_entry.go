@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// JournalEntry is one balanced money movement. Entries are never updated or deleted,
+// a movement is undone by posting the opposite entry
+type JournalEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UUID        uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	SourceType  string    `json:"source_type" gorm:"type:varchar(50);not null;index:idx_journal_source"`
+	SourceID    uint      `json:"source_id" gorm:"not null;index:idx_journal_source"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	PostedAt    time.Time `json:"posted_at" gorm:"index;not null"`
+
+	// Relationship
+	Postings []JournalPosting `json:"postings" gorm:"foreignKey:JournalEntryID"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (j *JournalEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if j.UUID == uuid.Nil {
+		j.UUID = uuid.New()
+	}
+	return
+}
+
+// JournalPosting is one side of a journal entry on one account, either debit or credit is set
+type JournalPosting struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	JournalEntryID uint            `json:"journal_entry_id" gorm:"index;not null"`
+	AccountCode    string          `json:"account_code" gorm:"type:varchar(20);index;not null"`
+	Debit          decimal.Decimal `json:"debit" gorm:"type:decimal(20,2);not null;default:0"`
+	Credit         decimal.Decimal `json:"credit" gorm:"type:decimal(20,2);not null;default:0"`
+
+	// Relationship
+	Account LedgerAccount `json:"account" gorm:"foreignKey:AccountCode;references:Code"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (JournalPosting) TableName() string {
+	return "journal_postings"
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+type LedgerAccountType string
+
+const (
+	LedgerAsset     LedgerAccountType = "asset"
+	LedgerLiability LedgerAccountType = "liability"
+	LedgerEquity    LedgerAccountType = "equity"
+	LedgerIncome    LedgerAccountType = "income"
+	LedgerExpense   LedgerAccountType = "expense"
+)
+
+// LedgerAccount is one account of the chart of accounts, postings refer to it by code
+type LedgerAccount struct {
+	ID   uint              `json:"id" gorm:"primaryKey"`
+	Code string            `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name string            `json:"name" gorm:"type:varchar(255);not null"`
+	Type LedgerAccountType `json:"type" gorm:"type:enum('asset', 'liability', 'equity', 'income', 'expense');not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (LedgerAccount) TableName() string {
+	return "ledger_accounts"
+}
+
+// DebitNormal is true for account which balance grow on debit side
+func (t LedgerAccountType) DebitNormal() bool {
+	return t == LedgerAsset || t == LedgerExpense
+}
+
+func (t *LedgerAccountType) Scan(value interface{}) error {
+	*t = LedgerAccountType(value.([]byte))
+	return nil
+}
+
+func (t LedgerAccountType) Value() (driver.Value, error) {
+	return string(t), nil
+}
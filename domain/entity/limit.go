@@ -1,18 +1,25 @@
 package entity
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+// Limit is the credit limit of a user for one tenor, PoolMode decide which limits a
+// transaction use its otr from. A frozen limit can not be used by new transaction.
 type Limit struct {
 	ID            uint            `json:"id" gorm:"primaryKey"`
 	UserID        uint            `json:"user_id" gorm:"not null;index:idx_limit,unique"`
 	Tenor         uint            `json:"tenor" gorm:"type:smallint unsigned;not null;index:idx_limit,unique"`
 	CurrentLimit  decimal.Decimal `json:"current_limit" gorm:"type:decimal(20,2);not null"`
 	OriginalLimit decimal.Decimal `json:"original_limit" gorm:"type:decimal(20,2);not null"`
+	FrozenAt      sql.NullTime    `json:"frozen_at"`
+	LimitPolicyID *uint           `json:"limit_policy_id" gorm:"index"`
+	PoolMode      LimitPoolMode   `json:"pool_mode" gorm:"type:enum('shared', 'per_tenor', 'global_cap');not null;default:'shared'"`
 
 	// Relationship
 	User User `json:"user" gorm:"foreignKey:UserID"`
@@ -25,3 +32,40 @@ type Limit struct {
 func (Limit) TableName() string {
 	return "limits"
 }
+
+type LimitPoolMode string
+
+const (
+	// LimitPoolShared use the otr from every tenor limit at once, limits act as one pool
+	LimitPoolShared LimitPoolMode = "shared"
+	// LimitPoolPerTenor use the otr only from the limit of the transaction tenor
+	LimitPoolPerTenor LimitPoolMode = "per_tenor"
+	// LimitPoolGlobalCap use the otr from the limit of the transaction tenor as sub cap
+	// and from the global limit as cap of every tenor
+	LimitPoolGlobalCap LimitPoolMode = "global_cap"
+)
+
+// GlobalLimitTenor is the tenor of the global limit on LimitPoolGlobalCap, no transaction
+// has tenor zero
+const GlobalLimitTenor uint = 0
+
+// Consumes tell whether a transaction of the tenor use the otr from the limit of limit_tenor
+func (m LimitPoolMode) Consumes(limit_tenor uint, tenor uint) bool {
+	switch m {
+	case LimitPoolPerTenor:
+		return limit_tenor == tenor
+	case LimitPoolGlobalCap:
+		return limit_tenor == tenor || limit_tenor == GlobalLimitTenor
+	default:
+		return true
+	}
+}
+
+func (m *LimitPoolMode) Scan(value interface{}) error {
+	*m = LimitPoolMode(value.([]byte))
+	return nil
+}
+
+func (m LimitPoolMode) Value() (driver.Value, error) {
+	return string(m), nil
+}
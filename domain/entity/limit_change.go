@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type LimitChangeAction string
+
+// Admin and activation action
+const (
+	LimitCreate   LimitChangeAction = "create"
+	LimitRaise    LimitChangeAction = "raise"
+	LimitLower    LimitChangeAction = "lower"
+	LimitFreeze   LimitChangeAction = "freeze"
+	LimitUnfreeze LimitChangeAction = "unfreeze"
+	LimitRemove   LimitChangeAction = "remove"
+)
+
+// Transaction action, the limit move by the otr of the related transaction
+const (
+	LimitTransactionCreate LimitChangeAction = "transaction_create"
+	LimitTransactionAmend  LimitChangeAction = "transaction_amend"
+	LimitTransactionCancel LimitChangeAction = "transaction_cancel"
+	LimitTransactionPaid   LimitChangeAction = "transaction_paid"
+	LimitPayoff            LimitChangeAction = "payoff"
+	LimitPaymentReversal   LimitChangeAction = "payment_reversal"
+)
+
+// Checkout action, the limit move by the otr of the related checkout session
+const (
+	LimitCheckoutHold    LimitChangeAction = "checkout_hold"
+	LimitCheckoutRelease LimitChangeAction = "checkout_release"
+)
+
+// LimitChange is an append only log of every movement of a limit, with the value before
+// and after it. ChangedBy is empty when the change is made by the system, like a gateway
+// callback that pay off a transaction.
+type LimitChange struct {
+	ID                    uint              `json:"id" gorm:"primaryKey"`
+	UUID                  uuid.UUID         `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	UserID                uint              `json:"user_id" gorm:"index;not null"`
+	LimitID               uint              `json:"limit_id" gorm:"index;not null"`
+	Tenor                 uint              `json:"tenor" gorm:"type:smallint unsigned;not null"`
+	Action                LimitChangeAction `json:"action" gorm:"type:enum('create', 'raise', 'lower', 'freeze', 'unfreeze', 'remove', 'transaction_create', 'transaction_amend', 'transaction_cancel', 'transaction_paid', 'payoff', 'payment_reversal', 'checkout_hold', 'checkout_release');not null"`
+	TransactionUUID       *uuid.UUID        `json:"transaction_uuid" gorm:"type:char(36);index"`
+	CheckoutUUID          *uuid.UUID        `json:"checkout_uuid" gorm:"type:char(36);index"`
+	Amount                decimal.Decimal   `json:"amount" gorm:"type:decimal(20,2);not null;default:0"`
+	PreviousOriginalLimit decimal.Decimal   `json:"previous_original_limit" gorm:"type:decimal(20,2);not null;default:0"`
+	PreviousCurrentLimit  decimal.Decimal   `json:"previous_current_limit" gorm:"type:decimal(20,2);not null;default:0"`
+	OriginalLimit         decimal.Decimal   `json:"original_limit" gorm:"type:decimal(20,2);not null;default:0"`
+	CurrentLimit          decimal.Decimal   `json:"current_limit" gorm:"type:decimal(20,2);not null;default:0"`
+	Reason                string            `json:"reason" gorm:"type:varchar(255);not null"`
+	ChangedBy             *uint             `json:"changed_by" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (LimitChange) TableName() string {
+	return "limit_changes"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (l *LimitChange) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.UUID == uuid.Nil {
+		l.UUID = uuid.New()
+	}
+	return
+}
+
+func (a *LimitChangeAction) Scan(value interface{}) error {
+	*a = LimitChangeAction(value.([]byte))
+	return nil
+}
+
+func (a LimitChangeAction) Value() (driver.Value, error) {
+	return string(a), nil
+}
@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// LimitPolicy is a template of tenor limits given to user on activation. Salary and
+// age condition are optional, empty one match every user. When many policies match,
+// the highest priority win. PoolMode decide how transactions consume the given limits,
+// GlobalCap is only used on LimitPoolGlobalCap.
+type LimitPolicy struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	UUID        uuid.UUID           `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Name        string              `json:"name" gorm:"type:varchar(255);not null"`
+	Description string              `json:"description" gorm:"type:varchar(255)"`
+	MinSalary   decimal.NullDecimal `json:"min_salary" gorm:"type:decimal(20,2)"`
+	MaxSalary   decimal.NullDecimal `json:"max_salary" gorm:"type:decimal(20,2)"`
+	MinAge      *uint               `json:"min_age" gorm:"type:smallint unsigned"`
+	MaxAge      *uint               `json:"max_age" gorm:"type:smallint unsigned"`
+	Priority    int                 `json:"priority" gorm:"not null;default:0"`
+	IsActive    bool                `json:"is_active" gorm:"not null;default:true"`
+	PoolMode    LimitPoolMode       `json:"pool_mode" gorm:"type:enum('shared', 'per_tenor', 'global_cap');not null;default:'shared'"`
+	GlobalCap   decimal.NullDecimal `json:"global_cap" gorm:"type:decimal(20,2)"`
+
+	// Relationship
+	Items []LimitPolicyItem `json:"items" gorm:"foreignKey:LimitPolicyID"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type LimitPolicyItem struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	LimitPolicyID uint            `json:"limit_policy_id" gorm:"not null;uniqueIndex:idx_limit_policy_tenor"`
+	Tenor         uint            `json:"tenor" gorm:"type:smallint unsigned;not null;uniqueIndex:idx_limit_policy_tenor"`
+	Amount        decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (LimitPolicy) TableName() string {
+	return "limit_policies"
+}
+
+func (LimitPolicyItem) TableName() string {
+	return "limit_policy_items"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (p *LimitPolicy) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == uuid.Nil {
+		p.UUID = uuid.New()
+	}
+	return
+}
+
+// HasCondition tell whether the policy limit who can get it
+func (p *LimitPolicy) HasCondition() bool {
+	return p.MinSalary.Valid || p.MaxSalary.Valid || p.MinAge != nil || p.MaxAge != nil
+}
+
+// Eligible check the salary and age against the policy condition, bound is inclusive
+func (p *LimitPolicy) Eligible(salary decimal.Decimal, age uint) bool {
+	if p.MinSalary.Valid && salary.LessThan(p.MinSalary.Decimal) {
+		return false
+	}
+	if p.MaxSalary.Valid && salary.GreaterThan(p.MaxSalary.Decimal) {
+		return false
+	}
+	if p.MinAge != nil && age < *p.MinAge {
+		return false
+	}
+	if p.MaxAge != nil && age > *p.MaxAge {
+		return false
+	}
+
+	return true
+}
+
+// LimitItems return the tenor limits given by the policy, on LimitPoolGlobalCap the global
+// cap is added as the item of GlobalLimitTenor
+func (p *LimitPolicy) LimitItems() []LimitPolicyItem {
+	items := append([]LimitPolicyItem{}, p.Items...)
+	if p.PoolMode == LimitPoolGlobalCap && p.GlobalCap.Valid {
+		items = append(items, LimitPolicyItem{
+			LimitPolicyID: p.ID,
+			Tenor:         GlobalLimitTenor,
+			Amount:        p.GlobalCap.Decimal,
+		})
+	}
+
+	return items
+}
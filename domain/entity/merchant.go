@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Merchant is the dealer that sell the financed asset. Its share of a contract is paid to
+// the bank account SettlementDays after the contract is disbursed (0 is the same day).
+type Merchant struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UUID              uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Code              string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name              string    `json:"name" gorm:"type:varchar(255);not null"`
+	BankName          string    `json:"bank_name" gorm:"type:varchar(100);not null"`
+	BankAccountNumber string    `json:"bank_account_number" gorm:"type:varchar(50);not null"`
+	BankAccountName   string    `json:"bank_account_name" gorm:"type:varchar(255);not null"`
+	SettlementDays    uint      `json:"settlement_days" gorm:"type:smallint unsigned;not null;default:0"`
+	IsActive          bool      `json:"is_active" gorm:"not null;default:true"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (Merchant) TableName() string {
+	return "merchants"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (m *Merchant) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.UUID == uuid.Nil {
+		m.UUID = uuid.New()
+	}
+	return
+}
+
+// SettlementDate is the day the merchant should be paid for a contract disbursed at
+func (m *Merchant) SettlementDate(disbursed_at time.Time) time.Time {
+	return disbursed_at.AddDate(0, 0, int(m.SettlementDays))
+}
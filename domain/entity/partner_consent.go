@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PartnerConsent is the customer permission for a merchant backend to read their limit and
+// create transaction for them, revoked consent can be granted again
+type PartnerConsent struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UUID       uuid.UUID  `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	MerchantID uint       `json:"merchant_id" gorm:"not null;uniqueIndex:idx_partner_consent"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_partner_consent"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	// Relationship
+	Merchant *Merchant `json:"merchant" gorm:"foreignKey:MerchantID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PartnerConsent) TableName() string {
+	return "partner_consents"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (c *PartnerConsent) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return
+}
+
+func (c *PartnerConsent) IsActive() bool {
+	return c.RevokedAt == nil
+}
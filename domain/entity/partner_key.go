@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PartnerKey is an API credential of a merchant backend. The secret is shown once and
+// kept encrypted with PARTNER_SECRET_KEY, requests are signed with the secret itself.
+type PartnerKey struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UUID         uuid.UUID  `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	MerchantID   uint       `json:"merchant_id" gorm:"index;not null"`
+	KeyID        string     `json:"key_id" gorm:"type:varchar(64);uniqueIndex;not null"`
+	SecretCipher string     `json:"-" gorm:"type:varchar(255);not null"`
+	Name         string     `json:"name" gorm:"type:varchar(100);not null"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+
+	// Relationship
+	Merchant *Merchant `json:"merchant" gorm:"foreignKey:MerchantID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PartnerKey) TableName() string {
+	return "partner_keys"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (k *PartnerKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.UUID == uuid.Nil {
+		k.UUID = uuid.New()
+	}
+	return
+}
+
+func (k *PartnerKey) IsActive() bool {
+	return k.RevokedAt == nil
+}
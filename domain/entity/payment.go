@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,17 +10,38 @@ import (
 	"gorm.io/gorm"
 )
 
+type PaymentType string
+
+const (
+	PaymentTypePayment  PaymentType = "payment"
+	PaymentTypeReversal PaymentType = "reversal"
+)
+
+// PaymentMethodCustomerCredit is the method of a payment drawn from customer credit
+const PaymentMethodCustomerCredit = "customer_credit"
+
+// Payment is money received for a transaction. InstallmentID is the first installment
+// the payment is allocated to. A reversal is stored as another payment with negative
+// amount and ReversalOfID pointing to the reversed payment. A payment drawn from customer
+// credit has CreditSourceID pointing to the credit it used.
 type Payment struct {
-	ID            uint            `json:"id" gorm:"primaryKey"`
-	UUID          uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
-	TransactionID uint            `json:"transaction_id" gorm:"index;not null"`
-	InstallmentID uint            `json:"installment_id" gorm:"index;not null"`
-	Amount        decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
-	PaymentMethod string          `json:"payment_method" gorm:"size:50;not null"`
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	UUID           uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	TransactionID  uint            `json:"transaction_id" gorm:"index;not null"`
+	InstallmentID  uint            `json:"installment_id" gorm:"index;not null"`
+	Amount         decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+	PaymentMethod  string          `json:"payment_method" gorm:"size:50;not null"`
+	Type           PaymentType     `json:"type" gorm:"type:enum('payment', 'reversal');default:'payment'"`
+	ReversalOfID   *uint           `json:"reversal_of_id" gorm:"index"`
+	Reason         string          `json:"reason" gorm:"type:varchar(255)"`
+	ReversedAt     sql.NullTime    `json:"reversed_at"`
+	CreditSourceID *uint           `json:"credit_source_id" gorm:"index"`
 
 	// Relationship
 	Transaction Transaction            `json:"transaction" gorm:"foreignKey:TransactionID"`
 	Installment TransactionInstallment `json:"installment" gorm:"foreignKey:InstallmentID"`
+	Allocations []PaymentAllocation    `json:"allocations" gorm:"foreignKey:PaymentID"`
+	Credit      *CustomerCredit        `json:"credit" gorm:"foreignKey:PaymentID"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -37,3 +60,18 @@ func (p *Payment) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// IsEffective tell if the payment still count toward the transaction, a reversed payment
+// and its reversal cancel each other
+func (p *Payment) IsEffective() bool {
+	return p.Type != PaymentTypeReversal && !p.ReversedAt.Valid
+}
+
+func (p *PaymentType) Scan(value interface{}) error {
+	*p = PaymentType(value.([]byte))
+	return nil
+}
+
+func (p PaymentType) Value() (driver.Value, error) {
+	return string(p), nil
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentAllocation is the part of a payment applied to one installment, split
+// by what it settled
+type PaymentAllocation struct {
+	ID              uint            `json:"id" gorm:"primaryKey"`
+	PaymentID       uint            `json:"payment_id" gorm:"index;not null"`
+	InstallmentID   uint            `json:"installment_id" gorm:"index;not null"`
+	PenaltyAmount   decimal.Decimal `json:"penalty_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	InterestAmount  decimal.Decimal `json:"interest_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	PrincipalAmount decimal.Decimal `json:"principal_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	Amount          decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+
+	// Relationship
+	Installment TransactionInstallment `json:"installment" gorm:"foreignKey:InstallmentID"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PaymentAllocation) TableName() string {
+	return "payment_allocations"
+}
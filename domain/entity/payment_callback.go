@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PaymentCallbackEvent string
+
+const (
+	PaymentCallbackVAPaid  PaymentCallbackEvent = "va.paid"
+	PaymentCallbackQRPaid  PaymentCallbackEvent = "qr.paid"
+	PaymentCallbackExpired PaymentCallbackEvent = "expired"
+)
+
+type PaymentCallbackStatus string
+
+const (
+	// PaymentCallbackProcessed callback is posted as a payment
+	PaymentCallbackProcessed PaymentCallbackStatus = "processed"
+	// PaymentCallbackRejected callback is paid but can not be posted, need manual follow up
+	PaymentCallbackRejected PaymentCallbackStatus = "rejected"
+	// PaymentCallbackIgnored callback does not move money, like expired
+	PaymentCallbackIgnored PaymentCallbackStatus = "ignored"
+)
+
+// PaymentCallback keep every payment gateway callback received, provider and provider
+// transaction id are unique so the same callback is never posted twice
+type PaymentCallback struct {
+	ID                    uint                  `json:"id" gorm:"primaryKey"`
+	UUID                  uuid.UUID             `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Provider              string                `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_provider_transaction"`
+	ProviderTransactionID string                `json:"provider_transaction_id" gorm:"type:varchar(100);not null;uniqueIndex:idx_provider_transaction"`
+	Event                 PaymentCallbackEvent  `json:"event" gorm:"type:enum('va.paid', 'qr.paid', 'expired');not null"`
+	Status                PaymentCallbackStatus `json:"status" gorm:"type:enum('processed', 'rejected', 'ignored');not null"`
+	TransactionID         *uint                 `json:"transaction_id" gorm:"index"`
+	PaymentID             *uint                 `json:"payment_id" gorm:"index"`
+	Amount                decimal.Decimal       `json:"amount" gorm:"type:decimal(20,2);not null;default:0"`
+	Message               string                `json:"message" gorm:"type:varchar(255)"`
+	Payload               string                `json:"payload" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PaymentCallback) TableName() string {
+	return "payment_callbacks"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (p *PaymentCallback) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == uuid.Nil {
+		p.UUID = uuid.New()
+	}
+	return
+}
+
+func (e *PaymentCallbackEvent) Scan(value interface{}) error {
+	*e = PaymentCallbackEvent(value.([]byte))
+	return nil
+}
+
+func (e PaymentCallbackEvent) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (s *PaymentCallbackStatus) Scan(value interface{}) error {
+	*s = PaymentCallbackStatus(value.([]byte))
+	return nil
+}
+
+func (s PaymentCallbackStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
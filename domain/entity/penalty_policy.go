@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PenaltyType string
+
+const (
+	// PenaltyDaily charge Rate percent of unpaid installment amount for every day past grace
+	PenaltyDaily PenaltyType = "daily"
+	// PenaltyFlat charge Amount once when installment pass grace
+	PenaltyFlat PenaltyType = "flat"
+)
+
+type PenaltyPolicy struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	UUID      uuid.UUID       `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Name      string          `json:"name" gorm:"type:varchar(255);not null"`
+	Type      PenaltyType     `json:"type" gorm:"type:enum('daily', 'flat');not null"`
+	Rate      decimal.Decimal `json:"rate" gorm:"type:decimal(9,4);not null;default:0"`
+	Amount    decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null;default:0"`
+	Cap       decimal.Decimal `json:"cap" gorm:"type:decimal(20,2);not null;default:0"`
+	GraceDays uint            `json:"grace_days" gorm:"type:smallint unsigned;not null;default:0"`
+	IsActive  bool            `json:"is_active" gorm:"not null;default:true"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (PenaltyPolicy) TableName() string {
+	return "penalty_policies"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (p *PenaltyPolicy) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == uuid.Nil {
+		p.UUID = uuid.New()
+	}
+	return
+}
+
+func (p *PenaltyType) Scan(value interface{}) error {
+	*p = PenaltyType(value.([]byte))
+	return nil
+}
+
+func (p PenaltyType) Value() (driver.Value, error) {
+	return string(p), nil
+}
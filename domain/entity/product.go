@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type AdminFeeType string
+
+const (
+	// AdminFeeFlat charge AdminFeeAmount on every transaction
+	AdminFeeFlat AdminFeeType = "flat"
+	// AdminFeePercentage charge AdminFeeRate percent of the otr
+	AdminFeePercentage AdminFeeType = "percentage"
+	// AdminFeeTiered charge the amount of the highest tier the otr reach
+	AdminFeeTiered AdminFeeType = "tiered"
+)
+
+// Product is a loan product sold to user, it own the interest, admin fee and tenor rules
+// so transaction only pick the product by its code. InterestRate and AdminFeeRate are in
+// percent (1.5 means 1.5%), MaxOnTheRoad is optional.
+type Product struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	UUID           uuid.UUID           `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
+	Code           string              `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name           string              `json:"name" gorm:"type:varchar(255);not null"`
+	Description    string              `json:"description" gorm:"type:varchar(255)"`
+	InterestMethod InterestMethod      `json:"interest_method" gorm:"type:enum('flat', 'annuity', 'effective');not null;default:'flat'"`
+	InterestRate   decimal.Decimal     `json:"interest_rate" gorm:"type:decimal(9,4);not null;default:0"`
+	AdminFeeType   AdminFeeType        `json:"admin_fee_type" gorm:"type:enum('flat', 'percentage', 'tiered');not null;default:'flat'"`
+	AdminFeeAmount decimal.Decimal     `json:"admin_fee_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	AdminFeeRate   decimal.Decimal     `json:"admin_fee_rate" gorm:"type:decimal(9,4);not null;default:0"`
+	MinOnTheRoad   decimal.Decimal     `json:"min_on_the_road" gorm:"type:decimal(20,2);not null;default:0"`
+	MaxOnTheRoad   decimal.NullDecimal `json:"max_on_the_road" gorm:"type:decimal(20,2)"`
+	IsActive       bool                `json:"is_active" gorm:"not null;default:true"`
+
+	// Relationship
+	Tenors   []ProductTenor   `json:"tenors" gorm:"foreignKey:ProductID"`
+	FeeTiers []ProductFeeTier `json:"fee_tiers" gorm:"foreignKey:ProductID"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type ProductTenor struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	ProductID uint `json:"product_id" gorm:"not null;uniqueIndex:idx_product_tenor"`
+	Tenor     uint `json:"tenor" gorm:"type:smallint unsigned;not null;uniqueIndex:idx_product_tenor"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductFeeTier is the admin fee for otr starting from MinOnTheRoad on AdminFeeTiered
+type ProductFeeTier struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	ProductID    uint            `json:"product_id" gorm:"not null;uniqueIndex:idx_product_fee_tier"`
+	MinOnTheRoad decimal.Decimal `json:"min_on_the_road" gorm:"type:decimal(20,2);not null;uniqueIndex:idx_product_fee_tier"`
+	Amount       decimal.Decimal `json:"amount" gorm:"type:decimal(20,2);not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Product) TableName() string {
+	return "products"
+}
+
+func (ProductTenor) TableName() string {
+	return "product_tenors"
+}
+
+func (ProductFeeTier) TableName() string {
+	return "product_fee_tiers"
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field of the struct.
+func (p *Product) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.UUID == uuid.Nil {
+		p.UUID = uuid.New()
+	}
+	return
+}
+
+// AllowTenor tell whether the tenor is offered by the product
+func (p *Product) AllowTenor(tenor uint) bool {
+	for _, allowed := range p.Tenors {
+		if allowed.Tenor == tenor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowOnTheRoad check the otr against the product range, bound is inclusive
+func (p *Product) AllowOnTheRoad(otr decimal.Decimal) bool {
+	if otr.LessThan(p.MinOnTheRoad) {
+		return false
+	}
+	if p.MaxOnTheRoad.Valid && otr.GreaterThan(p.MaxOnTheRoad.Decimal) {
+		return false
+	}
+
+	return true
+}
+
+// AdminFee calculate the admin fee of the otr, otr below the lowest tier pay no fee
+func (p *Product) AdminFee(otr decimal.Decimal) decimal.Decimal {
+	switch p.AdminFeeType {
+	case AdminFeePercentage:
+		return otr.Mul(p.AdminFeeRate).Div(decimal.NewFromInt(100)).Round(2)
+	case AdminFeeTiered:
+		fee := decimal.Zero
+		reached := decimal.Zero
+		for _, tier := range p.FeeTiers {
+			if otr.GreaterThanOrEqual(tier.MinOnTheRoad) && tier.MinOnTheRoad.GreaterThanOrEqual(reached) {
+				fee = tier.Amount
+				reached = tier.MinOnTheRoad
+			}
+		}
+		return fee
+	default:
+		return p.AdminFeeAmount
+	}
+}
+
+func (t *AdminFeeType) Scan(value interface{}) error {
+	*t = AdminFeeType(value.([]byte))
+	return nil
+}
+
+func (t AdminFeeType) Value() (driver.Value, error) {
+	return string(t), nil
+}
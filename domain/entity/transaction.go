@@ -2,6 +2,7 @@ package entity
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,22 +14,35 @@ type Transaction struct {
 	ID                 uint              `json:"id" gorm:"primaryKey"`
 	UUID               uuid.UUID         `json:"uuid" gorm:"uniqueIndex;type:char(36);not null"`
 	UserID             uint              `json:"user_id" gorm:"index;not null"`
+	ProductID          *uint             `json:"product_id" gorm:"index"`
+	MerchantID         *uint             `json:"merchant_id" gorm:"index"`
 	AssetName          string            `json:"asset_name" gorm:"not null"`
-	ContractNumber     string            `json:"contract_number" gorm:"type:varchar(255);index;not null"`
+	ContractNumber     string            `json:"contract_number" gorm:"type:varchar(255);uniqueIndex:uidx_transactions_contract_number;not null"`
+	ContractFile       string            `json:"contract_file" gorm:"type:varchar(255)"`
 	OnTheRoad          decimal.Decimal   `json:"on_the_road" gorm:"type:decimal(20,2);not null"`
 	AdminFee           decimal.Decimal   `json:"admin_fee" gorm:"type:decimal(20,2);not null"`
 	TotalLoanAmount    decimal.Decimal   `json:"total_loan_amount" gorm:"->;type:decimal(20,2) GENERATED ALWAYS AS (on_the_road + admin_fee) STORED"`
 	MonthlyInstallment decimal.Decimal   `json:"monthly_installment" gorm:"type:decimal(20,2);not null"`
 	InterestAmount     decimal.Decimal   `json:"interest_amount" gorm:"type:decimal(20,2);not null"`
+	InterestMethod     InterestMethod    `json:"interest_method" gorm:"type:enum('flat', 'annuity', 'effective');default:'flat'"`
+	InterestRate       decimal.Decimal   `json:"interest_rate" gorm:"type:decimal(9,4);not null;default:0"`
 	Tenor              uint              `json:"tenor" gorm:"type:smallint unsigned;not null"`
+	BillingDay         uint              `json:"billing_day" gorm:"type:tinyint unsigned;not null;default:0"`
+	LimitPoolMode      LimitPoolMode     `json:"limit_pool_mode" gorm:"type:enum('shared', 'per_tenor', 'global_cap');not null;default:'shared'"`
 	StartDate          time.Time         `json:"start_date" gorm:"type:date;not null"`
 	EndDate            time.Time         `json:"end_date" gorm:"type:date"`
-	Status             TransactionStatus `json:"status" gorm:"type:enum('active', 'paid', 'canceled');default:'active'"`
+	CreatedBy          *uint             `json:"created_by" gorm:"index"`
+	Status             TransactionStatus `json:"status" gorm:"type:enum('draft', 'pending_approval', 'approved', 'active', 'paid', 'canceled', 'defaulted', 'written_off');default:'active'"`
 
 	// Relationship
 	User         User                     `json:"user" gorm:"foreignKey:UserID"`
+	Product      *Product                 `json:"product" gorm:"foreignKey:ProductID"`
+	Merchant     *Merchant                `json:"merchant" gorm:"foreignKey:MerchantID"`
+	Disbursement *Disbursement            `json:"disbursement" gorm:"foreignKey:TransactionID"`
 	Installments []TransactionInstallment `json:"installments" gorm:"foreignKey:TransactionID"`
 	Payments     []Payment                `json:"payments" gorm:"foreignKey:TransactionID"`
+	Revisions    []TransactionRevision    `json:"revisions" gorm:"foreignKey:TransactionID"`
+	StatusLogs   []TransactionStatusLog   `json:"status_logs" gorm:"foreignKey:TransactionID"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -51,9 +65,83 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) (err error) {
 type TransactionStatus string
 
 const (
-	TransactionActive   TransactionStatus = "active"
-	TransactionPaid     TransactionStatus = "paid"
-	TransactionCanceled TransactionStatus = "canceled"
+	TransactionDraft           TransactionStatus = "draft"
+	TransactionPendingApproval TransactionStatus = "pending_approval"
+	TransactionApproved        TransactionStatus = "approved"
+	// TransactionActive is a disbursed contract that is being repaid
+	TransactionActive     TransactionStatus = "active"
+	TransactionPaid       TransactionStatus = "paid"
+	TransactionCanceled   TransactionStatus = "canceled"
+	TransactionDefaulted  TransactionStatus = "defaulted"
+	TransactionWrittenOff TransactionStatus = "written_off"
+)
+
+// transactionTransitions list the status a transaction can move to from each status.
+// Paid go back to active when its payment is reversed, defaulted go back to active when
+// the customer catch up.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionDraft:           {TransactionPendingApproval, TransactionCanceled},
+	TransactionPendingApproval: {TransactionApproved, TransactionCanceled},
+	TransactionApproved:        {TransactionActive, TransactionCanceled},
+	TransactionActive:          {TransactionPaid, TransactionCanceled, TransactionDefaulted},
+	TransactionPaid:            {TransactionActive},
+	TransactionDefaulted:       {TransactionActive, TransactionPaid, TransactionWrittenOff},
+}
+
+// CanTransitionTo tell whether the status is allowed to move to the given status
+func (t TransactionStatus) CanTransitionTo(to TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[t] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LimitHoldingStatuses list the status of contracts whose otr is still taken from the
+// limits and is given back on reject, cancel or payoff
+var LimitHoldingStatuses = []TransactionStatus{TransactionPendingApproval, TransactionApproved, TransactionActive, TransactionDefaulted}
+
+// RepayingStatuses list the status of contracts that still accept installment payment
+var RepayingStatuses = []TransactionStatus{TransactionActive, TransactionDefaulted}
+
+// IsRepaying tell whether the contract still accept installment payment
+func (t TransactionStatus) IsRepaying() bool {
+	for _, status := range RepayingStatuses {
+		if t == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transition move the transaction to the given status and return the log to record,
+// illegal move leave the transaction untouched. ChangedBy is nil for system change
+func (t *Transaction) Transition(to TransactionStatus, reason string, changed_by *uint) (*TransactionStatusLog, error) {
+	if !t.Status.CanTransitionTo(to) {
+		return nil, fmt.Errorf("transaction can not move from %s to %s", t.Status, to)
+	}
+
+	statusLog := &TransactionStatusLog{
+		TransactionID: t.ID,
+		FromStatus:    t.Status,
+		ToStatus:      to,
+		Reason:        reason,
+		ChangedBy:     changed_by,
+	}
+	t.Status = to
+
+	return statusLog, nil
+}
+
+type InterestMethod string
+
+const (
+	InterestFlat      InterestMethod = "flat"
+	InterestAnnuity   InterestMethod = "annuity"
+	InterestEffective InterestMethod = "effective"
 )
 
 func (t *TransactionStatus) Scan(value interface{}) error {
@@ -64,3 +152,12 @@ func (t *TransactionStatus) Scan(value interface{}) error {
 func (t TransactionStatus) Value() (driver.Value, error) {
 	return string(t), nil
 }
+
+func (i *InterestMethod) Scan(value interface{}) error {
+	*i = InterestMethod(value.([]byte))
+	return nil
+}
+
+func (i InterestMethod) Value() (driver.Value, error) {
+	return string(i), nil
+}
@@ -26,14 +26,19 @@ type TransactionInstallment struct {
 	TransactionID     uint            `json:"transaction_id" gorm:"not null"`
 	InstallmentNumber uint            `json:"installment_number" gorm:"not null;type:smallint unsigned"`
 	DueDate           time.Time       `json:"due_date" gorm:"type:date;not null"`
+	PrincipalDue      decimal.Decimal `json:"principal_due" gorm:"type:decimal(20,2);not null;default:0"`
+	InterestDue       decimal.Decimal `json:"interest_due" gorm:"type:decimal(20,2);not null;default:0"`
 	AmountDue         decimal.Decimal `json:"amount_due" gorm:"type:decimal(20,2);not null"`
+	Outstanding       decimal.Decimal `json:"outstanding" gorm:"type:decimal(20,2);not null;default:0"`
 	AmountPaid        decimal.Decimal `json:"amount_paid" gorm:"type:decimal(20,2);default:0"`
 	PaymentStatus     PaymentStatus   `json:"payment_status" gorm:"type:enum('pending', 'partial', 'paid', 'overdue', 'failed');default:'pending'"`
 	PaidAt            sql.NullTime    `json:"paid_at"`
+	DaysPastDue       uint            `json:"days_past_due" gorm:"not null;default:0;type:smallint unsigned"`
 
 	// Relationships
-	Transaction Transaction `json:"transaction" gorm:"foreignKey:TransactionID"`
-	Payments    []Payment   `json:"payments" gorm:"foreignKey:InstallmentID"`
+	Transaction Transaction          `json:"transaction" gorm:"foreignKey:TransactionID"`
+	Payments    []Payment            `json:"payments" gorm:"foreignKey:InstallmentID"`
+	Penalties   []InstallmentPenalty `json:"penalties" gorm:"foreignKey:InstallmentID"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransactionRevision is an append only snapshot of contract terms taken
+// right before the transaction is amended
+type TransactionRevision struct {
+	ID                 uint            `json:"id" gorm:"primaryKey"`
+	TransactionID      uint            `json:"transaction_id" gorm:"not null;index:idx_transaction_revision,unique"`
+	Revision           uint            `json:"revision" gorm:"type:smallint unsigned;not null;index:idx_transaction_revision,unique"`
+	ProductID          *uint           `json:"product_id"`
+	AssetName          string          `json:"asset_name" gorm:"not null"`
+	OnTheRoad          decimal.Decimal `json:"on_the_road" gorm:"type:decimal(20,2);not null"`
+	AdminFee           decimal.Decimal `json:"admin_fee" gorm:"type:decimal(20,2);not null"`
+	MonthlyInstallment decimal.Decimal `json:"monthly_installment" gorm:"type:decimal(20,2);not null"`
+	InterestAmount     decimal.Decimal `json:"interest_amount" gorm:"type:decimal(20,2);not null"`
+	InterestMethod     InterestMethod  `json:"interest_method" gorm:"type:enum('flat', 'annuity', 'effective');default:'flat'"`
+	InterestRate       decimal.Decimal `json:"interest_rate" gorm:"type:decimal(9,4);not null;default:0"`
+	Tenor              uint            `json:"tenor" gorm:"type:smallint unsigned;not null"`
+	BillingDay         uint            `json:"billing_day" gorm:"type:tinyint unsigned;not null;default:0"`
+	StartDate          time.Time       `json:"start_date" gorm:"type:date;not null"`
+	EndDate            time.Time       `json:"end_date" gorm:"type:date"`
+	ChangedBy          uint            `json:"changed_by" gorm:"not null"`
+
+	// Relationship
+	Transaction Transaction `json:"transaction" gorm:"foreignKey:TransactionID"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TransactionRevision) TableName() string {
+	return "transaction_revisions"
+}
+
+// NewTransactionRevision copy current contract terms of a transaction as revision
+func NewTransactionRevision(transaction *Transaction, revision uint, changed_by uint) *TransactionRevision {
+	return &TransactionRevision{
+		TransactionID:      transaction.ID,
+		Revision:           revision,
+		ProductID:          transaction.ProductID,
+		AssetName:          transaction.AssetName,
+		OnTheRoad:          transaction.OnTheRoad,
+		AdminFee:           transaction.AdminFee,
+		MonthlyInstallment: transaction.MonthlyInstallment,
+		InterestAmount:     transaction.InterestAmount,
+		InterestMethod:     transaction.InterestMethod,
+		InterestRate:       transaction.InterestRate,
+		Tenor:              transaction.Tenor,
+		BillingDay:         transaction.BillingDay,
+		StartDate:          transaction.StartDate,
+		EndDate:            transaction.EndDate,
+		ChangedBy:          changed_by,
+	}
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// TransactionStatusLog is an append only history of transaction status change. FromStatus
+// is empty on the first status of the contract, ChangedBy is empty when the change is made
+// by the system, like a gateway callback that pay off the transaction.
+type TransactionStatusLog struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	TransactionID uint              `json:"transaction_id" gorm:"index;not null"`
+	FromStatus    TransactionStatus `json:"from_status" gorm:"type:varchar(20);not null;default:''"`
+	ToStatus      TransactionStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	Reason        string            `json:"reason" gorm:"type:varchar(255);not null"`
+	ChangedBy     *uint             `json:"changed_by" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TransactionStatusLog) TableName() string {
+	return "transaction_status_logs"
+}
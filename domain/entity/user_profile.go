@@ -26,3 +26,17 @@ type UserProfile struct {
 func (UserProfile) TableName() string {
 	return "user_profiles"
 }
+
+// Age is the full years of the user on the given date
+func (p *UserProfile) Age(as_of time.Time) uint {
+	age := as_of.Year() - p.BirthDate.Year()
+	if as_of.Month() < p.BirthDate.Month() ||
+		(as_of.Month() == p.BirthDate.Month() && as_of.Day() < p.BirthDate.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0
+	}
+
+	return uint(age)
+}
@@ -0,0 +1,169 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Chart of accounts code
+const (
+	AccountCash           = "1000"
+	AccountLoanReceivable = "1100"
+	AccountCustomerCredit = "2100"
+	AccountFeeIncome      = "4100"
+	AccountInterestIncome = "4200"
+	AccountPenaltyIncome  = "4300"
+)
+
+// Source type of journal entry
+const (
+	SourceTransaction = "transaction"
+	SourcePayment     = "payment"
+)
+
+// Accounts is the chart of accounts seeded on start up
+func Accounts() []entity.LedgerAccount {
+	return []entity.LedgerAccount{
+		{Code: AccountCash, Name: "Cash", Type: entity.LedgerAsset},
+		{Code: AccountLoanReceivable, Name: "Loan Receivable", Type: entity.LedgerAsset},
+		{Code: AccountCustomerCredit, Name: "Customer Credit", Type: entity.LedgerLiability},
+		{Code: AccountFeeIncome, Name: "Fee Income", Type: entity.LedgerIncome},
+		{Code: AccountInterestIncome, Name: "Interest Income", Type: entity.LedgerIncome},
+		{Code: AccountPenaltyIncome, Name: "Penalty Income", Type: entity.LedgerIncome},
+	}
+}
+
+// Disbursement book the financed amount (otr plus admin fee) as receivable, otr is paid
+// out and admin fee is earned
+func Disbursement(transaction *entity.Transaction) *entity.JournalEntry {
+	entry := newEntry(SourceTransaction, transaction.ID, fmt.Sprintf("Disbursement of contract %s", transaction.ContractNumber))
+
+	debit(entry, AccountLoanReceivable, transaction.OnTheRoad.Add(transaction.AdminFee))
+	credit(entry, AccountCash, transaction.OnTheRoad)
+	credit(entry, AccountFeeIncome, transaction.AdminFee)
+
+	return entry
+}
+
+// Amendment book the difference between the previous and the amended disbursement,
+// entry has no posting when otr and admin fee did not change
+func Amendment(transaction *entity.Transaction, previous_otr decimal.Decimal, previous_fee decimal.Decimal) *entity.JournalEntry {
+	entry := newEntry(SourceTransaction, transaction.ID, fmt.Sprintf("Amendment of contract %s", transaction.ContractNumber))
+
+	otr := transaction.OnTheRoad.Sub(previous_otr)
+	fee := transaction.AdminFee.Sub(previous_fee)
+
+	debit(entry, AccountLoanReceivable, otr.Add(fee))
+	credit(entry, AccountCash, otr)
+	credit(entry, AccountFeeIncome, fee)
+
+	return entry
+}
+
+// Cancellation undo the disbursement of a cancelled contract. Every payment must be
+// reversed before, otherwise the receivable it repaid is taken back twice
+func Cancellation(transaction *entity.Transaction) *entity.JournalEntry {
+	return Reverse(Disbursement(transaction), SourceTransaction, transaction.ID,
+		fmt.Sprintf("Cancellation of contract %s", transaction.ContractNumber))
+}
+
+// Payment book cash received, split by its allocations into principal, interest and
+// penalty. Amount kept as customer credit is a liability, and what is left outside
+// allocation and credit, like early termination fee, is earned as fee. Payment drawn
+// from customer credit use the liability instead of cash
+func Payment(payment *entity.Payment) *entity.JournalEntry {
+	entry := newEntry(SourcePayment, payment.ID, fmt.Sprintf("Payment %s", payment.UUID))
+
+	principal, interest, penalty := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, allocation := range payment.Allocations {
+		principal = principal.Add(allocation.PrincipalAmount)
+		interest = interest.Add(allocation.InterestAmount)
+		penalty = penalty.Add(allocation.PenaltyAmount)
+	}
+
+	customerCredit := decimal.Zero
+	if payment.Credit != nil {
+		customerCredit = payment.Credit.Amount
+	}
+
+	fee := payment.Amount.Sub(principal).Sub(interest).Sub(penalty).Sub(customerCredit)
+
+	source := AccountCash
+	if payment.CreditSourceID != nil {
+		source = AccountCustomerCredit
+	}
+
+	debit(entry, source, payment.Amount)
+	credit(entry, AccountLoanReceivable, principal)
+	credit(entry, AccountInterestIncome, interest)
+	credit(entry, AccountPenaltyIncome, penalty)
+	credit(entry, AccountCustomerCredit, customerCredit)
+	credit(entry, AccountFeeIncome, fee)
+
+	return entry
+}
+
+// Reverse return the opposite entry, every debit become credit and the other way around
+func Reverse(entry *entity.JournalEntry, source_type string, source_id uint, description string) *entity.JournalEntry {
+	reversed := newEntry(source_type, source_id, description)
+	for _, posting := range entry.Postings {
+		reversed.Postings = append(reversed.Postings, entity.JournalPosting{
+			AccountCode: posting.AccountCode,
+			Debit:       posting.Credit,
+			Credit:      posting.Debit,
+		})
+	}
+
+	return reversed
+}
+
+// Validate check entry has posting and total debit equal total credit
+func Validate(entry *entity.JournalEntry) error {
+	if len(entry.Postings) == 0 {
+		return fmt.Errorf("journal entry %s %d has no posting", entry.SourceType, entry.SourceID)
+	}
+
+	totalDebit, totalCredit := decimal.Zero, decimal.Zero
+	for _, posting := range entry.Postings {
+		if posting.Debit.IsNegative() || posting.Credit.IsNegative() {
+			return fmt.Errorf("journal entry %s %d has negative posting", entry.SourceType, entry.SourceID)
+		}
+		totalDebit = totalDebit.Add(posting.Debit)
+		totalCredit = totalCredit.Add(posting.Credit)
+	}
+
+	if !totalDebit.Equal(totalCredit) {
+		return fmt.Errorf("journal entry %s %d is not balanced, debit %s credit %s",
+			entry.SourceType, entry.SourceID, totalDebit.StringFixed(2), totalCredit.StringFixed(2))
+	}
+
+	return nil
+}
+
+func newEntry(source_type string, source_id uint, description string) *entity.JournalEntry {
+	return &entity.JournalEntry{
+		SourceType:  source_type,
+		SourceID:    source_id,
+		Description: description,
+		PostedAt:    time.Now(),
+		Postings:    []entity.JournalPosting{},
+	}
+}
+
+// debit add a debit posting, negative amount is posted as credit and zero is skipped
+func debit(entry *entity.JournalEntry, account string, amount decimal.Decimal) {
+	switch {
+	case amount.IsPositive():
+		entry.Postings = append(entry.Postings, entity.JournalPosting{AccountCode: account, Debit: amount, Credit: decimal.Zero})
+	case amount.IsNegative():
+		entry.Postings = append(entry.Postings, entity.JournalPosting{AccountCode: account, Debit: decimal.Zero, Credit: amount.Neg()})
+	}
+}
+
+// credit add a credit posting, negative amount is posted as debit and zero is skipped
+func credit(entry *entity.JournalEntry, account string, amount decimal.Decimal) {
+	debit(entry, account, amount.Neg())
+}
@@ -0,0 +1,153 @@
+package limit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrFrozen        = errors.New("limit is frozen")
+	ErrOverlimit     = errors.New("overlimit")
+	ErrTenorNotFound = errors.New("user limit tenor not found")
+)
+
+// PoolMode return the pool mode of the tenor limit, shared when the tenor has no limit
+func PoolMode(limits []entity.Limit, tenor uint) entity.LimitPoolMode {
+	for _, limit := range limits {
+		if limit.Tenor == tenor && limit.PoolMode != "" {
+			return limit.PoolMode
+		}
+	}
+
+	return entity.LimitPoolShared
+}
+
+// Apply take otr from the limits consumed under the pool mode when is_reduce, or give it
+// back capped at the original limit. Empty mode use the pool mode of the tenor limit.
+// The limits passed in are not changed
+func Apply(limits []entity.Limit, tenor uint, mode entity.LimitPoolMode, otr decimal.Decimal, is_reduce bool) ([]entity.Limit, error) {
+	if mode == "" {
+		mode = PoolMode(limits, tenor)
+	}
+
+	tenor_limit_found := false
+	updatedLimits := make([]entity.Limit, len(limits))
+	for i, limit := range limits {
+		if !mode.Consumes(limit.Tenor, tenor) {
+			updatedLimits[i] = limit
+			continue
+		}
+
+		if limit.Tenor == tenor {
+			tenor_limit_found = true
+			if limit.FrozenAt.Valid && is_reduce {
+				return []entity.Limit{}, ErrFrozen
+			}
+		}
+		if (limit.Tenor == tenor || limit.Tenor == entity.GlobalLimitTenor) && is_reduce {
+			if limit.CurrentLimit.LessThan(otr) {
+				return []entity.Limit{}, ErrOverlimit
+			}
+		}
+
+		var newLimit decimal.Decimal
+		if is_reduce {
+			newLimit = limit.CurrentLimit.Sub(otr)
+			if newLimit.IsNegative() {
+				newLimit = decimal.Zero
+			}
+		} else {
+			newLimit = limit.CurrentLimit.Add(otr)
+			if newLimit.GreaterThan(limit.OriginalLimit) {
+				newLimit = limit.OriginalLimit
+			}
+		}
+
+		updatedLimits[i] = entity.Limit{
+			ID:            limit.ID,
+			UserID:        limit.UserID,
+			OriginalLimit: limit.OriginalLimit,
+			CurrentLimit:  newLimit,
+			Tenor:         limit.Tenor,
+			FrozenAt:      limit.FrozenAt,
+			PoolMode:      limit.PoolMode,
+			UpdatedAt:     time.Now(),
+		}
+	}
+	if !tenor_limit_found {
+		return []entity.Limit{}, ErrTenorNotFound
+	}
+
+	return updatedLimits, nil
+}
+
+// Amend give back the previous otr under the mode it was taken with, then take the amended
+// otr under the pool mode of the amended tenor. The pool mode the amended otr was taken
+// with is returned so it can be stored on the transaction
+func Amend(limits []entity.Limit, previous_tenor uint, previous_mode entity.LimitPoolMode, previous_otr decimal.Decimal,
+	tenor uint, otr decimal.Decimal) ([]entity.Limit, entity.LimitPoolMode, error) {
+	restoredLimits, err := Apply(limits, previous_tenor, previous_mode, previous_otr, false)
+	if err != nil {
+		return []entity.Limit{}, "", err
+	}
+
+	mode := PoolMode(restoredLimits, tenor)
+	updatedLimits, err := Apply(restoredLimits, tenor, mode, otr, true)
+	if err != nil {
+		return []entity.Limit{}, "", err
+	}
+
+	return updatedLimits, mode, nil
+}
+
+// Confirm give back a checkout hold under the mode it was held with, then take the contract
+// otr under the same mode so a pool mode change in between does not move the contract to
+// other limits. The released limits are returned with the updated ones for the movements
+func Confirm(limits []entity.Limit, held_tenor uint, held_mode entity.LimitPoolMode, held_otr decimal.Decimal,
+	tenor uint, otr decimal.Decimal) ([]entity.Limit, []entity.Limit, error) {
+	releasedLimits, err := Apply(limits, held_tenor, held_mode, held_otr, false)
+	if err != nil {
+		return []entity.Limit{}, []entity.Limit{}, err
+	}
+
+	updatedLimits, err := Apply(releasedLimits, tenor, held_mode, otr, true)
+	if err != nil {
+		return []entity.Limit{}, []entity.Limit{}, err
+	}
+
+	return releasedLimits, updatedLimits, nil
+}
+
+// TakeBack take otr again from the limits consumed under the pool mode when a paid contract
+// is reopened. It never fails, the contract is already running, so frozen limits are used
+// too and the limit is floored at zero
+func TakeBack(limits []entity.Limit, tenor uint, mode entity.LimitPoolMode, otr decimal.Decimal) []entity.Limit {
+	updatedLimits := make([]entity.Limit, len(limits))
+	for i, limit := range limits {
+		if !mode.Consumes(limit.Tenor, tenor) {
+			updatedLimits[i] = limit
+			continue
+		}
+
+		newLimit := limit.CurrentLimit.Sub(otr)
+		if newLimit.IsNegative() {
+			newLimit = decimal.Zero
+		}
+
+		updatedLimits[i] = entity.Limit{
+			ID:            limit.ID,
+			UserID:        limit.UserID,
+			OriginalLimit: limit.OriginalLimit,
+			CurrentLimit:  newLimit,
+			Tenor:         limit.Tenor,
+			FrozenAt:      limit.FrozenAt,
+			PoolMode:      limit.PoolMode,
+			UpdatedAt:     time.Now(),
+		}
+	}
+
+	return updatedLimits
+}
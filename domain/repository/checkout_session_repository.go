@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type CheckoutSessionRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.CheckoutSession, error)
+	FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.CheckoutSession, error)
+	FindExpired(ctx context.Context, as_of time.Time, limit int) (*[]entity.CheckoutSession, error)
+	CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, session *entity.CheckoutSession) error
+	UpdateStatusWithTransaction(ctx context.Context, tx *gorm.DB, session *entity.CheckoutSession, from entity.CheckoutStatus) error
+}
+
+type checkoutSessionRepository struct {
+	*gorm.DB
+}
+
+func NewCheckoutSessionRepository(db *gorm.DB) CheckoutSessionRepository {
+	return &checkoutSessionRepository{DB: db}
+}
+
+func (r *checkoutSessionRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.CheckoutSession, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var session entity.CheckoutSession
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Preload("User").Preload("Transaction").Preload("Merchant", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Find(&session); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &session, nil
+}
+
+func (r *checkoutSessionRepository) FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.CheckoutSession, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var sessions []entity.CheckoutSession
+
+	tx := r.DB.WithContext(ctx).Model(&entity.CheckoutSession{}).Where("user_id = ?", user_id).
+		Preload("Transaction").Preload("Merchant", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+
+	var allowedFields = map[string]string{
+		"status":  "checkout_sessions.status",
+		"created": "checkout_sessions.created_at",
+		"expires": "checkout_sessions.expires_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&sessions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
+// FindExpired return pending sessions past their expiry, oldest first, with the user to lock
+func (r *checkoutSessionRepository) FindExpired(ctx context.Context, as_of time.Time, limit int) (*[]entity.CheckoutSession, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var sessions []entity.CheckoutSession
+	if err := r.DB.WithContext(ctx).Preload("User").
+		Where("status = ? AND expires_at <= ?", entity.CheckoutPending, as_of).
+		Order("expires_at").Limit(limit).Find(&sessions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
+func (r *checkoutSessionRepository) CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.CheckoutSession{}).Where("user_id = ?", user_id)
+
+	var allowedFields = map[string]string{
+		"status":  "checkout_sessions.status",
+		"created": "checkout_sessions.created_at",
+		"expires": "checkout_sessions.expires_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *checkoutSessionRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, session *entity.CheckoutSession) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Omit("User", "Merchant", "Transaction").Create(session).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatusWithTransaction move the session only if it is still in from, so a session
+// confirmed and expired at the same time release or consume its hold once
+func (r *checkoutSessionRepository) UpdateStatusWithTransaction(ctx context.Context, tx *gorm.DB, session *entity.CheckoutSession, from entity.CheckoutStatus) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := tx.WithContext(ctx).Model(&entity.CheckoutSession{}).
+		Where("id = ? AND status = ?", session.ID, from).
+		Select("status", "transaction_id", "confirmed_at", "updated_at").
+		Updates(session)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errors.New("checkout session status already changed")
+	}
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type CreditScoreRepository interface {
+	FindLatestByUserID(ctx context.Context, user_id uint) (*entity.CreditScore, error)
+	FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.CreditScore, error)
+	CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64
+	Insert(ctx context.Context, score *entity.CreditScore) error
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, score *entity.CreditScore) error
+}
+
+type creditScoreRepository struct {
+	*gorm.DB
+}
+
+func NewCreditScoreRepository(db *gorm.DB) CreditScoreRepository {
+	return &creditScoreRepository{DB: db}
+}
+
+func (r *creditScoreRepository) FindLatestByUserID(ctx context.Context, user_id uint) (*entity.CreditScore, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var score entity.CreditScore
+	if result := r.DB.WithContext(ctx).Limit(1).Where("user_id = ?", user_id).
+		Preload("Factors").
+		Order("id desc").
+		Find(&score); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &score, nil
+}
+
+func (r *creditScoreRepository) FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.CreditScore, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var scores []entity.CreditScore
+	tx := r.DB.WithContext(ctx).Model(&entity.CreditScore{}).
+		Where("user_id = ?", user_id).
+		Preload("Factors")
+
+	var allowedFields = map[string]string{
+		"grade":   "credit_scores.grade",
+		"score":   "credit_scores.score",
+		"created": "credit_scores.created_at",
+	}
+
+	// Latest score first unless asked otherwise
+	if _, ok := allowedFields[query.OrderBy]; !ok {
+		tx = tx.Order("credit_scores.id desc")
+	}
+
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&scores).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &scores, nil
+}
+
+func (r *creditScoreRepository) CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.CreditScore{}).Where("user_id = ?", user_id)
+
+	var allowedFields = map[string]string{
+		"grade":   "credit_scores.grade",
+		"score":   "credit_scores.score",
+		"created": "credit_scores.created_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *creditScoreRepository) Insert(ctx context.Context, score *entity.CreditScore) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(score).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *creditScoreRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, score *entity.CreditScore) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(score).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
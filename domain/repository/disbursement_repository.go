@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var openDisbursementStatus = []entity.DisbursementStatus{entity.DisbursementPending, entity.DisbursementFailed}
+
+type DisbursementRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Disbursement, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Disbursement, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	OwedByMerchant(ctx context.Context) (*[]model.MerchantOwed, error)
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, disbursement *entity.Disbursement) error
+	UpdateStatus(ctx context.Context, disbursement *entity.Disbursement, from entity.DisbursementStatus) error
+	UpdateAmountWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint, amount decimal.Decimal) error
+	CancelWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error
+}
+
+type disbursementRepository struct {
+	*gorm.DB
+}
+
+func NewDisbursementRepository(db *gorm.DB) DisbursementRepository {
+	return &disbursementRepository{DB: db}
+}
+
+func (r *disbursementRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Disbursement, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var disbursement entity.Disbursement
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Preload("Transaction").Preload("Merchant", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Find(&disbursement); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &disbursement, nil
+}
+
+func (r *disbursementRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Disbursement, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var disbursements []entity.Disbursement
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Disbursement{}).
+		Preload("Transaction").Preload("Merchant", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"status":   "status",
+		"merchant": "merchant_id",
+		"due":      "due_date",
+		"updated":  "updated_at",
+		"created":  "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&disbursements).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &disbursements, nil
+}
+
+func (r *disbursementRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Disbursement{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"status":   "status",
+		"merchant": "merchant_id",
+		"due":      "due_date",
+		"updated":  "updated_at",
+		"created":  "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+// OwedByMerchant sum pending and failed disbursement of every merchant that still has one
+func (r *disbursementRepository) OwedByMerchant(ctx context.Context) (merchants *[]model.MerchantOwed, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.Disbursement{}).
+		Select("merchants.uuid, merchants.code, merchants.name, "+
+			"COUNT(disbursements.id) AS total_contract, "+
+			"COALESCE(SUM(disbursements.amount), 0) AS amount, "+
+			"MIN(disbursements.due_date) AS earliest_due_date").
+		Joins("JOIN merchants ON merchants.id = disbursements.merchant_id").
+		Where("disbursements.status IN ?", openDisbursementStatus).
+		Group("merchants.uuid, merchants.code, merchants.name").
+		Order("merchants.code").
+		Scan(&merchants).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
+func (r *disbursementRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, disbursement *entity.Disbursement) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Omit("Transaction", "Merchant").Create(disbursement).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatus only update when the disbursement is still on from, so two admin marking
+// the same disbursement can not both succeed
+func (r *disbursementRepository) UpdateStatus(ctx context.Context, disbursement *entity.Disbursement, from entity.DisbursementStatus) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := r.DB.WithContext(ctx).Model(&entity.Disbursement{}).
+		Where("id = ? AND status = ?", disbursement.ID, from).
+		Select("status", "reference", "notes", "sent_at", "updated_at").
+		Updates(disbursement)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errors.New("disbursement status already changed")
+	}
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	return nil
+}
+
+// UpdateAmountWithTransaction follow an amended on the road, sent disbursement is kept
+func (r *disbursementRepository) UpdateAmountWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint, amount decimal.Decimal) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.Disbursement{}).
+		Where("transaction_id = ? AND status IN ?", transaction_id, openDisbursementStatus).
+		Update("amount", amount).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// CancelWithTransaction cancel the disbursement of the contract that is not sent yet,
+// sent one is kept and has to be recovered from the merchant
+func (r *disbursementRepository) CancelWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.Disbursement{}).
+		Where("transaction_id = ? AND status IN ?", transaction_id, openDisbursementStatus).
+		Update("status", entity.DisbursementCanceled).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
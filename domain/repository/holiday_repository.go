@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type HolidayRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Holiday, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Holiday, error)
+	FindAllBetween(ctx context.Context, from time.Time, to time.Time) (*[]entity.Holiday, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	Insert(ctx context.Context, holiday *entity.Holiday) error
+	Update(ctx context.Context, holiday *entity.Holiday) error
+	Delete(ctx context.Context, holiday *entity.Holiday) error
+	DateExist(ctx context.Context, holiday *entity.Holiday) bool
+}
+
+type holidayRepository struct {
+	*gorm.DB
+}
+
+func NewHolidayRepository(db *gorm.DB) HolidayRepository {
+	return &holidayRepository{DB: db}
+}
+
+func (r *holidayRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Holiday, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var holiday entity.Holiday
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&holiday); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &holiday, nil
+}
+
+func (r *holidayRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Holiday, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var holidays []entity.Holiday
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Holiday{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"date":    "date",
+		"name":    "name",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&holidays).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &holidays, nil
+}
+
+// FindAllBetween return holidays from and to the given date, both inclusive
+func (r *holidayRepository) FindAllBetween(ctx context.Context, from time.Time, to time.Time) (*[]entity.Holiday, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var holidays []entity.Holiday
+	if err := r.DB.WithContext(ctx).Where("date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date asc").Find(&holidays).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &holidays, nil
+}
+
+func (r *holidayRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Holiday{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"date":    "date",
+		"name":    "name",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *holidayRepository) Insert(ctx context.Context, holiday *entity.Holiday) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(holiday).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *holidayRepository) Update(ctx context.Context, holiday *entity.Holiday) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(holiday).Where("id = ?", holiday.ID).
+		Select("date", "name").
+		Updates(holiday).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *holidayRepository) Delete(ctx context.Context, holiday *entity.Holiday) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(holiday).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *holidayRepository) DateExist(ctx context.Context, holiday *entity.Holiday) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Holiday{}).Where("date = ?", holiday.Date.Format("2006-01-02"))
+
+	if holiday.ID != 0 {
+		tx = tx.Not("id = ?", holiday.ID)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
@@ -17,6 +18,8 @@ type InstallmentRepository interface {
 	FindAll(ctx context.Context, query *model.QueryGet) (installments *[]entity.TransactionInstallment, err error)
 	FindAllByTransactionID(ctx context.Context, query *model.QueryGet, transaction_id uint) (installments *[]entity.TransactionInstallment, err error)
 	FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (installments *[]entity.TransactionInstallment, err error)
+	FindAllUnpaidByTransactionID(ctx context.Context, transaction_id uint) (installments *[]entity.TransactionInstallment, err error)
+	FindAllHistoryByUserID(ctx context.Context, user_id uint) (installments *[]entity.TransactionInstallment, err error)
 	Count(ctx context.Context, query *model.QueryGet) (total int64)
 	CountByTransactionID(ctx context.Context, query *model.QueryGet, transaction_id uint) (total int64)
 	CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (total int64)
@@ -24,8 +27,12 @@ type InstallmentRepository interface {
 	BulkInsertWithTransaction(ctx context.Context, tx *gorm.DB, installments []entity.TransactionInstallment) error
 	InsertWithTransaction(ctx context.Context, tx *gorm.DB, installment *entity.TransactionInstallment) error
 	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, installment *entity.TransactionInstallment) error
+	UpdatePaymentWithTransaction(ctx context.Context, tx *gorm.DB, installment *entity.TransactionInstallment) error
 	DeleteWithTransaction(ctx context.Context, tx *gorm.DB, installment *entity.TransactionInstallment) error
 	CancelWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error
+	FindAllOverdue(ctx context.Context) (*[]entity.TransactionInstallment, error)
+	MarkOverdue(ctx context.Context, as_of time.Time) (total int64, err error)
+	DeleteUnpaidWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error
 }
 
 type installmentRepository struct {
@@ -45,7 +52,7 @@ func (r *installmentRepository) FindByUUID(ctx context.Context, uuid uuid.UUID)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
 	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
-		Preload("Transaction").Preload("Transaction.User").Preload("Payments").
+		Preload("Transaction").Preload("Transaction.User").Preload("Payments").Preload("Penalties").
 		Find(&transaction); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -60,7 +67,7 @@ func (r *installmentRepository) FindByID(ctx context.Context, id uint) (transact
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
 	if result := r.DB.WithContext(ctx).Limit(1).Where("id = ?", id).
-		Preload("Transaction").Preload("Transaction.User").Preload("Payments").
+		Preload("Transaction").Preload("Transaction.User").Preload("Payments").Preload("Penalties").
 		Find(&transaction); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -154,6 +161,42 @@ func (r *installmentRepository) FindAllByUserID(ctx context.Context, query *mode
 	return
 }
 
+func (r *installmentRepository) FindAllUnpaidByTransactionID(ctx context.Context, transaction_id uint) (transactions *[]entity.TransactionInstallment, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.TransactionInstallment{}).
+		Where("transaction_id = ? AND payment_status IN ?", transaction_id, []string{"pending", "partial", "overdue"}).
+		Preload("Penalties").
+		Order("installment_number asc").
+		Find(&transactions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
+// FindAllHistoryByUserID return every installment of the user without pagination, used as
+// repayment history on credit scoring
+func (r *installmentRepository) FindAllHistoryByUserID(ctx context.Context, user_id uint) (installments *[]entity.TransactionInstallment, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.TransactionInstallment{}).
+		Joins("JOIN transactions on transactions.id = transaction_installments.transaction_id").
+		Where("transactions.user_id = ? AND transactions.status <> ?", user_id, entity.TransactionCanceled).
+		Order("transaction_installments.due_date asc").
+		Find(&installments).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
 func (r *installmentRepository) Count(ctx context.Context, query *model.QueryGet) (total int64) {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -284,6 +327,27 @@ func (r *installmentRepository) UpdateWithTransaction(ctx context.Context, tx *g
 	return nil
 }
 
+// UpdatePaymentWithTransaction write payment columns even when they are zero,
+// used when a reversal takes paid amount back
+func (r *installmentRepository) UpdatePaymentWithTransaction(ctx context.Context, tx *gorm.DB, installment *entity.TransactionInstallment) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.TransactionInstallment{}).Where("id = ?", installment.ID).
+		Select("amount_paid", "payment_status", "paid_at").
+		Updates(entity.TransactionInstallment{
+			AmountPaid:    installment.AmountPaid,
+			PaymentStatus: installment.PaymentStatus,
+			PaidAt:        installment.PaidAt,
+		}).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
 func (r *installmentRepository) CancelWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -299,6 +363,69 @@ func (r *installmentRepository) CancelWithTransaction(ctx context.Context, tx *g
 	return nil
 }
 
+func (r *installmentRepository) FindAllOverdue(ctx context.Context) (installments *[]entity.TransactionInstallment, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.TransactionInstallment{}).
+		Where("payment_status = ?", entity.PaymentStatusOverdue).
+		Where("transaction_id IN (?)", r.repayingTransactionIDs(ctx)).
+		Preload("Penalties").
+		Find(&installments).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
+// MarkOverdue set every unpaid installment of a repaying contract due before as_of to
+// overdue and refresh its days past due, running it twice on the same day give the same result
+func (r *installmentRepository) MarkOverdue(ctx context.Context, as_of time.Time) (total int64, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	as_of_date := as_of.Format(time.DateOnly)
+	result := r.DB.WithContext(ctx).Model(&entity.TransactionInstallment{}).
+		Where("payment_status IN ? AND due_date < ?", []string{"pending", "partial", "overdue"}, as_of_date).
+		Where("transaction_id IN (?)", r.repayingTransactionIDs(ctx)).
+		Updates(map[string]interface{}{
+			"payment_status": entity.PaymentStatusOverdue,
+			"days_past_due":  gorm.Expr("DATEDIFF(?, due_date)", as_of_date),
+		})
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// repayingTransactionIDs is the subquery of contracts still being repaid, written off or
+// cancelled contract is not marked overdue nor charged penalty anymore
+func (r *installmentRepository) repayingTransactionIDs(ctx context.Context) *gorm.DB {
+	return r.DB.WithContext(ctx).Model(&entity.Transaction{}).
+		Select("id").
+		Where("status IN ?", entity.RepayingStatuses)
+}
+
+func (r *installmentRepository) DeleteUnpaidWithTransaction(ctx context.Context, tx *gorm.DB, transaction_id uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Where("transaction_id = ? AND payment_status IN ?", transaction_id, []string{"pending", "overdue"}).
+		Where("amount_paid = 0").
+		Delete(&entity.TransactionInstallment{}).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
 func (r *installmentRepository) DeleteWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.TransactionInstallment) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type LedgerRepository interface {
+	InsertEntryWithTransaction(ctx context.Context, tx *gorm.DB, entry *entity.JournalEntry) error
+	TrialBalance(ctx context.Context, as_of time.Time) (*[]model.TrialBalanceAccount, error)
+}
+
+type ledgerRepository struct {
+	*gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{DB: db}
+}
+
+// InsertEntryWithTransaction insert entry with its postings, unbalanced entry is refused
+func (r *ledgerRepository) InsertEntryWithTransaction(ctx context.Context, tx *gorm.DB, entry *entity.JournalEntry) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := ledger.Validate(entry); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	if err := tx.WithContext(ctx).Omit("Postings.Account").Create(entry).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// TrialBalance sum debit and credit of every account for entries posted before as_of
+func (r *ledgerRepository) TrialBalance(ctx context.Context, as_of time.Time) (accounts *[]model.TrialBalanceAccount, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.LedgerAccount{}).
+		Select("ledger_accounts.code, ledger_accounts.name, ledger_accounts.type, "+
+			"COALESCE(SUM(CASE WHEN journal_entries.id IS NULL THEN 0 ELSE journal_postings.debit END), 0) AS debit, "+
+			"COALESCE(SUM(CASE WHEN journal_entries.id IS NULL THEN 0 ELSE journal_postings.credit END), 0) AS credit").
+		Joins("LEFT JOIN journal_postings ON journal_postings.account_code = ledger_accounts.code").
+		Joins("LEFT JOIN journal_entries ON journal_entries.id = journal_postings.journal_entry_id AND journal_entries.posted_at < ?", as_of).
+		Group("ledger_accounts.code, ledger_accounts.name, ledger_accounts.type").
+		Order("ledger_accounts.code").
+		Scan(&accounts).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type LimitPolicyRepository interface {
+	BeginTransaction(ctx context.Context) *gorm.DB
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.LimitPolicy, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.LimitPolicy, error)
+	FindAllActive(ctx context.Context) (*[]entity.LimitPolicy, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	Insert(ctx context.Context, policy *entity.LimitPolicy) error
+	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, policy *entity.LimitPolicy) error
+	ReplaceItemsWithTransaction(ctx context.Context, tx *gorm.DB, policy *entity.LimitPolicy) error
+	Delete(ctx context.Context, policy *entity.LimitPolicy) error
+	NameExist(ctx context.Context, policy *entity.LimitPolicy) bool
+}
+
+type limitPolicyRepository struct {
+	*gorm.DB
+}
+
+func NewLimitPolicyRepository(db *gorm.DB) LimitPolicyRepository {
+	return &limitPolicyRepository{DB: db}
+}
+
+func (r *limitPolicyRepository) BeginTransaction(ctx context.Context) *gorm.DB {
+	return r.DB.Begin()
+}
+
+func (r *limitPolicyRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.LimitPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policy entity.LimitPolicy
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tenor asc")
+		}).
+		Find(&policy); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &policy, nil
+}
+
+func (r *limitPolicyRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.LimitPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policies []entity.LimitPolicy
+
+	tx := r.DB.WithContext(ctx).Model(&entity.LimitPolicy{}).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tenor asc")
+		})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"name":     "name",
+		"priority": "priority",
+		"active":   "is_active",
+		"updated":  "updated_at",
+		"created":  "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&policies).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &policies, nil
+}
+
+// FindAllActive return active policies with the one to pick first on top
+func (r *limitPolicyRepository) FindAllActive(ctx context.Context) (*[]entity.LimitPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policies []entity.LimitPolicy
+	if err := r.DB.WithContext(ctx).Where("is_active = ?", true).
+		Preload("Items").
+		Order("priority desc").Order("id asc").
+		Find(&policies).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &policies, nil
+}
+
+func (r *limitPolicyRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.LimitPolicy{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"name":     "name",
+		"priority": "priority",
+		"active":   "is_active",
+		"updated":  "updated_at",
+		"created":  "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *limitPolicyRepository) Insert(ctx context.Context, policy *entity.LimitPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *limitPolicyRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, policy *entity.LimitPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	// Select all column so zero value (no condition, inactive) is saved too
+	if err := tx.WithContext(ctx).Model(policy).Where("id = ?", policy.ID).
+		Select("name", "description", "min_salary", "max_salary", "min_age", "max_age", "priority", "is_active", "pool_mode", "global_cap").
+		Updates(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// ReplaceItemsWithTransaction delete every tenor of the policy and insert the new one
+func (r *limitPolicyRepository) ReplaceItemsWithTransaction(ctx context.Context, tx *gorm.DB, policy *entity.LimitPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Where("limit_policy_id = ?", policy.ID).
+		Delete(&entity.LimitPolicyItem{}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	for i := range policy.Items {
+		policy.Items[i].ID = 0
+		policy.Items[i].LimitPolicyID = policy.ID
+	}
+
+	if err := tx.WithContext(ctx).Create(&policy.Items).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *limitPolicyRepository) Delete(ctx context.Context, policy *entity.LimitPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *limitPolicyRepository) NameExist(ctx context.Context, policy *entity.LimitPolicy) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.LimitPolicy{}).Where("name = ?", policy.Name)
+
+	if policy.ID != 0 {
+		tx = tx.Not("id = ?", policy.ID)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
@@ -16,14 +16,23 @@ type LimitRepository interface {
 	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Limit, error)
 	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Limit, error)
 	FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.Limit, error)
+	FindByUserIDAndTenorUnscoped(ctx context.Context, user_id uint, tenor uint) (*entity.Limit, error)
 	Count(ctx context.Context, query *model.QueryGet) int64
+	CountOpenUsage(ctx context.Context, user_id uint, tenor uint) (int64, error)
 	CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64
 	CountUnscoped(ctx context.Context, query *model.QueryGet) int64
+	FindAllChangesByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.LimitChange, error)
+	CountChangesByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64
 	Insert(ctx context.Context, limit *entity.Limit) error
 	BulkInsertWithTransaction(ctx context.Context, tx *gorm.DB, limits []entity.Limit) error
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error
+	InsertChangeWithTransaction(ctx context.Context, tx *gorm.DB, change *entity.LimitChange) error
+	BulkInsertChangeWithTransaction(ctx context.Context, tx *gorm.DB, changes []entity.LimitChange) error
 	Update(ctx context.Context, limit *entity.Limit) error
 	BulkUpdateWithTransaction(ctx context.Context, tx *gorm.DB, limits []entity.Limit) error
+	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error
 	Delete(ctx context.Context, limit *entity.Limit) error
+	DeleteWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error
 }
 
 type limitRepository struct {
@@ -127,6 +136,50 @@ func (r *limitRepository) FindAllByUserID(ctx context.Context, query *model.Quer
 	return &limits, nil
 }
 
+// FindByUserIDAndTenorUnscoped also find removed limit, so it can be restored instead of
+// breaking the user and tenor unique index
+func (r *limitRepository) FindByUserIDAndTenorUnscoped(ctx context.Context, user_id uint, tenor uint) (*entity.Limit, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var limit entity.Limit
+	if result := r.DB.WithContext(ctx).Unscoped().Limit(1).
+		Where("user_id = ? AND tenor = ?", user_id, tenor).
+		Find(&limit); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &limit, nil
+}
+
+// CountOpenUsage count open contracts and pending checkouts of the user on the tenor, the
+// limit of that tenor must stay while they can still give their otr back
+func (r *limitRepository) CountOpenUsage(ctx context.Context, user_id uint, tenor uint) (int64, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var transactions, checkouts int64
+	if err := r.DB.WithContext(ctx).Model(&entity.Transaction{}).
+		Where("user_id = ? AND tenor = ? AND status IN ?", user_id, tenor, entity.LimitHoldingStatuses).
+		Count(&transactions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	if err := r.DB.WithContext(ctx).Model(&entity.CheckoutSession{}).
+		Where("user_id = ? AND tenor = ? AND status = ?", user_id, tenor, entity.CheckoutPending).
+		Count(&checkouts).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	return transactions + checkouts, nil
+}
+
 func (r *limitRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -235,6 +288,22 @@ func (r *limitRepository) Update(ctx context.Context, limit *entity.Limit) error
 	return nil
 }
 
+// UpdateWithTransaction write every limit column including zero value, removed limit is
+// restored when its deleted at is cleared
+func (r *limitRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Unscoped().Model(limit).
+		Select("original_limit", "current_limit", "frozen_at", "limit_policy_id", "pool_mode", "deleted_at", "updated_at").
+		Updates(limit).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	return nil
+}
+
 func (r *limitRepository) BulkUpdateWithTransaction(ctx context.Context, tx *gorm.DB, limits []entity.Limit) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -272,6 +341,19 @@ func (r *limitRepository) Delete(ctx context.Context, limit *entity.Limit) error
 	return nil
 }
 
+func (r *limitRepository) DeleteWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Delete(limit).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
 func (r *limitRepository) BeginTransaction(ctx context.Context) *gorm.DB {
 	return r.DB.Begin()
 }
@@ -287,3 +369,103 @@ func (r *limitRepository) BulkInsertWithTransaction(ctx context.Context, tx *gor
 	}
 	return nil
 }
+
+func (r *limitRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, limit *entity.Limit) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(limit).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	return nil
+}
+
+func (r *limitRepository) InsertChangeWithTransaction(ctx context.Context, tx *gorm.DB, change *entity.LimitChange) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(change).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	return nil
+}
+
+func (r *limitRepository) BulkInsertChangeWithTransaction(ctx context.Context, tx *gorm.DB, changes []entity.LimitChange) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(changes).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	return nil
+}
+
+func (r *limitRepository) FindAllChangesByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (*[]entity.LimitChange, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var changes []entity.LimitChange
+	tx := r.DB.WithContext(ctx).Model(&entity.LimitChange{}).
+		Where("user_id = ?", user_id)
+
+	var allowedFields = map[string]string{
+		"tenor":       "limit_changes.tenor",
+		"action":      "limit_changes.action",
+		"transaction": "limit_changes.transaction_uuid",
+		"created":     "limit_changes.created_at",
+	}
+
+	// Latest movement first unless asked otherwise
+	if _, ok := allowedFields[query.OrderBy]; !ok {
+		tx = tx.Order("limit_changes.id desc")
+	}
+
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&changes).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &changes, nil
+}
+
+func (r *limitRepository) CountChangesByUserID(ctx context.Context, query *model.QueryGet, user_id uint) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.LimitChange{}).Where("user_id = ?", user_id)
+
+	var allowedFields = map[string]string{
+		"tenor":       "limit_changes.tenor",
+		"action":      "limit_changes.action",
+		"transaction": "limit_changes.transaction_uuid",
+		"created":     "limit_changes.created_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
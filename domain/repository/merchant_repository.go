@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type MerchantRepository interface {
+	FindByID(ctx context.Context, id uint) (*entity.Merchant, error)
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Merchant, error)
+	FindByCode(ctx context.Context, code string) (*entity.Merchant, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Merchant, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	Insert(ctx context.Context, merchant *entity.Merchant) error
+	Update(ctx context.Context, merchant *entity.Merchant) error
+	Delete(ctx context.Context, merchant *entity.Merchant) error
+	CodeExist(ctx context.Context, merchant *entity.Merchant) bool
+}
+
+type merchantRepository struct {
+	*gorm.DB
+}
+
+func NewMerchantRepository(db *gorm.DB) MerchantRepository {
+	return &merchantRepository{DB: db}
+}
+
+func (r *merchantRepository) FindByID(ctx context.Context, id uint) (*entity.Merchant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var merchant entity.Merchant
+	if result := r.DB.WithContext(ctx).Limit(1).Where("id = ?", id).
+		Find(&merchant); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &merchant, nil
+}
+
+func (r *merchantRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Merchant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var merchant entity.Merchant
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&merchant); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &merchant, nil
+}
+
+func (r *merchantRepository) FindByCode(ctx context.Context, code string) (*entity.Merchant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var merchant entity.Merchant
+	if result := r.DB.WithContext(ctx).Limit(1).Where("code = ?", code).
+		Find(&merchant); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &merchant, nil
+}
+
+func (r *merchantRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Merchant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var merchants []entity.Merchant
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Merchant{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"code":    "code",
+		"name":    "name",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&merchants).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &merchants, nil
+}
+
+func (r *merchantRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Merchant{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"code":    "code",
+		"name":    "name",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *merchantRepository) Insert(ctx context.Context, merchant *entity.Merchant) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(merchant).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Merchant) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(merchant).Where("id = ?", merchant.ID).
+		Select(
+			"code", "name", "bank_name", "bank_account_number", "bank_account_name", "settlement_days",
+			"is_active",
+		).
+		Updates(merchant).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) Delete(ctx context.Context, merchant *entity.Merchant) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(merchant).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// CodeExist include deleted merchant, code is unique in the table
+func (r *merchantRepository) CodeExist(ctx context.Context, merchant *entity.Merchant) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Unscoped().Model(&entity.Merchant{}).Where("code = ?", merchant.Code)
+
+	if merchant.ID != 0 {
+		tx = tx.Not("id = ?", merchant.ID)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
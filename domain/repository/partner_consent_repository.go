@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PartnerConsentRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PartnerConsent, error)
+	FindAllByUserID(ctx context.Context, user_id uint) (*[]entity.PartnerConsent, error)
+	HasConsent(ctx context.Context, merchant_id uint, user_id uint) bool
+	Grant(ctx context.Context, consent *entity.PartnerConsent) error
+	Revoke(ctx context.Context, consent *entity.PartnerConsent) error
+}
+
+type partnerConsentRepository struct {
+	*gorm.DB
+}
+
+func NewPartnerConsentRepository(db *gorm.DB) PartnerConsentRepository {
+	return &partnerConsentRepository{DB: db}
+}
+
+func (r *partnerConsentRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PartnerConsent, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var consent entity.PartnerConsent
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&consent); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &consent, nil
+}
+
+func (r *partnerConsentRepository) FindAllByUserID(ctx context.Context, user_id uint) (*[]entity.PartnerConsent, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var consents []entity.PartnerConsent
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", user_id).
+		Preload("Merchant", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).Order("created_at desc").
+		Find(&consents).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &consents, nil
+}
+
+func (r *partnerConsentRepository) HasConsent(ctx context.Context, merchant_id uint, user_id uint) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+	if err := r.DB.WithContext(ctx).Model(&entity.PartnerConsent{}).
+		Where("merchant_id = ? AND user_id = ? AND revoked_at IS NULL", merchant_id, user_id).
+		Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
+
+// Grant insert the consent or clear the revocation of the existing one
+func (r *partnerConsentRepository) Grant(ctx context.Context, consent *entity.PartnerConsent) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var existing entity.PartnerConsent
+	result := r.DB.WithContext(ctx).Limit(1).
+		Where("merchant_id = ? AND user_id = ?", consent.MerchantID, consent.UserID).Find(&existing)
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if err := r.DB.WithContext(ctx).Omit("Merchant").Create(consent).Error; err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+			return err
+		}
+		return nil
+	}
+
+	if err := r.DB.WithContext(ctx).Model(&entity.PartnerConsent{}).Where("id = ?", existing.ID).
+		Update("revoked_at", nil).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	consent.ID = existing.ID
+	consent.UUID = existing.UUID
+
+	return nil
+}
+
+func (r *partnerConsentRepository) Revoke(ctx context.Context, consent *entity.PartnerConsent) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	now := time.Now()
+	if err := r.DB.WithContext(ctx).Model(&entity.PartnerConsent{}).Where("id = ?", consent.ID).
+		Update("revoked_at", now).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	consent.RevokedAt = &now
+
+	return nil
+}
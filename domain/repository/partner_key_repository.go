@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PartnerKeyRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PartnerKey, error)
+	FindByKeyID(ctx context.Context, key_id string) (*entity.PartnerKey, error)
+	FindAllByMerchantID(ctx context.Context, merchant_id uint) (*[]entity.PartnerKey, error)
+	Insert(ctx context.Context, key *entity.PartnerKey) error
+	Revoke(ctx context.Context, key *entity.PartnerKey) error
+}
+
+type partnerKeyRepository struct {
+	*gorm.DB
+}
+
+func NewPartnerKeyRepository(db *gorm.DB) PartnerKeyRepository {
+	return &partnerKeyRepository{DB: db}
+}
+
+func (r *partnerKeyRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PartnerKey, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var key entity.PartnerKey
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&key); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &key, nil
+}
+
+// FindByKeyID load the key with its merchant, deleted merchant is not loaded
+func (r *partnerKeyRepository) FindByKeyID(ctx context.Context, key_id string) (*entity.PartnerKey, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var key entity.PartnerKey
+	if result := r.DB.WithContext(ctx).Limit(1).Where("key_id = ?", key_id).Preload("Merchant").
+		Find(&key); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &key, nil
+}
+
+func (r *partnerKeyRepository) FindAllByMerchantID(ctx context.Context, merchant_id uint) (*[]entity.PartnerKey, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var keys []entity.PartnerKey
+	if err := r.DB.WithContext(ctx).Where("merchant_id = ?", merchant_id).Order("created_at desc").
+		Find(&keys).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &keys, nil
+}
+
+func (r *partnerKeyRepository) Insert(ctx context.Context, key *entity.PartnerKey) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Omit("Merchant").Create(key).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *partnerKeyRepository) Revoke(ctx context.Context, key *entity.PartnerKey) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	now := time.Now()
+	if err := r.DB.WithContext(ctx).Model(&entity.PartnerKey{}).Where("id = ?", key.ID).
+		Update("revoked_at", now).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+	key.RevokedAt = &now
+
+	return nil
+}
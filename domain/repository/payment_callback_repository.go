@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PaymentCallbackRepository interface {
+	FindByProviderTransactionID(ctx context.Context, provider string, provider_transaction_id string) (*entity.PaymentCallback, error)
+	Insert(ctx context.Context, callback *entity.PaymentCallback) error
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, callback *entity.PaymentCallback) error
+}
+
+type paymentCallbackRepository struct {
+	*gorm.DB
+}
+
+func NewPaymentCallbackRepository(db *gorm.DB) PaymentCallbackRepository {
+	return &paymentCallbackRepository{DB: db}
+}
+
+func (r *paymentCallbackRepository) FindByProviderTransactionID(ctx context.Context, provider string, provider_transaction_id string) (callback *entity.PaymentCallback, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if result := r.DB.WithContext(ctx).Limit(1).
+		Where("provider = ? AND provider_transaction_id = ?", provider, provider_transaction_id).
+		Find(&callback); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return
+}
+
+func (r *paymentCallbackRepository) Insert(ctx context.Context, callback *entity.PaymentCallback) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(callback).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentCallbackRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, callback *entity.PaymentCallback) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(callback).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
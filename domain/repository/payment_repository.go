@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -23,6 +25,12 @@ type PaymentRepository interface {
 	BulkInsertWithTransaction(ctx context.Context, tx *gorm.DB, payments []entity.Payment) error
 	InsertWithTransaction(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error
 	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error
+	BulkInsertAllocationWithTransaction(ctx context.Context, tx *gorm.DB, allocations []entity.PaymentAllocation) error
+	InsertCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit *entity.CustomerCredit) error
+	DeleteCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit *entity.CustomerCredit) error
+	FindCreditByUUID(ctx context.Context, uuid uuid.UUID) (credit *entity.CustomerCredit, err error)
+	UseCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit_id uint, amount decimal.Decimal) error
+	ReleaseCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit_id uint, amount decimal.Decimal) error
 	DeleteWithTransaction(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error
 }
 
@@ -44,6 +52,7 @@ func (r *paymentRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (tra
 
 	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
 		Preload("Transaction").Preload("Transaction.User").Preload("Installment").
+		Preload("Allocations").Preload("Allocations.Installment").Preload("Credit").
 		Find(&transaction); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -255,6 +264,100 @@ func (r *paymentRepository) BulkInsertWithTransaction(ctx context.Context, tx *g
 	return tx.WithContext(ctx).Create(transactions).Error
 }
 
+func (r *paymentRepository) BulkInsertAllocationWithTransaction(ctx context.Context, tx *gorm.DB, allocations []entity.PaymentAllocation) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(allocations).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentRepository) InsertCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit *entity.CustomerCredit) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(credit).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentRepository) DeleteCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit *entity.CustomerCredit) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Where("id = ?", credit.ID).Delete(credit).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentRepository) FindCreditByUUID(ctx context.Context, uuid uuid.UUID) (credit *entity.CustomerCredit, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&credit); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return
+}
+
+// UseCreditWithTransaction add amount to the used part of the credit only if that much is
+// still available, so the same credit drawn twice at once is only spent once
+func (r *paymentRepository) UseCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit_id uint, amount decimal.Decimal) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := tx.WithContext(ctx).Model(&entity.CustomerCredit{}).
+		Where("id = ? AND amount - amount_used >= ?", credit_id, amount).
+		Update("amount_used", gorm.Expr("amount_used + ?", amount))
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errors.New("customer credit not available")
+	}
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	return nil
+}
+
+// ReleaseCreditWithTransaction give back amount drawn from the credit by a reversed payment
+func (r *paymentRepository) ReleaseCreditWithTransaction(ctx context.Context, tx *gorm.DB, credit_id uint, amount decimal.Decimal) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := tx.WithContext(ctx).Model(&entity.CustomerCredit{}).
+		Where("id = ? AND amount_used >= ?", credit_id, amount).
+		Update("amount_used", gorm.Expr("amount_used - ?", amount))
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errors.New("customer credit used amount already changed")
+	}
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	return nil
+}
+
 func (r *paymentRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Payment) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
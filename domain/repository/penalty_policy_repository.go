@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PenaltyPolicyRepository interface {
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PenaltyPolicy, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.PenaltyPolicy, error)
+	FindAllActive(ctx context.Context) (*[]entity.PenaltyPolicy, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	Insert(ctx context.Context, policy *entity.PenaltyPolicy) error
+	Update(ctx context.Context, policy *entity.PenaltyPolicy) error
+	Delete(ctx context.Context, policy *entity.PenaltyPolicy) error
+	NameExist(ctx context.Context, policy *entity.PenaltyPolicy) bool
+}
+
+type penaltyPolicyRepository struct {
+	*gorm.DB
+}
+
+func NewPenaltyPolicyRepository(db *gorm.DB) PenaltyPolicyRepository {
+	return &penaltyPolicyRepository{DB: db}
+}
+
+func (r *penaltyPolicyRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.PenaltyPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policy entity.PenaltyPolicy
+	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
+		Find(&policy); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &policy, nil
+}
+
+func (r *penaltyPolicyRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.PenaltyPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policies []entity.PenaltyPolicy
+
+	tx := r.DB.WithContext(ctx).Model(&entity.PenaltyPolicy{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"name":    "name",
+		"type":    "type",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&policies).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &policies, nil
+}
+
+func (r *penaltyPolicyRepository) FindAllActive(ctx context.Context) (*[]entity.PenaltyPolicy, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var policies []entity.PenaltyPolicy
+	if err := r.DB.WithContext(ctx).Where("is_active = ?", true).Order("id asc").
+		Find(&policies).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &policies, nil
+}
+
+func (r *penaltyPolicyRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.PenaltyPolicy{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"name":    "name",
+		"type":    "type",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *penaltyPolicyRepository) Insert(ctx context.Context, policy *entity.PenaltyPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *penaltyPolicyRepository) Update(ctx context.Context, policy *entity.PenaltyPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	// Select all column so zero value (inactive, no cap, no grace) is saved too
+	if err := r.DB.WithContext(ctx).Model(policy).Where("id = ?", policy.ID).
+		Select("name", "type", "rate", "amount", "cap", "grace_days", "is_active").
+		Updates(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *penaltyPolicyRepository) Delete(ctx context.Context, policy *entity.PenaltyPolicy) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(policy).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *penaltyPolicyRepository) NameExist(ctx context.Context, policy *entity.PenaltyPolicy) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.PenaltyPolicy{}).Where("name = ?", policy.Name)
+
+	if policy.ID != 0 {
+		tx = tx.Not("id = ?", policy.ID)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PenaltyRepository interface {
+	BeginTransaction(ctx context.Context) *gorm.DB
+	FindAllByInstallmentID(ctx context.Context, installment_id uint) (*[]entity.InstallmentPenalty, error)
+	FindAllUnpaidByInstallmentID(ctx context.Context, installment_id uint) (*[]entity.InstallmentPenalty, error)
+	InsertWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error
+	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error
+	UpdatePaymentWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error
+}
+
+type penaltyRepository struct {
+	*gorm.DB
+}
+
+func NewPenaltyRepository(db *gorm.DB) PenaltyRepository {
+	return &penaltyRepository{DB: db}
+}
+
+func (r *penaltyRepository) BeginTransaction(ctx context.Context) *gorm.DB {
+	return r.DB.Begin()
+}
+
+func (r *penaltyRepository) FindAllByInstallmentID(ctx context.Context, installment_id uint) (penalties *[]entity.InstallmentPenalty, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Where("installment_id = ?", installment_id).
+		Order("accrual_date asc").
+		Find(&penalties).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
+func (r *penaltyRepository) FindAllUnpaidByInstallmentID(ctx context.Context, installment_id uint) (penalties *[]entity.InstallmentPenalty, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Where("installment_id = ? AND amount_paid < amount", installment_id).
+		Order("accrual_date asc").
+		Find(&penalties).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
+// InsertWithTransaction skip the row when the policy already accrued on the same date,
+// so running accrual twice a day does not double charge
+func (r *penaltyRepository) InsertWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(penalty).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *penaltyRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Where("id = ?", penalty.ID).Updates(penalty).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePaymentWithTransaction write paid columns even when they are zero
+func (r *penaltyRepository) UpdatePaymentWithTransaction(ctx context.Context, tx *gorm.DB, penalty *entity.InstallmentPenalty) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.InstallmentPenalty{}).Where("id = ?", penalty.ID).
+		Select("amount_paid", "paid_at").
+		Updates(entity.InstallmentPenalty{AmountPaid: penalty.AmountPaid, PaidAt: penalty.PaidAt}).
+		Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
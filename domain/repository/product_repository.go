@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type ProductRepository interface {
+	BeginTransaction(ctx context.Context) *gorm.DB
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Product, error)
+	FindByCode(ctx context.Context, code string) (*entity.Product, error)
+	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Product, error)
+	Count(ctx context.Context, query *model.QueryGet) int64
+	Insert(ctx context.Context, product *entity.Product) error
+	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, product *entity.Product) error
+	ReplaceRulesWithTransaction(ctx context.Context, tx *gorm.DB, product *entity.Product) error
+	Delete(ctx context.Context, product *entity.Product) error
+	CodeExist(ctx context.Context, product *entity.Product) bool
+}
+
+type productRepository struct {
+	*gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{DB: db}
+}
+
+func (r *productRepository) BeginTransaction(ctx context.Context) *gorm.DB {
+	return r.DB.Begin()
+}
+
+func (r *productRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Product, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var product entity.Product
+	if result := r.preloadRules(r.DB.WithContext(ctx)).Limit(1).Where("uuid = ?", uuid).
+		Find(&product); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &product, nil
+}
+
+func (r *productRepository) FindByCode(ctx context.Context, code string) (*entity.Product, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var product entity.Product
+	if result := r.preloadRules(r.DB.WithContext(ctx)).Limit(1).Where("code = ?", code).
+		Find(&product); result.Error != nil || result.RowsAffected == 0 {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	return &product, nil
+}
+
+func (r *productRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Product, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var products []entity.Product
+
+	tx := r.preloadRules(r.DB.WithContext(ctx).Model(&entity.Product{}))
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"code":    "code",
+		"name":    "name",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&products).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &products, nil
+}
+
+func (r *productRepository) Count(ctx context.Context, query *model.QueryGet) int64 {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Product{})
+
+	// map value for parsing user query input
+	var allowedFields = map[string]string{
+		"code":    "code",
+		"name":    "name",
+		"active":  "is_active",
+		"updated": "updated_at",
+		"created": "created_at",
+	}
+
+	// Apply Query Operation
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total
+}
+
+func (r *productRepository) Insert(ctx context.Context, product *entity.Product) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(product).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *productRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, product *entity.Product) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	// Select all column so zero value (no fee, no max otr, inactive) is saved too
+	if err := tx.WithContext(ctx).Model(product).Where("id = ?", product.ID).
+		Select(
+			"code", "name", "description", "interest_method", "interest_rate", "admin_fee_type",
+			"admin_fee_amount", "admin_fee_rate", "min_on_the_road", "max_on_the_road", "is_active",
+		).
+		Updates(product).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// ReplaceRulesWithTransaction delete every tenor and fee tier of the product and insert the new one
+func (r *productRepository) ReplaceRulesWithTransaction(ctx context.Context, tx *gorm.DB, product *entity.Product) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Where("product_id = ?", product.ID).
+		Delete(&entity.ProductTenor{}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	if err := tx.WithContext(ctx).Where("product_id = ?", product.ID).
+		Delete(&entity.ProductFeeTier{}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	for i := range product.Tenors {
+		product.Tenors[i].ID = 0
+		product.Tenors[i].ProductID = product.ID
+	}
+
+	if err := tx.WithContext(ctx).Create(&product.Tenors).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	if len(product.FeeTiers) == 0 {
+		return nil
+	}
+
+	for i := range product.FeeTiers {
+		product.FeeTiers[i].ID = 0
+		product.FeeTiers[i].ProductID = product.ID
+	}
+
+	if err := tx.WithContext(ctx).Create(&product.FeeTiers).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *productRepository) Delete(ctx context.Context, product *entity.Product) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(product).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// CodeExist include deleted product, code is unique in the table
+func (r *productRepository) CodeExist(ctx context.Context, product *entity.Product) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+
+	tx := r.DB.WithContext(ctx).Unscoped().Model(&entity.Product{}).Where("code = ?", product.Code)
+
+	if product.ID != 0 {
+		tx = tx.Not("id = ?", product.ID)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
+
+func (r *productRepository) preloadRules(tx *gorm.DB) *gorm.DB {
+	return tx.
+		Preload("Tenors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tenor asc")
+		}).
+		Preload("FeeTiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_on_the_road asc")
+		})
+}
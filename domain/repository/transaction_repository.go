@@ -2,26 +2,40 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrTransactionStatusChanged is returned when the transaction left the expected status
+// before the update, another request already moved it
+var ErrTransactionStatusChanged = errors.New("transaction status already changed")
+
 type TransactionRepository interface {
 	BeginTransaction(ctx context.Context) *gorm.DB
 	FindByUUID(ctx context.Context, uuid uuid.UUID) (transaction *entity.Transaction, err error)
 	FindByID(ctx context.Context, id uint) (transaction *entity.Transaction, err error)
 	FindAll(ctx context.Context, query *model.QueryGet) (transactions *[]entity.Transaction, err error)
 	FindAllByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (transactions *[]entity.Transaction, err error)
+	FindAllByStatus(ctx context.Context, query *model.QueryGet, status entity.TransactionStatus) (transactions *[]entity.Transaction, err error)
 	Count(ctx context.Context, query *model.QueryGet) (total int64)
 	CountByUserID(ctx context.Context, query *model.QueryGet, user_id uint) (total int64)
+	CountByStatus(ctx context.Context, query *model.QueryGet, status entity.TransactionStatus) (total int64)
 	CountUnscoped(ctx context.Context, query *model.QueryGet) (total int64)
 	InsertWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error
 	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error
+	AmendWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error
+	UpdateContractFileWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error
+	UpdateStatusWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, statusLog *entity.TransactionStatusLog) error
+	InsertRevisionWithTransaction(ctx context.Context, tx *gorm.DB, revision *entity.TransactionRevision) error
 	DeleteWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error
+	ContractNumberExist(ctx context.Context, contract_number string) bool
+	NextContractSequenceWithTransaction(ctx context.Context, tx *gorm.DB, scope string) (uint64, error)
 }
 
 type transactionRepository struct {
@@ -41,7 +55,7 @@ func (r *transactionRepository) FindByUUID(ctx context.Context, uuid uuid.UUID)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
 	if result := r.DB.WithContext(ctx).Limit(1).Where("uuid = ?", uuid).
-		Preload("User").Preload("User.Profile").Preload("Installments").Preload("Payments").
+		Preload("User").Preload("User.Profile").Preload("Product").Preload("Merchant").Preload("Disbursement").Preload("Installments").Preload("Installments.Penalties").Preload("Payments").Preload("Revisions").Preload("StatusLogs").
 		Find(&transaction); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -56,7 +70,7 @@ func (r *transactionRepository) FindByID(ctx context.Context, id uint) (transact
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
 	if result := r.DB.WithContext(ctx).Limit(1).Where("id = ?", id).
-		Preload("User").Preload("User.Profile").Preload("Installments").Preload("Payments").
+		Preload("User").Preload("User.Profile").Preload("Product").Preload("Merchant").Preload("Disbursement").Preload("Installments").Preload("Installments.Penalties").Preload("Payments").Preload("Revisions").Preload("StatusLogs").
 		Find(&transaction); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -122,6 +136,34 @@ func (r *transactionRepository) FindAllByUserID(ctx context.Context, query *mode
 	return
 }
 
+func (r *transactionRepository) FindAllByStatus(ctx context.Context, query *model.QueryGet, status entity.TransactionStatus) (transactions *[]entity.Transaction, err error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Transaction{}).Where("status = ?", status).
+		Preload("User").Preload("User.Profile").Preload("Product")
+
+	var allowedFields = map[string]string{
+		"created": "transactions.created_at",
+		"updated": "transactions.updated_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Paginate(query),
+		helpers.Order(query, allowedFields),
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Find(&transactions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return
+}
+
 func (r *transactionRepository) Count(ctx context.Context, query *model.QueryGet) (total int64) {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -172,6 +214,30 @@ func (r *transactionRepository) CountByUserID(ctx context.Context, query *model.
 	return
 }
 
+func (r *transactionRepository) CountByStatus(ctx context.Context, query *model.QueryGet, status entity.TransactionStatus) (total int64) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	tx := r.DB.WithContext(ctx).Model(&entity.Transaction{}).Where("status = ?", status)
+
+	var allowedFields = map[string]string{
+		"created": "transactions.created_at",
+		"updated": "transactions.updated_at",
+	}
+
+	tx = tx.Scopes(
+		helpers.Filter(query, allowedFields),
+		helpers.Search(query, allowedFields),
+	)
+
+	if err := tx.Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return
+}
+
 func (r *transactionRepository) CountUnscoped(ctx context.Context, query *model.QueryGet) (total int64) {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -201,14 +267,15 @@ func (r *transactionRepository) InsertWithTransaction(ctx context.Context, tx *g
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	return tx.WithContext(ctx).Create(transaction).Error
+	// Product and merchant are only referenced, they are never written from transaction
+	return tx.WithContext(ctx).Omit("Product", "Merchant", "Disbursement").Create(transaction).Error
 }
 
 func (r *transactionRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if err := tx.WithContext(ctx).Where("id = ?", transaction.ID).Updates(transaction).
+	if err := tx.WithContext(ctx).Where("id = ?", transaction.ID).Omit("Product", "Merchant", "Disbursement").Updates(transaction).
 		Error; err != nil {
 		logData.Err = err
 		logData.Message = "Not Passed"
@@ -218,6 +285,85 @@ func (r *transactionRepository) UpdateWithTransaction(ctx context.Context, tx *g
 	return nil
 }
 
+// AmendWithTransaction update contract terms, including the one that can be zero. Start date
+// only move when a contract waiting approval is disbursed
+func (r *transactionRepository) AmendWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.Transaction{}).Where("id = ?", transaction.ID).
+		Select(
+			"product_id", "asset_name", "on_the_road", "admin_fee", "monthly_installment", "interest_amount",
+			"interest_method", "interest_rate", "tenor", "billing_day", "limit_pool_mode", "start_date", "end_date",
+			"updated_at",
+		).
+		Updates(transaction).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) UpdateContractFileWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Model(&entity.Transaction{}).Where("id = ?", transaction.ID).
+		Update("contract_file", transaction.ContractFile).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatusWithTransaction write the transaction status with the log of the change. The
+// status is only moved if it is still the from status of the log, so two request racing on
+// the same contract apply their change once. The first status has no from status, it is
+// already written by the insert
+func (r *transactionRepository) UpdateStatusWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, statusLog *entity.TransactionStatusLog) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if statusLog.FromStatus != "" {
+		result := tx.WithContext(ctx).Model(&entity.Transaction{}).
+			Where("id = ? AND status = ?", transaction.ID, statusLog.FromStatus).
+			Update("status", transaction.Status)
+		if result.Error == nil && result.RowsAffected != 1 {
+			result.Error = ErrTransactionStatusChanged
+		}
+		if result.Error != nil {
+			logData.Err = result.Error
+			logData.Message = "Not Passed"
+			return result.Error
+		}
+	}
+
+	if err := tx.WithContext(ctx).Create(statusLog).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) InsertRevisionWithTransaction(ctx context.Context, tx *gorm.DB, revision *entity.TransactionRevision) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Create(revision).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return err
+	}
+
+	return nil
+}
+
 func (r *transactionRepository) DeleteWithTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -230,3 +376,50 @@ func (r *transactionRepository) DeleteWithTransaction(ctx context.Context, tx *g
 
 	return nil
 }
+
+// ContractNumberExist include deleted transaction, contract number is unique in the table
+func (r *transactionRepository) ContractNumberExist(ctx context.Context, contract_number string) bool {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var total int64
+	if err := r.DB.WithContext(ctx).Unscoped().Model(&entity.Transaction{}).
+		Where("contract_number = ?", contract_number).Count(&total).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+
+	return total != 0
+}
+
+// NextContractSequenceWithTransaction increment the sequence of the scope and return it.
+// The sequence row stay locked until tx end, so other instance wait for it and a rollback
+// give the number back
+func (r *transactionRepository) NextContractSequenceWithTransaction(ctx context.Context, tx *gorm.DB, scope string) (uint64, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.ContractSequence{Scope: scope}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	var sequence entity.ContractSequence
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ?", scope).First(&sequence).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	sequence.LastValue++
+	if err := tx.WithContext(ctx).Model(&sequence).Update("last_value", sequence.LastValue).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	return sequence.LastValue, nil
+}
@@ -0,0 +1,196 @@
+// Package scoring compute the credit score of a user from the profile, document
+// completeness and internal repayment history. Every factor keep its points, so the
+// reason behind a score can be shown later.
+package scoring
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Score start from base and every factor add its points, result is kept between
+// MinScore and MaxScore
+const (
+	BaseScore = 250
+	MinScore  = 0
+	MaxScore  = 850
+)
+
+// Factor code
+const (
+	FactorIncome    = "income"
+	FactorAge       = "age"
+	FactorDocument  = "document"
+	FactorRepayment = "repayment"
+	FactorOverdue   = "overdue"
+)
+
+// Input is everything the score is computed from, installments are every installment
+// of the user, not yet due and unpaid one is not counted
+type Input struct {
+	Profile      *entity.UserProfile
+	Document     *entity.UserDocument
+	Installments []entity.TransactionInstallment
+	AsOf         time.Time
+}
+
+// Calculate return the score, grade and factor breakdown of the input
+func Calculate(input Input) *entity.CreditScore {
+	factors := []entity.CreditScoreFactor{
+		income(input.Profile),
+		age(input.Profile, input.AsOf),
+		document(input.Document),
+	}
+	factors = append(factors, repayment(input.Installments, input.AsOf)...)
+
+	score := BaseScore
+	for _, factor := range factors {
+		score += factor.Points
+	}
+	score = max(MinScore, min(MaxScore, score))
+
+	grade := Grade(score)
+	return &entity.CreditScore{
+		Score:           uint(score),
+		Grade:           grade,
+		LimitMultiplier: LimitMultiplier(grade),
+		Factors:         factors,
+	}
+}
+
+// Grade map score to risk grade, A is the lowest risk
+func Grade(score int) entity.CreditGrade {
+	switch {
+	case score >= 750:
+		return entity.CreditGradeA
+	case score >= 650:
+		return entity.CreditGradeB
+	case score >= 550:
+		return entity.CreditGradeC
+	case score >= 450:
+		return entity.CreditGradeD
+	default:
+		return entity.CreditGradeE
+	}
+}
+
+// LimitMultiplier scale the limit policy amount by grade, grade C get the policy as is
+func LimitMultiplier(grade entity.CreditGrade) decimal.Decimal {
+	switch grade {
+	case entity.CreditGradeA:
+		return decimal.NewFromFloat(1.5)
+	case entity.CreditGradeB:
+		return decimal.NewFromFloat(1.25)
+	case entity.CreditGradeC:
+		return decimal.NewFromInt(1)
+	case entity.CreditGradeD:
+		return decimal.NewFromFloat(0.75)
+	default:
+		return decimal.NewFromFloat(0.5)
+	}
+}
+
+func income(profile *entity.UserProfile) entity.CreditScoreFactor {
+	factor := entity.CreditScoreFactor{Code: FactorIncome, Description: "Monthly salary", Value: "unknown"}
+	if profile == nil || !profile.Salary.Valid {
+		return factor
+	}
+
+	salary := profile.Salary.Decimal
+	factor.Value = salary.StringFixed(2)
+	switch {
+	case salary.GreaterThanOrEqual(decimal.NewFromInt(20000000)):
+		factor.Points = 200
+	case salary.GreaterThanOrEqual(decimal.NewFromInt(10000000)):
+		factor.Points = 170
+	case salary.GreaterThanOrEqual(decimal.NewFromInt(5000000)):
+		factor.Points = 120
+	case salary.GreaterThanOrEqual(decimal.NewFromInt(3000000)):
+		factor.Points = 60
+	}
+
+	return factor
+}
+
+func age(profile *entity.UserProfile, as_of time.Time) entity.CreditScoreFactor {
+	factor := entity.CreditScoreFactor{Code: FactorAge, Description: "Age in years", Value: "unknown"}
+	if profile == nil {
+		return factor
+	}
+
+	years := profile.Age(as_of)
+	factor.Value = fmt.Sprintf("%d", years)
+	switch {
+	case years < 21:
+		factor.Points = 0
+	case years <= 25:
+		factor.Points = 40
+	case years <= 55:
+		factor.Points = 80
+	default:
+		factor.Points = 40
+	}
+
+	return factor
+}
+
+func document(document *entity.UserDocument) entity.CreditScoreFactor {
+	factor := entity.CreditScoreFactor{Code: FactorDocument, Description: "Uploaded ktp and selfie"}
+
+	uploaded := 0
+	if document != nil {
+		if document.KtpFile != "" {
+			uploaded++
+		}
+		if document.SelfieFile != "" {
+			uploaded++
+		}
+	}
+
+	factor.Value = fmt.Sprintf("%d/2", uploaded)
+	factor.Points = uploaded * 50
+
+	return factor
+}
+
+// repayment reward installments paid on time and punish installments still overdue, user
+// without history get a neutral points
+func repayment(installments []entity.TransactionInstallment, as_of time.Time) []entity.CreditScoreFactor {
+	history := entity.CreditScoreFactor{Code: FactorRepayment, Description: "Installments paid on time", Value: "no history", Points: 80}
+	overdue := entity.CreditScoreFactor{Code: FactorOverdue, Description: "Installments still overdue"}
+
+	onTime, due, unpaid := 0, 0, 0
+	for _, installment := range installments {
+		if installment.PaymentStatus == entity.PaymentStatusFailed {
+			continue
+		}
+
+		switch {
+		case installment.PaidAt.Valid:
+			due++
+			if !installment.PaidAt.Time.After(endOfDay(installment.DueDate)) {
+				onTime++
+			}
+		case endOfDay(installment.DueDate).Before(as_of):
+			due++
+			unpaid++
+		}
+	}
+
+	if due > 0 {
+		history.Value = fmt.Sprintf("%d/%d", onTime, due)
+		history.Points = 200 * onTime / due
+	}
+
+	overdue.Value = fmt.Sprintf("%d", unpaid)
+	overdue.Points = -min(300, 60*unpaid)
+
+	return []entity.CreditScoreFactor{history, overdue}
+}
+
+func endOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/scoring"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type CreditScoreService interface {
+	GetAllByUser(ctx context.Context, user_uuid uuid.UUID, query *model.QueryGet, url string) helpers.BaseResponse
+	Calculate(ctx context.Context, user_uuid uuid.UUID) helpers.BaseResponse
+}
+
+type creditScoreService struct {
+	creditScoreRepository repository.CreditScoreRepository
+	userRepository        repository.UserRepository
+	installmentRepository repository.InstallmentRepository
+}
+
+func NewCreditScoreService(
+	creditScoreRepository repository.CreditScoreRepository,
+	userRepository repository.UserRepository,
+	installmentRepository repository.InstallmentRepository,
+) CreditScoreService {
+	return &creditScoreService{
+		creditScoreRepository: creditScoreRepository,
+		userRepository:        userRepository,
+		installmentRepository: installmentRepository,
+	}
+}
+
+func (s *creditScoreService) GetAllByUser(ctx context.Context, user_uuid uuid.UUID, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByUUID(ctx, user_uuid)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		})
+	}
+
+	scores, err := s.creditScoreRepository.FindAllByUserID(ctx, query, user.ID)
+	if err != nil || scores == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Credit score not found",
+			Errors:  err,
+		})
+	}
+
+	scoreModels := model.CreditScoreToDetailModels(*scores)
+
+	totalData := s.creditScoreRepository.CountByUserID(ctx, query, user.ID)
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Credit score data found",
+		Data:    scoreModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+// Calculate score the user again with the current profile and repayment history. The
+// new score is kept but existing limit is not changed.
+func (s *creditScoreService) Calculate(ctx context.Context, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByUUID(ctx, user_uuid)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		})
+	}
+
+	score, err := calculateCreditScore(ctx, s.installmentRepository, user, time.Now())
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error getting repayment history",
+			Errors:  err,
+		})
+	}
+
+	if err := s.creditScoreRepository.Insert(ctx, score); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Credit score successfully calculated",
+		Data:    model.CreditScoreToDetailModel(score),
+	})
+}
+
+// calculateCreditScore score the user from profile, document and repayment history, the
+// result is not saved yet
+func calculateCreditScore(ctx context.Context, installmentRepository repository.InstallmentRepository,
+	user *entity.User, as_of time.Time) (*entity.CreditScore, error) {
+	installments, err := installmentRepository.FindAllHistoryByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []entity.TransactionInstallment{}
+	if installments != nil {
+		history = *installments
+	}
+
+	score := scoring.Calculate(scoring.Input{
+		Profile:      user.Profile,
+		Document:     user.Document,
+		Installments: history,
+		AsOf:         as_of,
+	})
+	score.UserID = user.ID
+
+	return score, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// DisbursementService track what is paid to merchant for active contract, the money
+// itself is sent outside and only recorded here
+type DisbursementService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	GetOwed(ctx context.Context) helpers.BaseResponse
+	ChangeStatus(ctx context.Context, input *model.DisbursementStatusInput, uuid uuid.UUID) helpers.BaseResponse
+}
+
+type disbursementService struct {
+	disbursementRepository repository.DisbursementRepository
+}
+
+func NewDisbursementService(disbursementRepository repository.DisbursementRepository) DisbursementService {
+	return &disbursementService{
+		disbursementRepository: disbursementRepository,
+	}
+}
+
+func (s *disbursementService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	disbursement, err := s.disbursementRepository.FindByUUID(ctx, uuid)
+	if err != nil || disbursement == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Disbursement not found",
+			Errors:  err,
+		})
+	}
+
+	disbursementModel := model.DisbursementToDetailModel(disbursement)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Disbursement data found",
+		Data:    disbursementModel,
+	})
+}
+
+func (s *disbursementService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	disbursements, err := s.disbursementRepository.FindAll(ctx, query)
+	if err != nil || disbursements == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Disbursement not found",
+			Errors:  err,
+		})
+	}
+
+	disbursementModels := model.DisbursementToListModels(*disbursements)
+
+	totalData := s.disbursementRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Disbursement data found",
+		Data:    disbursementModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+// GetOwed list what is still owed to every merchant, failed disbursement included
+func (s *disbursementService) GetOwed(ctx context.Context) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchants, err := s.disbursementRepository.OwedByMerchant(ctx)
+	if err != nil || merchants == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error calculating merchant owed",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Merchant owed data found",
+		Data:    merchants,
+	})
+}
+
+func (s *disbursementService) ChangeStatus(ctx context.Context, input *model.DisbursementStatusInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	disbursement, err := s.disbursementRepository.FindByUUID(ctx, uuid)
+	if err != nil || disbursement == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Disbursement not found",
+			Errors:  err,
+		})
+	}
+
+	from := disbursement.Status
+	if err := disbursement.Transition(entity.DisbursementStatus(input.Status), input.Reference, input.Notes, time.Now()); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.disbursementRepository.UpdateStatus(ctx, disbursement, from); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Error updating disbursement data",
+			Errors:  err.Error(),
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Disbursement status successfully changed",
+	})
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// HolidayService manage the holiday table used to shift installment due date,
+// change only apply to schedule generated afterward
+type HolidayService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.HolidayInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.HolidayInput, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+}
+
+type holidayService struct {
+	holidayRepository repository.HolidayRepository
+}
+
+func NewHolidayService(holidayRepository repository.HolidayRepository) HolidayService {
+	return &holidayService{
+		holidayRepository: holidayRepository,
+	}
+}
+
+func (s *holidayService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	holiday, err := s.holidayRepository.FindByUUID(ctx, uuid)
+	if err != nil || holiday == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Holiday not found",
+			Errors:  err,
+		})
+	}
+
+	holidayModel := model.HolidayToDetailModel(holiday)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Holiday data found",
+		Data:    holidayModel,
+	})
+}
+
+func (s *holidayService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	holidays, err := s.holidayRepository.FindAll(ctx, query)
+	if err != nil || holidays == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Holiday not found",
+			Errors:  err,
+		})
+	}
+
+	holidayModels := model.HolidayToListModels(*holidays)
+
+	totalData := s.holidayRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Holiday data found",
+		Data:    holidayModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *holidayService) Create(ctx context.Context, input *model.HolidayInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	holidayEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if err := s.validateEntityInput(ctx, holidayEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.holidayRepository.Insert(ctx, holidayEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Holiday successfully created",
+	})
+}
+
+func (s *holidayService) UpdateByUUID(ctx context.Context, input *model.HolidayInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	holiday, err := s.holidayRepository.FindByUUID(ctx, uuid)
+	if err != nil || holiday == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Holiday not found",
+			Errors:  err,
+		})
+	}
+
+	holidayEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+	holidayEntity.ID = holiday.ID
+
+	if err := s.validateEntityInput(ctx, holidayEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.holidayRepository.Update(ctx, holidayEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Holiday successfully updated",
+	})
+}
+
+func (s *holidayService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	holiday, err := s.holidayRepository.FindByUUID(ctx, uuid)
+	if err != nil || holiday == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Holiday not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.holidayRepository.Delete(ctx, holiday); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Holiday successfully deleted",
+	})
+}
+
+func (s *holidayService) validateEntityInput(ctx context.Context, holiday *entity.Holiday) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errs := []helpers.ValidationError{}
+
+	// Check date duplication
+	if exist := s.holidayRepository.DateExist(ctx, holiday); exist {
+		errs = append(errs, helpers.ValidationError{
+			Field: "date",
+			Tag:   "duplicate",
+		})
+	}
+
+	if len(errs) != 0 {
+		logData.Message = "Validation error"
+		logData.Err = errs
+		return errs
+	}
+
+	return nil
+}
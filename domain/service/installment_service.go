@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -14,6 +16,7 @@ import (
 type InstallmentService interface {
 	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
 	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	MarkOverdue(ctx context.Context, as_of time.Time) (int64, error)
 }
 
 type installmentService struct {
@@ -103,3 +106,19 @@ func (s *installmentService) GetAll(ctx context.Context, query *model.QueryGet,
 		},
 	})
 }
+
+// MarkOverdue is run by the overdue worker, not exposed through http
+func (s *installmentService) MarkOverdue(ctx context.Context, as_of time.Time) (int64, error) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	total, err := s.installmentRepository.MarkOverdue(ctx, as_of)
+	if err != nil {
+		logData.Message = "Failed marking overdue installments"
+		logData.Err = err.Error()
+		return 0, err
+	}
+
+	logData.Message = fmt.Sprintf("%d installments marked overdue as of %s", total, as_of.Format(time.DateOnly))
+	return total, nil
+}
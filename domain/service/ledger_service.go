@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type LedgerService interface {
+	TrialBalance(ctx context.Context, as_of time.Time) helpers.BaseResponse
+}
+
+type ledgerService struct {
+	ledgerRepository repository.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepository repository.LedgerRepository) LedgerService {
+	return &ledgerService{
+		ledgerRepository: ledgerRepository,
+	}
+}
+
+// TrialBalance sum every account for entries posted before as_of, total debit must
+// equal total credit when every money movement is posted balanced
+func (s *ledgerService) TrialBalance(ctx context.Context, as_of time.Time) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	accounts, err := s.ledgerRepository.TrialBalance(ctx, as_of)
+	if err != nil || accounts == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Ledger account Not Found",
+			Errors:  err,
+		})
+	}
+
+	trialBalance := model.ToTrialBalanceModel(*accounts, as_of)
+
+	message := "Trial balance is balanced"
+	if !trialBalance.IsBalanced {
+		message = "Trial balance is not balanced"
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: message,
+		Data:    trialBalance,
+	})
+}
@@ -0,0 +1,531 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type LimitPolicyService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.LimitPolicyInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.LimitPolicyInput, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	Apply(ctx context.Context, input *model.LimitPolicyApplyInput, uuid uuid.UUID) helpers.BaseResponse
+}
+
+type limitPolicyService struct {
+	limitPolicyRepository repository.LimitPolicyRepository
+	limitRepository       repository.LimitRepository
+	userRepository        repository.UserRepository
+	lockRedis             *redis.LockClient
+}
+
+func NewLimitPolicyService(
+	limitPolicyRepository repository.LimitPolicyRepository,
+	limitRepository repository.LimitRepository,
+	userRepository repository.UserRepository,
+	lockRedis *redis.LockClient,
+) LimitPolicyService {
+	return &limitPolicyService{
+		limitPolicyRepository: limitPolicyRepository,
+		limitRepository:       limitRepository,
+		userRepository:        userRepository,
+		lockRedis:             lockRedis,
+	}
+}
+
+func (s *limitPolicyService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.limitPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Limit policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyModel := model.LimitPolicyToDetailModel(policy)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Limit policy data found",
+		Data:    policyModel,
+	})
+}
+
+func (s *limitPolicyService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policies, err := s.limitPolicyRepository.FindAll(ctx, query)
+	if err != nil || policies == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Limit policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyModels := model.LimitPolicyToListModels(*policies)
+
+	totalData := s.limitPolicyRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Limit policy data found",
+		Data:    policyModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *limitPolicyService) Create(ctx context.Context, input *model.LimitPolicyInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policyEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if err := s.validateEntityInput(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.limitPolicyRepository.Insert(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Limit policy successfully created",
+	})
+}
+
+func (s *limitPolicyService) UpdateByUUID(ctx context.Context, input *model.LimitPolicyInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.limitPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Limit policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+	policyEntity.ID = policy.ID
+
+	if err := s.validateEntityInput(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	tx := s.limitPolicyRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := s.limitPolicyRepository.UpdateWithTransaction(ctx, tx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	if err := s.limitPolicyRepository.ReplaceItemsWithTransaction(ctx, tx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	tx.Commit()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Limit policy successfully updated",
+	})
+}
+
+func (s *limitPolicyService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.limitPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Limit policy not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.limitPolicyRepository.Delete(ctx, policy); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Limit policy successfully deleted",
+	})
+}
+
+// Apply replace the limits of the listed users with the policy tenors, regardless of the
+// policy condition. Each user is applied on its own, so one failing user does not stop
+// the others.
+func (s *limitPolicyService) Apply(ctx context.Context, input *model.LimitPolicyApplyInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.limitPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Limit policy not found",
+			Errors:  err,
+		})
+	}
+
+	results := []model.LimitPolicyApplyResult{}
+	for _, user_uuid := range input.UserUUIDs {
+		result := model.LimitPolicyApplyResult{
+			UserUUID: user_uuid,
+			Success:  true,
+			Message:  "User limit successfully updated",
+		}
+
+		if errResponse := s.applyToUser(ctx, policy, user_uuid, input.Reason); errResponse != nil {
+			result.Success = false
+			result.Message = errResponse.Message
+		}
+
+		results = append(results, result)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Limit policy successfully applied",
+		Data:    results,
+	})
+}
+
+// applyToUser set every policy tenor as the user limit and remove tenor outside the
+// policy. Used amount is kept, so a limit is never lowered below it.
+func (s *limitPolicyService) applyToUser(ctx context.Context, policy *entity.LimitPolicy, user_uuid string, reason string) *helpers.BaseResponse {
+	parsed_uuid, err := uuid.Parse(user_uuid)
+	if err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+		}
+	}
+
+	user, err := s.userRepository.FindByUUID(ctx, parsed_uuid)
+	if err != nil || user == nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+		}
+	}
+
+	if !user.ValidatedAt.Valid {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "User is not activated",
+		}
+	}
+
+	// Lock limit
+	lock_ttl := 10 * time.Second
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+		}
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, user.ID)
+	if err != nil || limits == nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error getting user limit data",
+		}
+	}
+
+	policy_tenors := map[uint]bool{}
+	for _, item := range policy.LimitItems() {
+		policy_tenors[item.Tenor] = true
+	}
+
+	// Tenor left out of the policy is removed, reject, cancel and payoff of an open
+	// contract or checkout on it would not find the limit to give the otr back to
+	for _, limit := range *limits {
+		if policy_tenors[limit.Tenor] {
+			continue
+		}
+
+		total, err := s.limitRepository.CountOpenUsage(ctx, user.ID, limit.Tenor)
+		if err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error getting user limit usage",
+			}
+		}
+		if total > 0 {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: fmt.Sprintf("User limit tenor %d is still used by an open contract or checkout", limit.Tenor),
+			}
+		}
+	}
+
+	changed_by := changedBy(ctx)
+
+	tx := s.limitRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	for _, item := range policy.LimitItems() {
+		limit, _ := s.limitRepository.FindByUserIDAndTenorUnscoped(ctx, user.ID, item.Tenor)
+		if limit == nil {
+			limit = &entity.Limit{UserID: user.ID, Tenor: item.Tenor}
+		}
+		used := limitUsedAmount(*limits, limit, policy.PoolMode)
+
+		change := entity.LimitChange{
+			UserID:                user.ID,
+			Tenor:                 item.Tenor,
+			PreviousOriginalLimit: limit.OriginalLimit,
+			PreviousCurrentLimit:  limit.CurrentLimit,
+			Reason:                reason,
+			ChangedBy:             changed_by,
+		}
+
+		original := decimal.Max(item.Amount, used)
+		switch {
+		case limit.ID == 0 || limit.DeletedAt.Valid:
+			change.Action = entity.LimitCreate
+			change.Amount = original
+		case original.GreaterThan(limit.OriginalLimit):
+			change.Action = entity.LimitRaise
+			change.Amount = original.Sub(limit.OriginalLimit)
+		case original.LessThan(limit.OriginalLimit):
+			change.Action = entity.LimitLower
+			change.Amount = limit.OriginalLimit.Sub(original)
+		default:
+			// Same amount, only the policy reference change
+			change.Action = ""
+		}
+
+		limit.OriginalLimit = original
+		limit.CurrentLimit = original.Sub(used)
+		limit.LimitPolicyID = &policy.ID
+		limit.PoolMode = policy.PoolMode
+		limit.DeletedAt = gorm.DeletedAt{}
+		if change.Action == entity.LimitCreate {
+			limit.FrozenAt = sql.NullTime{}
+		}
+
+		if limit.ID == 0 {
+			err = s.limitRepository.InsertWithTransaction(ctx, tx, limit)
+		} else {
+			err = s.limitRepository.UpdateWithTransaction(ctx, tx, limit)
+		}
+		if err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error updating user limit data",
+			}
+		}
+
+		if change.Action == "" {
+			continue
+		}
+
+		change.LimitID = limit.ID
+		change.OriginalLimit = limit.OriginalLimit
+		change.CurrentLimit = limit.CurrentLimit
+		if err := s.limitRepository.InsertChangeWithTransaction(ctx, tx, &change); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error creating user limit change data",
+			}
+		}
+	}
+
+	for _, limit := range *limits {
+		if policy_tenors[limit.Tenor] {
+			continue
+		}
+
+		if err := s.limitRepository.DeleteWithTransaction(ctx, tx, &limit); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error updating user limit data",
+			}
+		}
+
+		if err := s.limitRepository.InsertChangeWithTransaction(ctx, tx, &entity.LimitChange{
+			UserID:                user.ID,
+			LimitID:               limit.ID,
+			Tenor:                 limit.Tenor,
+			Action:                entity.LimitRemove,
+			PreviousOriginalLimit: limit.OriginalLimit,
+			PreviousCurrentLimit:  limit.CurrentLimit,
+			OriginalLimit:         limit.OriginalLimit,
+			CurrentLimit:          limit.CurrentLimit,
+			Reason:                reason,
+			ChangedBy:             changed_by,
+		}); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error creating user limit change data",
+			}
+		}
+	}
+
+	tx.Commit()
+	return nil
+}
+
+func (s *limitPolicyService) validateEntityInput(ctx context.Context, policy *entity.LimitPolicy) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errs := []helpers.ValidationError{}
+
+	// Check name duplication
+	if exist := s.limitPolicyRepository.NameExist(ctx, policy); exist {
+		errs = append(errs, helpers.ValidationError{
+			Field: "name",
+			Tag:   "duplicate",
+		})
+	}
+
+	if policy.MinSalary.Valid && policy.MaxSalary.Valid && policy.MinSalary.Decimal.GreaterThan(policy.MaxSalary.Decimal) {
+		errs = append(errs, helpers.ValidationError{
+			Field: "min_salary",
+			Tag:   "ltefield",
+		})
+	}
+
+	if policy.MinAge != nil && policy.MaxAge != nil && *policy.MinAge > *policy.MaxAge {
+		errs = append(errs, helpers.ValidationError{
+			Field: "min_age",
+			Tag:   "ltefield",
+		})
+	}
+
+	tenors := map[uint]bool{}
+	for _, item := range policy.Items {
+		if tenors[item.Tenor] {
+			errs = append(errs, helpers.ValidationError{
+				Field: "tenor",
+				Tag:   "duplicate",
+			})
+			break
+		}
+		tenors[item.Tenor] = true
+	}
+
+	if len(errs) != 0 {
+		logData.Message = "Validation error"
+		logData.Err = errs
+		return errs
+	}
+
+	return nil
+}
+
+// selectLimitPolicy pick the first eligible policy, policies is ordered by priority. User
+// without profile only get policy without condition
+func selectLimitPolicy(policies []entity.LimitPolicy, profile *entity.UserProfile, as_of time.Time) *entity.LimitPolicy {
+	for i, policy := range policies {
+		if profile == nil {
+			if !policy.HasCondition() {
+				return &policies[i]
+			}
+			continue
+		}
+
+		if policy.Eligible(profile.Salary.Decimal, profile.Age(as_of)) {
+			return &policies[i]
+		}
+	}
+
+	return nil
+}
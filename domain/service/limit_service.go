@@ -2,27 +2,47 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type LimitService interface {
 	GetUserLimit(ctx context.Context, uuid uuid.UUID, query *model.QueryGet, url string) helpers.BaseResponse
+	GetUserLimitHistory(ctx context.Context, uuid uuid.UUID, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse
+	Raise(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse
+	Lower(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse
+	Freeze(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse
+	Unfreeze(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse
+	Remove(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse
 }
 
 type limitService struct {
 	userRepository  repository.UserRepository
 	limitRepository repository.LimitRepository
+	lockRedis       *redis.LockClient
 }
 
-func NewLimitService(userRepository repository.UserRepository, limitRepository repository.LimitRepository) LimitService {
+// limitMutation change the limit in place, limits is every other active limit of the user
+type limitMutation func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse
+
+func NewLimitService(userRepository repository.UserRepository, limitRepository repository.LimitRepository,
+	lockRedis *redis.LockClient) LimitService {
 	return &limitService{
 		userRepository:  userRepository,
 		limitRepository: limitRepository,
+		lockRedis:       lockRedis,
 	}
 }
 
@@ -73,3 +93,517 @@ func (s *limitService) GetUserLimit(ctx context.Context, uuid uuid.UUID, query *
 		},
 	})
 }
+
+func (s *limitService) GetUserLimitHistory(ctx context.Context, uuid uuid.UUID, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByUUID(ctx, uuid)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		})
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, user.ID) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	changes, err := s.limitRepository.FindAllChangesByUserID(ctx, query, user.ID)
+	if err != nil || changes == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User limit history Not Found",
+			Errors:  err,
+		})
+	}
+
+	changeModels := model.LimitChangeToListModels(*changes)
+
+	totalData := s.limitRepository.CountChangesByUserID(ctx, query, user.ID)
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit history found",
+		Data:    changeModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *limitService) Create(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	amount, errResponse := parseLimitAmount(input.Amount)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	limit, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitCreate, amount, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			if limit.ID != 0 && !limit.DeletedAt.Valid {
+				return &helpers.BaseResponse{
+					Status:  fiber.StatusConflict,
+					Success: false,
+					Message: "User limit for this tenor already exist",
+				}
+			}
+
+			// New limit join the pool mode of the other limits, on shared pool it start
+			// with the amount already used by the other limits
+			mode := userLimitPoolMode(limits)
+			current := amount.Sub(limitUsedAmount(limits, limit, mode))
+			if current.IsNegative() {
+				current = decimal.Zero
+			}
+
+			limit.OriginalLimit = amount
+			limit.CurrentLimit = current
+			limit.PoolMode = mode
+			limit.FrozenAt = sql.NullTime{}
+			limit.DeletedAt = gorm.DeletedAt{}
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "User limit successfully created",
+		Data:    model.LimitToListModel(limit),
+	})
+}
+
+func (s *limitService) Raise(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	amount, errResponse := parseLimitAmount(input.Amount)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	limit, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitRaise, amount, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			limit.OriginalLimit = limit.OriginalLimit.Add(amount)
+			limit.CurrentLimit = limit.CurrentLimit.Add(amount)
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit successfully raised",
+		Data:    model.LimitToListModel(limit),
+	})
+}
+
+func (s *limitService) Lower(ctx context.Context, input *model.LimitInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	amount, errResponse := parseLimitAmount(input.Amount)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	limit, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitLower, amount, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			if amount.GreaterThan(limit.CurrentLimit) {
+				return &helpers.BaseResponse{
+					Status:  fiber.StatusBadRequest,
+					Success: false,
+					Message: "Limit can not be lowered below used amount",
+					Errors: map[string]string{
+						"used_amount":   limit.OriginalLimit.Sub(limit.CurrentLimit).StringFixed(2),
+						"max_lowerable": limit.CurrentLimit.StringFixed(2),
+					},
+				}
+			}
+
+			limit.OriginalLimit = limit.OriginalLimit.Sub(amount)
+			limit.CurrentLimit = limit.CurrentLimit.Sub(amount)
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit successfully lowered",
+		Data:    model.LimitToListModel(limit),
+	})
+}
+
+func (s *limitService) Freeze(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	limit, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitFreeze, decimal.Zero, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			if limit.FrozenAt.Valid {
+				return &helpers.BaseResponse{
+					Status:  fiber.StatusBadRequest,
+					Success: false,
+					Message: "User limit already frozen",
+				}
+			}
+
+			limit.FrozenAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit successfully frozen",
+		Data:    model.LimitToListModel(limit),
+	})
+}
+
+func (s *limitService) Unfreeze(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	limit, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitUnfreeze, decimal.Zero, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			if !limit.FrozenAt.Valid {
+				return &helpers.BaseResponse{
+					Status:  fiber.StatusBadRequest,
+					Success: false,
+					Message: "User limit is not frozen",
+				}
+			}
+
+			limit.FrozenAt = sql.NullTime{}
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit successfully unfrozen",
+		Data:    model.LimitToListModel(limit),
+	})
+}
+
+func (s *limitService) Remove(ctx context.Context, input *model.LimitActionInput, user_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	_, errResponse := s.changeLimit(ctx, user_uuid, input.Tenor, entity.LimitRemove, decimal.Zero, input.Reason,
+		func(limit *entity.Limit, limits []entity.Limit) *helpers.BaseResponse {
+			// Reject, cancel and payoff give the otr back to the tenor limit, it must exist
+			if errResponse := s.checkLimitNotInUse(ctx, limit); errResponse != nil {
+				return errResponse
+			}
+
+			limit.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			return nil
+		})
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit successfully removed",
+	})
+}
+
+// changeLimit apply mutation to the user limit of the tenor under the user limit lock, and
+// save it together with the change record. Only create may work on a missing or removed limit
+func (s *limitService) changeLimit(ctx context.Context, user_uuid uuid.UUID, tenor uint, action entity.LimitChangeAction,
+	amount decimal.Decimal, reason string, mutate limitMutation) (*entity.Limit, *helpers.BaseResponse) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByUUID(ctx, user_uuid)
+	if err != nil || user == nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		}
+	}
+
+	// Lock limit
+	lock_ttl := 10 * time.Second
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		}
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	limit, _ := s.limitRepository.FindByUserIDAndTenorUnscoped(ctx, user.ID, tenor)
+	if limit == nil {
+		if action != entity.LimitCreate {
+			return nil, &helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "User limit Not Found",
+			}
+		}
+		limit = &entity.Limit{UserID: user.ID, Tenor: tenor}
+	} else if limit.DeletedAt.Valid && action != entity.LimitCreate {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User limit Not Found",
+		}
+	}
+
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, user.ID)
+	if err != nil || limits == nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error getting user limit data",
+			Errors:  err,
+		}
+	}
+
+	otherLimits := []entity.Limit{}
+	for _, other := range *limits {
+		if other.ID != limit.ID {
+			otherLimits = append(otherLimits, other)
+		}
+	}
+
+	change := entity.LimitChange{
+		UserID:                user.ID,
+		Tenor:                 tenor,
+		Action:                action,
+		Amount:                amount,
+		PreviousOriginalLimit: limit.OriginalLimit,
+		PreviousCurrentLimit:  limit.CurrentLimit,
+		Reason:                reason,
+		ChangedBy:             changedBy(ctx),
+	}
+
+	if errResponse := mutate(limit, otherLimits); errResponse != nil {
+		return nil, errResponse
+	}
+
+	tx := s.limitRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if limit.ID == 0 {
+		err = s.limitRepository.InsertWithTransaction(ctx, tx, limit)
+	} else if action == entity.LimitRemove {
+		err = s.limitRepository.DeleteWithTransaction(ctx, tx, limit)
+	} else {
+		err = s.limitRepository.UpdateWithTransaction(ctx, tx, limit)
+	}
+	if err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating user limit data",
+			Errors:  err,
+		}
+	}
+
+	change.LimitID = limit.ID
+	change.OriginalLimit = limit.OriginalLimit
+	change.CurrentLimit = limit.CurrentLimit
+	if err := s.limitRepository.InsertChangeWithTransaction(ctx, tx, &change); err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating user limit change data",
+			Errors:  err,
+		}
+	}
+
+	tx.Commit()
+	return limit, nil
+}
+
+func parseLimitAmount(value string) (decimal.Decimal, *helpers.BaseResponse) {
+	amount, err := decimal.NewFromString(value)
+	if err != nil || !amount.IsPositive() {
+		return decimal.Zero, &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Amount must be greater than zero",
+			Errors:  err,
+		}
+	}
+
+	return amount.Round(2), nil
+}
+
+// usedLimitAmount is the otr in use, the same amount is taken from every limit of the user
+// but a limit never go below zero, so the biggest one is used
+func usedLimitAmount(limits []entity.Limit) decimal.Decimal {
+	used := decimal.Zero
+	for _, limit := range limits {
+		used = decimal.Max(used, limit.OriginalLimit.Sub(limit.CurrentLimit))
+	}
+
+	return used
+}
+
+// userLimitPoolMode is the pool mode of the user limits, shared when the user has no limit
+func userLimitPoolMode(limits []entity.Limit) entity.LimitPoolMode {
+	for _, limit := range limits {
+		if limit.PoolMode != "" {
+			return limit.PoolMode
+		}
+	}
+
+	return entity.LimitPoolShared
+}
+
+// limitUsedAmount is the otr in use of the limit under the pool mode. Shared pool take the
+// same amount from every limit so the usage of the whole pool count, other mode only count
+// what is taken from the limit itself. Removed limit has nothing in use
+func limitUsedAmount(limits []entity.Limit, limit *entity.Limit, mode entity.LimitPoolMode) decimal.Decimal {
+	if mode == entity.LimitPoolShared {
+		return usedLimitAmount(limits)
+	}
+	if limit.ID == 0 || limit.DeletedAt.Valid {
+		return decimal.Zero
+	}
+
+	return limit.OriginalLimit.Sub(limit.CurrentLimit)
+}
+
+// changedBy is the session user, nil when the change is made by the system
+func changedBy(ctx context.Context) *uint {
+	// Partner request has no session user, it is recorded like the system
+	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
+	if !ok || session_user_id == 0 {
+		return nil
+	}
+
+	user_id := uint(session_user_id)
+	return &user_id
+}
+
+// transactionLimitMovement describe a limit movement caused by the transaction otr
+func transactionLimitMovement(ctx context.Context, transaction *entity.Transaction, action entity.LimitChangeAction, reason string) entity.LimitChange {
+	transaction_uuid := transaction.UUID
+	return entity.LimitChange{
+		UserID:          transaction.UserID,
+		Action:          action,
+		TransactionUUID: &transaction_uuid,
+		Amount:          transaction.OnTheRoad,
+		Reason:          reason,
+		ChangedBy:       changedBy(ctx),
+	}
+}
+
+// checkoutLimitMovement is the movement of a limit held or released by a checkout session
+func checkoutLimitMovement(ctx context.Context, session *entity.CheckoutSession, action entity.LimitChangeAction, reason string) entity.LimitChange {
+	checkout_uuid := session.UUID
+	return entity.LimitChange{
+		UserID:       session.UserID,
+		Action:       action,
+		CheckoutUUID: &checkout_uuid,
+		Amount:       session.OnTheRoad,
+		Reason:       reason,
+		ChangedBy:    changedBy(ctx),
+	}
+}
+
+// updateLimitsWithMovement write the updated limits and append a movement for every limit
+// whose current limit changed, in the same db transaction
+func updateLimitsWithMovement(ctx context.Context, tx *gorm.DB, limitRepository repository.LimitRepository,
+	previous []entity.Limit, updated []entity.Limit, movement entity.LimitChange) error {
+	if err := limitRepository.BulkUpdateWithTransaction(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	changes := limitMovements(previous, updated, movement)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return limitRepository.BulkInsertChangeWithTransaction(ctx, tx, changes)
+}
+
+// limitMovements compare limits before and after update, movement fill the source of change
+func limitMovements(previous []entity.Limit, updated []entity.Limit, movement entity.LimitChange) []entity.LimitChange {
+	before := make(map[uint]entity.Limit, len(previous))
+	for _, limit := range previous {
+		before[limit.ID] = limit
+	}
+
+	changes := []entity.LimitChange{}
+	for _, limit := range updated {
+		previousLimit := before[limit.ID]
+		if previousLimit.CurrentLimit.Equal(limit.CurrentLimit) && previousLimit.OriginalLimit.Equal(limit.OriginalLimit) {
+			continue
+		}
+
+		change := movement
+		change.UserID = limit.UserID
+		change.LimitID = limit.ID
+		change.Tenor = limit.Tenor
+		change.PreviousOriginalLimit = previousLimit.OriginalLimit
+		change.PreviousCurrentLimit = previousLimit.CurrentLimit
+		change.OriginalLimit = limit.OriginalLimit
+		change.CurrentLimit = limit.CurrentLimit
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// checkLimitNotInUse refuse removing a limit still used by an open contract or checkout
+func (s *limitService) checkLimitNotInUse(ctx context.Context, limit *entity.Limit) *helpers.BaseResponse {
+	total, err := s.limitRepository.CountOpenUsage(ctx, limit.UserID, limit.Tenor)
+	if err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error getting user limit usage",
+			Errors:  err,
+		}
+	}
+
+	if total > 0 {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: fmt.Sprintf("User limit tenor %d is still used by an open contract or checkout", limit.Tenor),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// MerchantService manage the dealer paid for the asset of a contract, bank account
+// change only apply to disbursement sent afterward
+type MerchantService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.MerchantInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.MerchantInput, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+}
+
+type merchantService struct {
+	merchantRepository repository.MerchantRepository
+}
+
+func NewMerchantService(merchantRepository repository.MerchantRepository) MerchantService {
+	return &merchantService{
+		merchantRepository: merchantRepository,
+	}
+}
+
+func (s *merchantService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchant, err := s.merchantRepository.FindByUUID(ctx, uuid)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	merchantModel := model.MerchantToDetailModel(merchant)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Merchant data found",
+		Data:    merchantModel,
+	})
+}
+
+func (s *merchantService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchants, err := s.merchantRepository.FindAll(ctx, query)
+	if err != nil || merchants == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	merchantModels := model.MerchantToListModels(*merchants)
+
+	totalData := s.merchantRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Merchant data found",
+		Data:    merchantModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *merchantService) Create(ctx context.Context, input *model.MerchantInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchantEntity := input.ToEntity()
+
+	if err := s.validateEntityInput(ctx, merchantEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.merchantRepository.Insert(ctx, merchantEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Merchant successfully created",
+	})
+}
+
+func (s *merchantService) UpdateByUUID(ctx context.Context, input *model.MerchantInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchant, err := s.merchantRepository.FindByUUID(ctx, uuid)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	merchantEntity := input.ToEntity()
+	merchantEntity.ID = merchant.ID
+
+	if err := s.validateEntityInput(ctx, merchantEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.merchantRepository.Update(ctx, merchantEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Merchant successfully updated",
+	})
+}
+
+func (s *merchantService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchant, err := s.merchantRepository.FindByUUID(ctx, uuid)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.merchantRepository.Delete(ctx, merchant); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Merchant successfully deleted",
+	})
+}
+
+func (s *merchantService) validateEntityInput(ctx context.Context, merchant *entity.Merchant) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errs := []helpers.ValidationError{}
+
+	// Check code duplication
+	if exist := s.merchantRepository.CodeExist(ctx, merchant); exist {
+		errs = append(errs, helpers.ValidationError{
+			Field: "code",
+			Tag:   "duplicate",
+		})
+	}
+
+	if len(errs) != 0 {
+		logData.Message = "Validation error"
+		logData.Err = errs
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// PartnerService manage merchant API credential and customer consent, and serve the limit
+// lookup of partner request. Partner transaction is created by TransactionService
+type PartnerService interface {
+	IssueKey(ctx context.Context, input *model.PartnerKeyInput, merchant_uuid uuid.UUID) helpers.BaseResponse
+	GetKeys(ctx context.Context, merchant_uuid uuid.UUID) helpers.BaseResponse
+	RevokeKey(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GrantConsent(ctx context.Context, input *model.PartnerConsentInput) helpers.BaseResponse
+	GetConsents(ctx context.Context) helpers.BaseResponse
+	RevokeConsent(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetCustomerLimit(ctx context.Context, customer_uuid uuid.UUID) helpers.BaseResponse
+}
+
+type partnerService struct {
+	partnerKeyRepository     repository.PartnerKeyRepository
+	partnerConsentRepository repository.PartnerConsentRepository
+	merchantRepository       repository.MerchantRepository
+	userRepository           repository.UserRepository
+	limitRepository          repository.LimitRepository
+}
+
+func NewPartnerService(
+	partnerKeyRepository repository.PartnerKeyRepository,
+	partnerConsentRepository repository.PartnerConsentRepository,
+	merchantRepository repository.MerchantRepository,
+	userRepository repository.UserRepository,
+	limitRepository repository.LimitRepository,
+) PartnerService {
+	return &partnerService{
+		partnerKeyRepository:     partnerKeyRepository,
+		partnerConsentRepository: partnerConsentRepository,
+		merchantRepository:       merchantRepository,
+		userRepository:           userRepository,
+		limitRepository:          limitRepository,
+	}
+}
+
+// IssueKey create a credential for the merchant, the secret is only in this response
+func (s *partnerService) IssueKey(ctx context.Context, input *model.PartnerKeyInput, merchant_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchant, err := s.merchantRepository.FindByUUID(ctx, merchant_uuid)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	key_id, err := randomHex(12)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating partner key",
+		})
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating partner key",
+		})
+	}
+
+	secret_key, err := helpers.PartnerSecretKey()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Partner secret key is not configured",
+			Errors:  err.Error(),
+		})
+	}
+	secret_cipher, err := helpers.EncryptSecret(secret_key, secret)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating partner key",
+		})
+	}
+
+	key := &entity.PartnerKey{
+		MerchantID:   merchant.ID,
+		KeyID:        "pk_" + key_id,
+		SecretCipher: secret_cipher,
+		Name:         input.Name,
+	}
+	if err := s.partnerKeyRepository.Insert(ctx, key); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Partner key successfully created, the secret is only shown once",
+		Data: model.PartnerKeyCreated{
+			UUID:   key.UUID,
+			KeyID:  key.KeyID,
+			Secret: secret,
+			Name:   key.Name,
+		},
+	})
+}
+
+func (s *partnerService) GetKeys(ctx context.Context, merchant_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	merchant, err := s.merchantRepository.FindByUUID(ctx, merchant_uuid)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	keys, err := s.partnerKeyRepository.FindAllByMerchantID(ctx, merchant.ID)
+	if err != nil || keys == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Partner key not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Partner key data found",
+		Data:    model.PartnerKeyToListModels(*keys),
+	})
+}
+
+func (s *partnerService) RevokeKey(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	key, err := s.partnerKeyRepository.FindByUUID(ctx, uuid)
+	if err != nil || key == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Partner key not found",
+			Errors:  err,
+		})
+	}
+
+	if !key.IsActive() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Partner key already revoked",
+		})
+	}
+
+	if err := s.partnerKeyRepository.Revoke(ctx, key); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Partner key successfully revoked",
+	})
+}
+
+// GrantConsent let the merchant act for the session user
+func (s *partnerService) GrantConsent(ctx context.Context, input *model.PartnerConsentInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user_id := changedBy(ctx)
+	if user_id == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Missing user id",
+		})
+	}
+
+	merchant, err := s.merchantRepository.FindByCode(ctx, input.MerchantCode)
+	if err != nil || merchant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		})
+	}
+
+	if !merchant.IsActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Merchant is not active",
+		})
+	}
+
+	consent := &entity.PartnerConsent{MerchantID: merchant.ID, UserID: *user_id}
+	if err := s.partnerConsentRepository.Grant(ctx, consent); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Partner consent successfully granted",
+	})
+}
+
+func (s *partnerService) GetConsents(ctx context.Context) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user_id := changedBy(ctx)
+	if user_id == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Missing user id",
+		})
+	}
+
+	consents, err := s.partnerConsentRepository.FindAllByUserID(ctx, *user_id)
+	if err != nil || consents == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Partner consent not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Partner consent data found",
+		Data:    model.PartnerConsentToListModels(*consents),
+	})
+}
+
+func (s *partnerService) RevokeConsent(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	consent, err := s.partnerConsentRepository.FindByUUID(ctx, uuid)
+	if err != nil || consent == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Partner consent not found",
+			Errors:  err,
+		})
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, consent.UserID) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	if !consent.IsActive() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Partner consent already revoked",
+		})
+	}
+
+	if err := s.partnerConsentRepository.Revoke(ctx, consent); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Partner consent successfully revoked",
+	})
+}
+
+// GetCustomerLimit return the limit of a customer who consent to the partner
+func (s *partnerService) GetCustomerLimit(ctx context.Context, customer_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, errResponse := partnerCustomer(ctx, s.userRepository, s.partnerConsentRepository, customer_uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{Limit: "100"}, user.ID)
+	if err != nil || limits == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User limit Not Found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User limit data found",
+		Data:    model.LimitToPartnerModels(*limits),
+	})
+}
+
+// partnerCustomer find the customer of a partner request, the customer must have consented
+// to the partner merchant. Unknown customer and missing consent look the same to partner
+func partnerCustomer(ctx context.Context, userRepository repository.UserRepository,
+	partnerConsentRepository repository.PartnerConsentRepository, customer_uuid uuid.UUID) (*entity.User, *helpers.BaseResponse) {
+	partner_id, ok := ctx.Value(helpers.CtxKeyPartnerID).(uint)
+	if !ok || partner_id == 0 {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Missing partner id",
+		}
+	}
+
+	user, err := userRepository.FindByUUID(ctx, customer_uuid)
+	if err != nil || user == nil || !partnerConsentRepository.HasConsent(ctx, partner_id, user.ID) {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Customer has not given consent to this partner",
+		}
+	}
+
+	return user, nil
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -4,21 +4,33 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/allocation"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/limit"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type PaymentService interface {
 	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
 	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
 	Create(ctx context.Context, input *model.PaymentInput) helpers.BaseResponse
+	PayoffQuote(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	Payoff(ctx context.Context, input *model.PayoffInput, uuid uuid.UUID) helpers.BaseResponse
+	ApplyCredit(ctx context.Context, input *model.CreditApplyInput, uuid uuid.UUID) helpers.BaseResponse
+	Reverse(ctx context.Context, input *model.PaymentReversalInput, uuid uuid.UUID) helpers.BaseResponse
+	ReceiveCallback(ctx context.Context, input *model.PaymentCallbackInput, payload string) helpers.BaseResponse
 }
 
 type paymentService struct {
@@ -27,6 +39,9 @@ type paymentService struct {
 	transactionRepository repository.TransactionRepository
 	installmentRepository repository.InstallmentRepository
 	limitRepository       repository.LimitRepository
+	penaltyRepository     repository.PenaltyRepository
+	callbackRepository    repository.PaymentCallbackRepository
+	ledgerRepository      repository.LedgerRepository
 	lockRedis             *redis.LockClient
 }
 
@@ -36,6 +51,9 @@ func NewPaymentService(
 	transactionRepository repository.TransactionRepository,
 	installmentRepository repository.InstallmentRepository,
 	limitRepository repository.LimitRepository,
+	penaltyRepository repository.PenaltyRepository,
+	callbackRepository repository.PaymentCallbackRepository,
+	ledgerRepository repository.LedgerRepository,
 	lockRedis *redis.LockClient,
 ) PaymentService {
 	return &paymentService{
@@ -44,6 +62,9 @@ func NewPaymentService(
 		transactionRepository: transactionRepository,
 		installmentRepository: installmentRepository,
 		limitRepository:       limitRepository,
+		penaltyRepository:     penaltyRepository,
+		callbackRepository:    callbackRepository,
+		ledgerRepository:      ledgerRepository,
 		lockRedis:             lockRedis,
 	}
 }
@@ -121,6 +142,8 @@ func (s *paymentService) GetAll(ctx context.Context, query *model.QueryGet, url
 	})
 }
 
+// Create allocate a payment over unpaid installments of a transaction with the
+// allocation waterfall, any amount left is kept as customer credit
 func (s *paymentService) Create(ctx context.Context, input *model.PaymentInput) helpers.BaseResponse {
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -134,52 +157,101 @@ func (s *paymentService) Create(ctx context.Context, input *model.PaymentInput)
 		})
 	}
 
-	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
-	if session_user_id == 0 || !ok {
+	if !paymentEntity.Amount.IsPositive() {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
-			Message: "Missing user id",
+			Message: "Payment amount must be greater than zero",
 		})
 	}
 
-	user, err := s.userRepository.FindByID(ctx, uint(session_user_id))
-	if err != nil || user == nil {
+	transaction_uuid, err := uuid.Parse(input.TransactionUUID)
+	if err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
-			Message: "User Not Found",
+			Message: "Invalid UUID Format",
 			Errors:  err,
 		})
 	}
 
-	installment, err := s.installmentRepository.FindByID(ctx, paymentEntity.InstallmentID)
-	if err != nil || installment == nil || installment.PaymentStatus == entity.PaymentStatusFailed {
+	transaction, installments, errResponse := s.findActiveTransaction(ctx, transaction_uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	if errResponse := s.allocatePayment(ctx, transaction, installments, paymentEntity, nil); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	paymentEntity.Transaction = *transaction
+	paymentModel := model.PaymentToDetailModel(paymentEntity)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Payment succesffully created",
+		Data:    paymentModel,
+	})
+}
+
+func (s *paymentService) PayoffQuote(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	transaction, installments, errResponse := s.findActiveTransaction(ctx, uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	payoff := calculatePayoff(installments, time.Now())
+	quoteModel := payoffToModel(payoff, transaction)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Payoff quote generated",
+		Data:    quoteModel,
+	})
+}
+
+// Payoff settle every remaining installment of the transaction with one payment,
+// then mark the transaction paid and restore user limit
+func (s *paymentService) Payoff(ctx context.Context, input *model.PayoffInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	amount, err := decimal.NewFromString(input.Amount)
+	if err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Installment Not Found",
-			Errors:  err,
+			Message: "Failed parsing input",
 		})
 	}
 
-	transaction, err := s.transactionRepository.FindByID(ctx, installment.TransactionID)
-	if err != nil || transaction == nil {
+	transaction, installments, errResponse := s.findActiveTransaction(ctx, uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	lock_ttl := 10 * time.Second
+
+	release, err := s.lockTransaction(ctx, transaction, installments)
+	if err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Transaction Not Found",
+			Message: "failed to acquire lock",
 			Errors:  err,
 		})
 	}
+	defer release()
 
-	paymentEntity.TransactionID = transaction.ID
-
-	// Lock installment
-	installment_lock_name := fmt.Sprintf("lock:installment:%s", installment.UUID)
-	lock_ttl := 10 * time.Second
-	acquireinstallment, err := s.lockRedis.AcquireLock(ctx, installment_lock_name, lock_ttl)
-	if !acquireinstallment || err != nil {
+	// Lock limit
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
@@ -187,44 +259,308 @@ func (s *paymentService) Create(ctx context.Context, input *model.PaymentInput)
 			Errors:  err,
 		})
 	}
-	defer s.lockRedis.ReleaseLock(ctx, installment_lock_name)
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	// Read again under the lock, a payment may have settled installments meanwhile
+	transaction, installments, errResponse = s.findActiveTransaction(ctx, uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	payoff := calculatePayoff(installments, time.Now())
+	if !payoff.Covered(amount) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Payment amount is less than payoff amount",
+			Errors:  payoffToModel(payoff, transaction),
+		})
+	}
 
 	tx := s.paymentRepository.BeginTransaction(ctx)
 	defer tx.Rollback()
 
+	paymentEntity := &entity.Payment{
+		TransactionID: transaction.ID,
+		InstallmentID: installments[0].ID,
+		Amount:        amount,
+		PaymentMethod: input.PaymentMethod,
+	}
 	if err := s.paymentRepository.InsertWithTransaction(ctx, tx, paymentEntity); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Error creating transaction data",
+			Message: "Error creating payment data",
 			Errors:  logData.Err,
 		})
 	}
 
-	newAmountPaid := installment.AmountPaid.Add(paymentEntity.Amount)
-	if newAmountPaid.GreaterThanOrEqual(installment.AmountDue) {
-		installment.AmountPaid = installment.AmountDue
-		installment.PaymentStatus = entity.PaymentStatusPaid
-		installment.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
-	} else {
-		installment.AmountPaid = newAmountPaid
-		installment.PaymentStatus = entity.PaymentStatusPartial
+	if err := s.applyAllocation(ctx, tx, paymentEntity, installments, payoff.Lines); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error allocating payment",
+			Errors:  logData.Err,
+		})
 	}
 
-	if err := s.installmentRepository.UpdateWithTransaction(ctx, tx, installment); err != nil {
+	// Amount above the payoff is kept as customer credit
+	if excess := payoff.Excess(amount); excess.IsPositive() {
+		paymentEntity.Credit = &entity.CustomerCredit{
+			UserID:        transaction.UserID,
+			TransactionID: transaction.ID,
+			PaymentID:     paymentEntity.ID,
+			Amount:        excess,
+		}
+		if err := s.paymentRepository.InsertCreditWithTransaction(ctx, tx, paymentEntity.Credit); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error creating credit data",
+				Errors:  logData.Err,
+			})
+		}
+	}
+
+	// Termination fee is what is left outside allocation and credit
+	if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, ledger.Payment(paymentEntity)); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Error updating installments data",
+			Message: "Error posting ledger entry",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionPaid,
+		fmt.Sprintf("Contract %s paid off", transaction.ContractNumber)); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
 			Errors:  logData.Err,
 		})
 	}
 
-	if transaction.Tenor == installment.InstallmentNumber && installment.PaymentStatus == entity.PaymentStatusPaid {
+	movement := transactionLimitMovement(ctx, transaction, entity.LimitPayoff,
+		fmt.Sprintf("Contract %s paid off", transaction.ContractNumber))
+	if err := s.restoreUserLimit(ctx, tx, transaction, movement); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+			Errors:  logData.Err,
+		})
+	}
+
+	tx.Commit()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Transaction successfully paid off",
+		Data:    payoffToModel(payoff, transaction),
+	})
+}
+
+// ApplyCredit pay an active transaction of the same user from customer credit, up to what
+// is left to settle it. The payment is allocated like a customer payment and the used
+// part of the credit is raised in the same database transaction
+func (s *paymentService) ApplyCredit(ctx context.Context, input *model.CreditApplyInput, credit_uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	credit, err := s.paymentRepository.FindCreditByUUID(ctx, credit_uuid)
+	if err != nil || credit == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Credit Not Found",
+			Errors:  err,
+		})
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, credit.UserID) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	if !credit.Available().IsPositive() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Credit already used",
+		})
+	}
+
+	transaction_uuid, err := uuid.Parse(input.TransactionUUID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID Format",
+			Errors:  err,
+		})
+	}
+
+	transaction, installments, errResponse := s.findActiveTransaction(ctx, transaction_uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	if transaction.UserID != credit.UserID {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Credit belongs to another user",
+		})
+	}
+
+	paymentEntity := &entity.Payment{
+		Amount:         decimal.Min(credit.Available(), allocation.Outstanding(installments)),
+		PaymentMethod:  entity.PaymentMethodCustomerCredit,
+		CreditSourceID: &credit.ID,
+	}
+
+	useCredit := func(tx *gorm.DB, payment *entity.Payment) error {
+		return s.paymentRepository.UseCreditWithTransaction(ctx, tx, credit.ID, payment.Amount)
+	}
+	if errResponse := s.allocatePayment(ctx, transaction, installments, paymentEntity, useCredit); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	paymentEntity.Transaction = *transaction
+	paymentModel := model.PaymentToDetailModel(paymentEntity)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Credit successfully applied",
+		Data:    paymentModel,
+	})
+}
+
+// Reverse undo a payment that bounced or was posted by mistake. The payment is kept and
+// marked reversed, a reversal payment with negative amount takes back what it settled,
+// and a paid transaction is reopened to active with its restored limit taken back.
+// Only admin can reverse a payment
+func (s *paymentService) Reverse(ctx context.Context, input *model.PaymentReversalInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if is_admin, _ := ctx.Value(helpers.CtxKeyIsAdmin).(bool); !is_admin {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	payment, err := s.paymentRepository.FindByUUID(ctx, uuid)
+	if err != nil || payment == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Payment Not Found",
+			Errors:  err,
+		})
+	}
+
+	allocations := payment.Allocations
+	if len(allocations) == 0 {
+		// Payment recorded before allocation settled one installment only
+		allocations = []entity.PaymentAllocation{{
+			InstallmentID:   payment.InstallmentID,
+			PenaltyAmount:   decimal.Zero,
+			InterestAmount:  decimal.Zero,
+			PrincipalAmount: payment.Amount,
+			Amount:          payment.Amount,
+			Installment:     payment.Installment,
+		}}
+	}
+
+	lockInstallments := make([]entity.TransactionInstallment, len(allocations))
+	for i, allocation := range allocations {
+		lockInstallments[i] = allocation.Installment
+	}
+
+	release, err := s.lockTransaction(ctx, &payment.Transaction, lockInstallments)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer release()
+
+	// Reload under lock so a concurrent reversal or payment is seen
+	payment, err = s.paymentRepository.FindByUUID(ctx, uuid)
+	if err != nil || payment == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Payment Not Found",
+			Errors:  err,
+		})
+	}
+
+	if payment.Type == entity.PaymentTypeReversal || payment.ReversedAt.Valid {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Payment already reversed or is a reversal",
+		})
+	}
+
+	if payment.Credit != nil && payment.Credit.AmountUsed.IsPositive() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Payment credit already used",
+		})
+	}
+
+	transaction, err := s.transactionRepository.FindByID(ctx, payment.TransactionID)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status == entity.TransactionCanceled {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction already cancelled",
+		})
+	}
+
+	installmentByID := map[uint]*entity.TransactionInstallment{}
+	for i := range transaction.Installments {
+		installmentByID[transaction.Installments[i].ID] = &transaction.Installments[i]
+	}
+	for _, allocation := range allocations {
+		if _, ok := installmentByID[allocation.InstallmentID]; !ok {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "Installment Not Found",
+			})
+		}
+	}
+
+	is_reopen := transaction.Status == entity.TransactionPaid
+	if is_reopen {
 		// Lock limit
-		user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
-		lock_ttl := 10 * time.Second
-		acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+		user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+		acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, 10*time.Second)
 		if !acquireUserLimit || err != nil {
 			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 				Status:  fiber.StatusInternalServerError,
@@ -234,58 +570,652 @@ func (s *paymentService) Create(ctx context.Context, input *model.PaymentInput)
 			})
 		}
 		defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+	}
+
+	now := time.Now()
+
+	tx := s.paymentRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	reversal := &entity.Payment{
+		TransactionID: payment.TransactionID,
+		InstallmentID: payment.InstallmentID,
+		Amount:        payment.Amount.Neg(),
+		PaymentMethod: payment.PaymentMethod,
+		Type:          entity.PaymentTypeReversal,
+		ReversalOfID:  &payment.ID,
+		Reason:        input.Reason,
+	}
+	if err := s.paymentRepository.InsertWithTransaction(ctx, tx, reversal); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating payment data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.paymentRepository.UpdateWithTransaction(ctx, tx, &entity.Payment{
+		ID:         payment.ID,
+		ReversedAt: sql.NullTime{Time: now, Valid: true},
+	}); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating payment data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.reverseAllocation(ctx, tx, reversal, installmentByID, allocations, now); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error reversing payment allocation",
+			Errors:  logData.Err,
+		})
+	}
 
-		transaction.Status = entity.TransactionPaid
-		if err := s.transactionRepository.UpdateWithTransaction(ctx, tx, transaction); err != nil {
+	original := *payment
+	original.Allocations = allocations
+	entry := ledger.Reverse(ledger.Payment(&original), ledger.SourcePayment, reversal.ID, fmt.Sprintf("Reversal of payment %s", payment.UUID))
+	if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, entry); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error posting ledger entry",
+			Errors:  logData.Err,
+		})
+	}
+
+	if payment.Credit != nil {
+		if err := s.paymentRepository.DeleteCreditWithTransaction(ctx, tx, payment.Credit); err != nil {
 			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 				Status:  fiber.StatusInternalServerError,
 				Success: false,
-				Message: "Error updating installments data",
+				Message: "Error deleting credit data",
 				Errors:  logData.Err,
 			})
 		}
+	}
 
-		limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{Limit: "100"}, user.ID)
-		if err != nil || limits == nil {
+	// Payment drawn from customer credit give the credit back
+	if payment.CreditSourceID != nil {
+		if err := s.paymentRepository.ReleaseCreditWithTransaction(ctx, tx, *payment.CreditSourceID, payment.Amount); err != nil {
 			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 				Status:  fiber.StatusInternalServerError,
 				Success: false,
-				Message: "User limit not found",
+				Message: "Error updating credit data",
 				Errors:  logData.Err,
 			})
 		}
-		updatedLimits := make([]entity.Limit, len(*limits))
-
-		for i, limit := range *limits {
-			newLimit := limit.CurrentLimit.Add(transaction.OnTheRoad)
-			if newLimit.GreaterThan(limit.OriginalLimit) {
-				newLimit = limit.OriginalLimit
-			}
+	}
 
-			updatedLimits[i] = entity.Limit{
-				ID:            limit.ID,
-				UserID:        limit.UserID,
-				OriginalLimit: limit.OriginalLimit,
-				CurrentLimit:  newLimit,
-				Tenor:         limit.Tenor,
-				UpdatedAt:     time.Now(),
-			}
+	if is_reopen {
+		if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionActive,
+			fmt.Sprintf("Contract %s reopened by payment reversal", transaction.ContractNumber)); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  transitionErrorStatus(err),
+				Success: false,
+				Message: "Error updating transaction data",
+				Errors:  logData.Err,
+			})
 		}
 
-		if err := s.limitRepository.BulkUpdateWithTransaction(ctx, tx, updatedLimits); err != nil {
+		movement := transactionLimitMovement(ctx, transaction, entity.LimitPaymentReversal,
+			fmt.Sprintf("Contract %s reopened by payment reversal", transaction.ContractNumber))
+		if err := s.takeBackUserLimit(ctx, tx, transaction, movement); err != nil {
 			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 				Status:  fiber.StatusInternalServerError,
 				Success: false,
-				Message: "Error updating installments data",
+				Message: "Error updating limits data",
 				Errors:  logData.Err,
 			})
 		}
 	}
 
 	tx.Commit()
+
+	reversal.Transaction = *transaction
+	reversal.Installment = *installmentByID[reversal.InstallmentID]
+	paymentModel := model.PaymentToDetailModel(reversal)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusCreated,
 		Success: true,
-		Message: "Payment succesffully created",
+		Message: "Payment successfully reversed",
+		Data:    paymentModel,
 	})
 }
+
+// allocatePayment post a payment over unpaid installments of an active transaction with
+// the allocation waterfall, any amount left is kept as customer credit. record, when not
+// nil, is run inside the same database transaction before commit
+func (s *paymentService) allocatePayment(ctx context.Context, transaction *entity.Transaction, installments []entity.TransactionInstallment, paymentEntity *entity.Payment, record func(tx *gorm.DB, payment *entity.Payment) error) *helpers.BaseResponse {
+	release, err := s.lockTransaction(ctx, transaction, installments)
+	if err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		}
+	}
+	defer release()
+
+	// Reload under lock so penalty accrued in the meantime is allocated too
+	unpaidInstallments, err := s.installmentRepository.FindAllUnpaidByTransactionID(ctx, transaction.ID)
+	if err != nil || unpaidInstallments == nil || len(*unpaidInstallments) == 0 {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Unpaid installment Not Found",
+			Errors:  err,
+		}
+	}
+	installments = *unpaidInstallments
+
+	result := allocation.Allocate(installments, paymentEntity.Amount)
+
+	fullyPaid := true
+	settled := map[uint]bool{}
+	for _, line := range result.Lines {
+		settled[line.InstallmentID] = line.Settled
+	}
+	for _, installment := range installments {
+		if !settled[installment.ID] {
+			fullyPaid = false
+			break
+		}
+	}
+
+	if fullyPaid {
+		// Lock limit
+		user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+		acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, 10*time.Second)
+		if !acquireUserLimit || err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "failed to acquire lock",
+				Errors:  err,
+			}
+		}
+		defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+	}
+
+	tx := s.paymentRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	paymentEntity.TransactionID = transaction.ID
+	paymentEntity.InstallmentID = result.Lines[0].InstallmentID
+	if err := s.paymentRepository.InsertWithTransaction(ctx, tx, paymentEntity); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating payment data",
+			Errors:  err,
+		}
+	}
+
+	if err := s.applyAllocation(ctx, tx, paymentEntity, installments, result.Lines); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error allocating payment",
+			Errors:  err,
+		}
+	}
+
+	if result.Credit.IsPositive() {
+		paymentEntity.Credit = &entity.CustomerCredit{
+			UserID:        transaction.UserID,
+			TransactionID: transaction.ID,
+			PaymentID:     paymentEntity.ID,
+			Amount:        result.Credit,
+		}
+		if err := s.paymentRepository.InsertCreditWithTransaction(ctx, tx, paymentEntity.Credit); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error creating credit data",
+				Errors:  err,
+			}
+		}
+	}
+
+	if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, ledger.Payment(paymentEntity)); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error posting ledger entry",
+			Errors:  err,
+		}
+	}
+
+	if fullyPaid {
+		if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionPaid,
+			fmt.Sprintf("Contract %s fully paid", transaction.ContractNumber)); err != nil {
+			return &helpers.BaseResponse{
+				Status:  transitionErrorStatus(err),
+				Success: false,
+				Message: "Error updating transaction data",
+				Errors:  err,
+			}
+		}
+
+		movement := transactionLimitMovement(ctx, transaction, entity.LimitTransactionPaid,
+			fmt.Sprintf("Contract %s fully paid", transaction.ContractNumber))
+		if err := s.restoreUserLimit(ctx, tx, transaction, movement); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error updating limits data",
+				Errors:  err,
+			}
+		}
+	}
+
+	if record != nil {
+		if err := record(tx, paymentEntity); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error recording payment",
+				Errors:  err,
+			}
+		}
+	}
+
+	tx.Commit()
+
+	return nil
+}
+
+// ReceiveCallback post a payment gateway callback through the same allocation as Create.
+// Signature is already verified by middleware. Callback received before is answered with
+// its first result, and a paid callback that can not be posted is kept as rejected so the
+// gateway stop retrying while it is followed up manually
+func (s *paymentService) ReceiveCallback(ctx context.Context, input *model.PaymentCallbackInput, payload string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	amount, err := decimal.NewFromString(input.Amount)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if callback, _ := s.callbackRepository.FindByProviderTransactionID(ctx, input.Provider, input.ProviderTransactionID); callback != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusOK,
+			Success: true,
+			Message: "Callback already received",
+			Data:    model.PaymentCallbackToDetailModel(callback),
+		})
+	}
+
+	callback := &entity.PaymentCallback{
+		Provider:              input.Provider,
+		ProviderTransactionID: input.ProviderTransactionID,
+		Event:                 entity.PaymentCallbackEvent(input.Event),
+		Amount:                amount,
+		Payload:               payload,
+	}
+
+	if callback.Event == entity.PaymentCallbackExpired {
+		callback.Status = entity.PaymentCallbackIgnored
+		callback.Message = "Payment expired"
+		return helpers.LogBaseResponse(&logData, s.recordCallback(ctx, callback))
+	}
+
+	transaction, installments, errResponse := s.findCallbackTransaction(ctx, input)
+	if errResponse == nil && !amount.IsPositive() {
+		errResponse = &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Payment amount must be greater than zero",
+		}
+	}
+
+	if errResponse == nil {
+		paymentEntity := &entity.Payment{
+			Amount:        amount,
+			PaymentMethod: fmt.Sprintf("%s_%s", input.Provider, strings.TrimSuffix(input.Event, ".paid")),
+		}
+
+		errResponse = s.allocatePayment(ctx, transaction, installments, paymentEntity, func(tx *gorm.DB, payment *entity.Payment) error {
+			callback.Status = entity.PaymentCallbackProcessed
+			callback.Message = "Payment posted"
+			callback.TransactionID = &payment.TransactionID
+			callback.PaymentID = &payment.ID
+			return s.callbackRepository.InsertWithTransaction(ctx, tx, callback)
+		})
+		if errResponse == nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusOK,
+				Success: true,
+				Message: "Callback processed",
+				Data:    model.PaymentCallbackToDetailModel(callback),
+			})
+		}
+	}
+
+	// Server error is returned so the gateway retry the callback later
+	if errResponse.Status >= fiber.StatusInternalServerError {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	callback.Status = entity.PaymentCallbackRejected
+	callback.Message = errResponse.Message
+	callback.TransactionID = nil
+	callback.PaymentID = nil
+	if transaction != nil {
+		callback.TransactionID = &transaction.ID
+	}
+
+	return helpers.LogBaseResponse(&logData, s.recordCallback(ctx, callback))
+}
+
+// findCallbackTransaction resolve the transaction referenced by a gateway callback,
+// without session ownership check since the callback is not sent by the customer
+func (s *paymentService) findCallbackTransaction(ctx context.Context, input *model.PaymentCallbackInput) (*entity.Transaction, []entity.TransactionInstallment, *helpers.BaseResponse) {
+	var transaction *entity.Transaction
+
+	if input.InstallmentUUID != "" {
+		installment_uuid, _ := uuid.Parse(input.InstallmentUUID)
+		installment, err := s.installmentRepository.FindByUUID(ctx, installment_uuid)
+		if err != nil || installment == nil {
+			return nil, nil, &helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "Installment Not Found",
+				Errors:  err,
+			}
+		}
+
+		transaction, err = s.transactionRepository.FindByID(ctx, installment.TransactionID)
+		if err != nil || transaction == nil {
+			return nil, nil, &helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "Transaction Not Found",
+				Errors:  err,
+			}
+		}
+	} else {
+		transaction_uuid, _ := uuid.Parse(input.TransactionUUID)
+		var err error
+		transaction, err = s.transactionRepository.FindByUUID(ctx, transaction_uuid)
+		if err != nil || transaction == nil {
+			return nil, nil, &helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "Transaction Not Found",
+				Errors:  err,
+			}
+		}
+	}
+
+	_, installments, errResponse := s.findUnpaidInstallments(ctx, transaction)
+	return transaction, installments, errResponse
+}
+
+// recordCallback store a callback that is not posted as payment
+func (s *paymentService) recordCallback(ctx context.Context, callback *entity.PaymentCallback) helpers.BaseResponse {
+	if err := s.callbackRepository.Insert(ctx, callback); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating callback data",
+			Errors:  err,
+		}
+	}
+
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: fmt.Sprintf("Callback %s", callback.Status),
+		Data:    model.PaymentCallbackToDetailModel(callback),
+	}
+}
+
+func (s *paymentService) findActiveTransaction(ctx context.Context, uuid uuid.UUID) (*entity.Transaction, []entity.TransactionInstallment, *helpers.BaseResponse) {
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return nil, nil, &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		}
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, transaction.UserID) {
+		return nil, nil, &helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		}
+	}
+
+	return s.findUnpaidInstallments(ctx, transaction)
+}
+
+// findUnpaidInstallments check the transaction is still active and return its unpaid installments
+func (s *paymentService) findUnpaidInstallments(ctx context.Context, transaction *entity.Transaction) (*entity.Transaction, []entity.TransactionInstallment, *helpers.BaseResponse) {
+	if !transaction.Status.IsRepaying() {
+		return nil, nil, &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction already paid or already cancelled",
+		}
+	}
+
+	installments, err := s.installmentRepository.FindAllUnpaidByTransactionID(ctx, transaction.ID)
+	if err != nil || installments == nil || len(*installments) == 0 {
+		return nil, nil, &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Unpaid installment Not Found",
+			Errors:  err,
+		}
+	}
+
+	return transaction, *installments, nil
+}
+
+// lockTransaction lock the transaction and every given installment, release
+// must be called once the caller is done
+func (s *paymentService) lockTransaction(ctx context.Context, transaction *entity.Transaction, installments []entity.TransactionInstallment) (release func(), err error) {
+	lock_ttl := 10 * time.Second
+	lock_names := []string{}
+
+	release = func() {
+		for _, lock_name := range lock_names {
+			s.lockRedis.ReleaseLock(ctx, lock_name)
+		}
+	}
+
+	names := []string{fmt.Sprintf("lock:transaction:%s", transaction.UUID)}
+	for _, installment := range installments {
+		names = append(names, fmt.Sprintf("lock:installment:%s", installment.UUID))
+	}
+
+	for _, lock_name := range names {
+		acquired, err := s.lockRedis.AcquireLock(ctx, lock_name, lock_ttl)
+		if !acquired || err != nil {
+			release()
+			if err == nil {
+				err = fmt.Errorf("%s is locked", lock_name)
+			}
+			return nil, err
+		}
+		lock_names = append(lock_names, lock_name)
+	}
+
+	return release, nil
+}
+
+// applyAllocation write allocation lines of a payment: settle penalties, update
+// installments and store one allocation row per installment touched
+func (s *paymentService) applyAllocation(ctx context.Context, tx *gorm.DB, payment *entity.Payment, installments []entity.TransactionInstallment, lines []allocation.Line) error {
+	now := time.Now()
+	installmentByID := map[uint]*entity.TransactionInstallment{}
+	penaltyByID := map[uint]*entity.InstallmentPenalty{}
+	for i := range installments {
+		installmentByID[installments[i].ID] = &installments[i]
+		for j := range installments[i].Penalties {
+			penaltyByID[installments[i].Penalties[j].ID] = &installments[i].Penalties[j]
+		}
+	}
+
+	allocations := make([]entity.PaymentAllocation, len(lines))
+	for i, line := range lines {
+		for _, settlement := range line.Penalties {
+			penalty := penaltyByID[settlement.PenaltyID]
+			penalty.AmountPaid = penalty.AmountPaid.Add(settlement.Amount)
+			if !penalty.Unpaid().IsPositive() {
+				penalty.PaidAt = sql.NullTime{Time: now, Valid: true}
+			}
+
+			if err := s.penaltyRepository.UpdateWithTransaction(ctx, tx, penalty); err != nil {
+				return err
+			}
+		}
+
+		installment := installmentByID[line.InstallmentID]
+		installment.AmountPaid = installment.AmountPaid.Add(line.Interest).Add(line.Principal)
+		if line.Settled {
+			installment.PaymentStatus = entity.PaymentStatusPaid
+			installment.PaidAt = sql.NullTime{Time: now, Valid: true}
+		} else if installment.PaymentStatus != entity.PaymentStatusOverdue {
+			installment.PaymentStatus = entity.PaymentStatusPartial
+		}
+
+		// Penalties are already updated above
+		penalties := installment.Penalties
+		installment.Penalties = nil
+		err := s.installmentRepository.UpdateWithTransaction(ctx, tx, installment)
+		installment.Penalties = penalties
+		if err != nil {
+			return err
+		}
+
+		allocations[i] = entity.PaymentAllocation{
+			PaymentID:       payment.ID,
+			InstallmentID:   line.InstallmentID,
+			PenaltyAmount:   line.Penalty,
+			InterestAmount:  line.Interest,
+			PrincipalAmount: line.Principal,
+			Amount:          line.Amount,
+		}
+	}
+
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	if err := s.paymentRepository.BulkInsertAllocationWithTransaction(ctx, tx, allocations); err != nil {
+		return err
+	}
+
+	for i := range allocations {
+		allocations[i].Installment.InstallmentNumber = installmentByID[allocations[i].InstallmentID].InstallmentNumber
+	}
+	payment.Allocations = allocations
+
+	return nil
+}
+
+// reverseAllocation take back what the allocation rows of a payment settled. Penalties
+// accrued last are reopened first, status of every installment is recomputed, and the
+// reversal get negative allocation rows
+func (s *paymentService) reverseAllocation(ctx context.Context, tx *gorm.DB, reversal *entity.Payment, installmentByID map[uint]*entity.TransactionInstallment, allocations []entity.PaymentAllocation, now time.Time) error {
+	reversed := make([]entity.PaymentAllocation, len(allocations))
+	for i, line := range allocations {
+		installment := installmentByID[line.InstallmentID]
+
+		for _, penalty := range allocation.Reverse(installment, line, now) {
+			if err := s.penaltyRepository.UpdatePaymentWithTransaction(ctx, tx, penalty); err != nil {
+				return err
+			}
+		}
+
+		if err := s.installmentRepository.UpdatePaymentWithTransaction(ctx, tx, installment); err != nil {
+			return err
+		}
+
+		reversed[i] = entity.PaymentAllocation{
+			PaymentID:       reversal.ID,
+			InstallmentID:   line.InstallmentID,
+			PenaltyAmount:   line.PenaltyAmount.Neg(),
+			InterestAmount:  line.InterestAmount.Neg(),
+			PrincipalAmount: line.PrincipalAmount.Neg(),
+			Amount:          line.Amount.Neg(),
+		}
+	}
+
+	if err := s.paymentRepository.BulkInsertAllocationWithTransaction(ctx, tx, reversed); err != nil {
+		return err
+	}
+
+	for i := range reversed {
+		reversed[i].Installment.InstallmentNumber = installmentByID[reversed[i].InstallmentID].InstallmentNumber
+	}
+	reversal.Allocations = reversed
+
+	return nil
+}
+
+// restoreUserLimit give back otr to the limits consumed under the transaction pool mode,
+// capped at original limit
+func (s *paymentService) restoreUserLimit(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, movement entity.LimitChange) error {
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{Limit: "100"}, transaction.UserID)
+	if err != nil || limits == nil {
+		return fmt.Errorf("user limit not found")
+	}
+	updatedLimits, err := limit.Apply(*limits, transaction.Tenor, transaction.LimitPoolMode, transaction.OnTheRoad, false)
+	if err != nil {
+		return err
+	}
+
+	return updateLimitsWithMovement(ctx, tx, s.limitRepository, *limits, updatedLimits, movement)
+}
+
+// takeBackUserLimit use otr again from the limits consumed under the transaction pool mode
+// when a paid transaction is reopened, floored at zero since the contract is already running
+func (s *paymentService) takeBackUserLimit(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, movement entity.LimitChange) error {
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{Limit: "100"}, transaction.UserID)
+	if err != nil || limits == nil {
+		return fmt.Errorf("user limit not found")
+	}
+	updatedLimits := limit.TakeBack(*limits, transaction.Tenor, transaction.LimitPoolMode, transaction.OnTheRoad)
+
+	return updateLimitsWithMovement(ctx, tx, s.limitRepository, *limits, updatedLimits, movement)
+}
+
+// calculatePayoff quote early settlement with the configured PAYOFF_INTEREST_REBATE and
+// PAYOFF_FEE_RATE percent
+func calculatePayoff(installments []entity.TransactionInstallment, now time.Time) allocation.Payoff {
+	return allocation.QuotePayoff(installments, now,
+		decimal.NewFromFloat(config.AppConfig.PayoffInterestRebate), decimal.NewFromFloat(config.AppConfig.PayoffFeeRate))
+}
+
+func payoffToModel(p allocation.Payoff, transaction *entity.Transaction) *model.PayoffQuote {
+	return &model.PayoffQuote{
+		TransactionUUID:      transaction.UUID,
+		ContractNumber:       transaction.ContractNumber,
+		RemainingInstallment: len(p.Lines),
+		RemainingPrincipal:   p.RemainingPrincipal,
+		AccruedInterest:      p.AccruedInterest,
+		InterestRebate:       p.InterestRebate,
+		OutstandingPenalty:   p.OutstandingPenalty,
+		TerminationFee:       p.TerminationFee,
+		TotalPayoff:          p.TotalPayoff,
+		QuotedAt:             time.Now(),
+	}
+}
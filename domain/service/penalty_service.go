@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+)
+
+type PenaltyService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.PenaltyPolicyInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.PenaltyPolicyInput, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	AccruePenalties(ctx context.Context, as_of time.Time) (int64, error)
+}
+
+type penaltyService struct {
+	penaltyPolicyRepository repository.PenaltyPolicyRepository
+	penaltyRepository       repository.PenaltyRepository
+	installmentRepository   repository.InstallmentRepository
+	lockRedis               *redis.LockClient
+}
+
+func NewPenaltyService(
+	penaltyPolicyRepository repository.PenaltyPolicyRepository,
+	penaltyRepository repository.PenaltyRepository,
+	installmentRepository repository.InstallmentRepository,
+	lockRedis *redis.LockClient,
+) PenaltyService {
+	return &penaltyService{
+		penaltyPolicyRepository: penaltyPolicyRepository,
+		penaltyRepository:       penaltyRepository,
+		installmentRepository:   installmentRepository,
+		lockRedis:               lockRedis,
+	}
+}
+
+func (s *penaltyService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.penaltyPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Penalty policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyModel := model.PenaltyPolicyToDetailModel(policy)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Penalty policy data found",
+		Data:    policyModel,
+	})
+}
+
+func (s *penaltyService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policies, err := s.penaltyPolicyRepository.FindAll(ctx, query)
+	if err != nil || policies == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Penalty policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyModels := model.PenaltyPolicyToListModels(*policies)
+
+	totalData := s.penaltyPolicyRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Penalty policy data found",
+		Data:    policyModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *penaltyService) Create(ctx context.Context, input *model.PenaltyPolicyInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policyEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if err := s.validateEntityInput(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.penaltyPolicyRepository.Insert(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Penalty policy successfully created",
+	})
+}
+
+func (s *penaltyService) UpdateByUUID(ctx context.Context, input *model.PenaltyPolicyInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.penaltyPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Penalty policy not found",
+			Errors:  err,
+		})
+	}
+
+	policyEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+	policyEntity.ID = policy.ID
+
+	if err := s.validateEntityInput(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.penaltyPolicyRepository.Update(ctx, policyEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Penalty policy successfully updated",
+	})
+}
+
+func (s *penaltyService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policy, err := s.penaltyPolicyRepository.FindByUUID(ctx, uuid)
+	if err != nil || policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Penalty policy not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.penaltyPolicyRepository.Delete(ctx, policy); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Penalty policy successfully deleted",
+	})
+}
+
+// AccruePenalties is run by the overdue worker after installments are marked overdue.
+// Installment locked by a running payment is skipped, daily penalty catch up the
+// missed days on the next run.
+func (s *penaltyService) AccruePenalties(ctx context.Context, as_of time.Time) (int64, error) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	policies, err := s.penaltyPolicyRepository.FindAllActive(ctx)
+	if err != nil {
+		logData.Message = "Failed fetching penalty policies"
+		logData.Err = err.Error()
+		return 0, err
+	}
+	if len(*policies) == 0 {
+		logData.Message = "No active penalty policy"
+		return 0, nil
+	}
+
+	installments, err := s.installmentRepository.FindAllOverdue(ctx)
+	if err != nil {
+		logData.Message = "Failed fetching overdue installments"
+		logData.Err = err.Error()
+		return 0, err
+	}
+
+	// One failing installment does not stop the others, it is retried on the next run
+	var total int64
+	errs := []string{}
+	for _, installment := range *installments {
+		accrued, err := s.accrueInstallment(ctx, &installment, *policies, as_of)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("installment %s: %s", installment.UUID, err.Error()))
+			continue
+		}
+		total += accrued
+	}
+	if len(errs) != 0 {
+		logData.Err = errs
+	}
+
+	logData.Message = fmt.Sprintf("%d penalties accrued as of %s, %d installments failed", total, as_of.Format(time.DateOnly), len(errs))
+	return total, nil
+}
+
+func (s *penaltyService) accrueInstallment(ctx context.Context, installment *entity.TransactionInstallment, policies []entity.PenaltyPolicy, as_of time.Time) (int64, error) {
+	installment_lock_name := fmt.Sprintf("lock:installment:%s", installment.UUID)
+	acquired, err := s.lockRedis.AcquireLock(ctx, installment_lock_name, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	if !acquired {
+		return 0, nil
+	}
+	defer s.lockRedis.ReleaseLock(ctx, installment_lock_name)
+
+	// Read again under the lock, a payment may have settled it or accrued its penalty meanwhile
+	installment, err = s.installmentRepository.FindByID(ctx, installment.ID)
+	if err != nil || installment == nil {
+		return 0, err
+	}
+	if installment.PaymentStatus != entity.PaymentStatusOverdue {
+		return 0, nil
+	}
+
+	penalties := []entity.InstallmentPenalty{}
+	for _, policy := range policies {
+		if penalty := calculatePenalty(&policy, installment, as_of); penalty != nil {
+			penalties = append(penalties, *penalty)
+		}
+	}
+	if len(penalties) == 0 {
+		return 0, nil
+	}
+
+	tx := s.penaltyRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	for i := range penalties {
+		if err := s.penaltyRepository.InsertWithTransaction(ctx, tx, &penalties[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return int64(len(penalties)), nil
+}
+
+// calculatePenalty return the penalty to accrue for a policy on an overdue installment,
+// or nil when nothing is due. Penalties already accrued by the policy are read from
+// installment.Penalties, daily penalty only charge days not accrued yet and cap
+// limit total penalty of the policy on the installment.
+func calculatePenalty(policy *entity.PenaltyPolicy, installment *entity.TransactionInstallment, as_of time.Time) *entity.InstallmentPenalty {
+	as_of_date := time.Date(as_of.Year(), as_of.Month(), as_of.Day(), 0, 0, 0, 0, time.UTC)
+	due_date := time.Date(installment.DueDate.Year(), installment.DueDate.Month(), installment.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	days_past_due := int(as_of_date.Sub(due_date).Hours() / 24)
+	chargeable_days := days_past_due - int(policy.GraceDays)
+	if chargeable_days <= 0 {
+		return nil
+	}
+
+	accrued_amount := decimal.Zero
+	accrued_days := 0
+	accrued_rows := 0
+	for _, penalty := range installment.Penalties {
+		if penalty.PenaltyPolicyID != policy.ID {
+			continue
+		}
+		accrued_amount = accrued_amount.Add(penalty.Amount)
+		accrued_days += int(penalty.Days)
+		accrued_rows++
+	}
+
+	var amount decimal.Decimal
+	days := 0
+	switch policy.Type {
+	case entity.PenaltyFlat:
+		if accrued_rows > 0 {
+			return nil
+		}
+		amount = policy.Amount
+	case entity.PenaltyDaily:
+		days = chargeable_days - accrued_days
+		if days <= 0 {
+			return nil
+		}
+		unpaid := installment.AmountDue.Sub(installment.AmountPaid)
+		amount = unpaid.Mul(policy.Rate).Div(decimal.NewFromInt(100)).
+			Mul(decimal.NewFromInt(int64(days))).Round(2)
+	default:
+		return nil
+	}
+
+	if policy.Cap.IsPositive() {
+		amount = decimal.Min(amount, policy.Cap.Sub(accrued_amount))
+	}
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	return &entity.InstallmentPenalty{
+		InstallmentID:   installment.ID,
+		PenaltyPolicyID: policy.ID,
+		AccrualDate:     as_of_date,
+		Days:            uint(days),
+		Amount:          amount,
+	}
+}
+
+func (s *penaltyService) validateEntityInput(ctx context.Context, policy *entity.PenaltyPolicy) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errs := []helpers.ValidationError{}
+
+	// Check name duplication
+	if exist := s.penaltyPolicyRepository.NameExist(ctx, policy); exist {
+		errs = append(errs, helpers.ValidationError{
+			Field: "name",
+			Tag:   "duplicate",
+		})
+	}
+
+	if len(errs) != 0 {
+		logData.Message = "Validation error"
+		logData.Err = errs
+		return errs
+	}
+
+	return nil
+}
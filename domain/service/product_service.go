@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type ProductService interface {
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Create(ctx context.Context, input *model.ProductInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.ProductInput, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+}
+
+type productService struct {
+	productRepository repository.ProductRepository
+}
+
+func NewProductService(productRepository repository.ProductRepository) ProductService {
+	return &productService{
+		productRepository: productRepository,
+	}
+}
+
+func (s *productService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	product, err := s.productRepository.FindByUUID(ctx, uuid)
+	if err != nil || product == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Product not found",
+			Errors:  err,
+		})
+	}
+
+	productModel := model.ProductToDetailModel(product)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Product data found",
+		Data:    productModel,
+	})
+}
+
+func (s *productService) GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	products, err := s.productRepository.FindAll(ctx, query)
+	if err != nil || products == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Product not found",
+			Errors:  err,
+		})
+	}
+
+	productModels := model.ProductToListModels(*products)
+
+	totalData := s.productRepository.Count(ctx, query)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Product data found",
+		Data:    productModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+func (s *productService) Create(ctx context.Context, input *model.ProductInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	productEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if err := s.validateEntityInput(ctx, productEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.productRepository.Insert(ctx, productEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Product successfully created",
+	})
+}
+
+// UpdateByUUID only change the rules for new transaction, running contract keep the
+// fee and interest it was created with
+func (s *productService) UpdateByUUID(ctx context.Context, input *model.ProductInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	product, err := s.productRepository.FindByUUID(ctx, uuid)
+	if err != nil || product == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Product not found",
+			Errors:  err,
+		})
+	}
+
+	productEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+	productEntity.ID = product.ID
+
+	if err := s.validateEntityInput(ctx, productEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	tx := s.productRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := s.productRepository.UpdateWithTransaction(ctx, tx, productEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	if err := s.productRepository.ReplaceRulesWithTransaction(ctx, tx, productEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+		})
+	}
+
+	tx.Commit()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Product successfully updated",
+	})
+}
+
+func (s *productService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	product, err := s.productRepository.FindByUUID(ctx, uuid)
+	if err != nil || product == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Product not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.productRepository.Delete(ctx, product); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Product successfully deleted",
+	})
+}
+
+func (s *productService) validateEntityInput(ctx context.Context, product *entity.Product) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errs := []helpers.ValidationError{}
+
+	// Check code duplication
+	if exist := s.productRepository.CodeExist(ctx, product); exist {
+		errs = append(errs, helpers.ValidationError{
+			Field: "code",
+			Tag:   "duplicate",
+		})
+	}
+
+	if product.InterestRate.IsNegative() || product.AdminFeeAmount.IsNegative() || product.AdminFeeRate.IsNegative() {
+		errs = append(errs, helpers.ValidationError{
+			Field: "interest_rate",
+			Tag:   "gte",
+		})
+	}
+
+	if product.MaxOnTheRoad.Valid && product.MinOnTheRoad.GreaterThan(product.MaxOnTheRoad.Decimal) {
+		errs = append(errs, helpers.ValidationError{
+			Field: "min_on_the_road",
+			Tag:   "ltefield",
+		})
+	}
+
+	if product.AdminFeeType == entity.AdminFeeTiered && len(product.FeeTiers) == 0 {
+		errs = append(errs, helpers.ValidationError{
+			Field: "fee_tiers",
+			Tag:   "required_if",
+		})
+	}
+
+	tenors := map[uint]bool{}
+	for _, tenor := range product.Tenors {
+		if tenors[tenor.Tenor] {
+			errs = append(errs, helpers.ValidationError{
+				Field: "tenors",
+				Tag:   "duplicate",
+			})
+			break
+		}
+		tenors[tenor.Tenor] = true
+	}
+
+	tiers := map[string]bool{}
+	for _, tier := range product.FeeTiers {
+		if tiers[tier.MinOnTheRoad.String()] {
+			errs = append(errs, helpers.ValidationError{
+				Field: "fee_tiers",
+				Tag:   "duplicate",
+			})
+			break
+		}
+		tiers[tier.MinOnTheRoad.String()] = true
+	}
+
+	if len(errs) != 0 {
+		logData.Message = "Validation error"
+		logData.Err = errs
+		return errs
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"sync"
 	"time"
 
@@ -12,7 +13,6 @@ import (
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
-	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -24,19 +24,26 @@ type RegistrationService interface {
 }
 
 type registrationService struct {
-	repository      repository.UserRepository
-	roleRepository  repository.RoleRepository
-	limitRepository repository.LimitRepository
+	repository            repository.UserRepository
+	roleRepository        repository.RoleRepository
+	limitRepository       repository.LimitRepository
+	limitPolicyRepository repository.LimitPolicyRepository
+	installmentRepository repository.InstallmentRepository
+	creditScoreRepository repository.CreditScoreRepository
 }
 
 func NewRegistrationService(
 	repository repository.UserRepository, roleRepository repository.RoleRepository,
-	limitRepository repository.LimitRepository,
+	limitRepository repository.LimitRepository, limitPolicyRepository repository.LimitPolicyRepository,
+	installmentRepository repository.InstallmentRepository, creditScoreRepository repository.CreditScoreRepository,
 ) RegistrationService {
 	return &registrationService{
-		repository:      repository,
-		roleRepository:  roleRepository,
-		limitRepository: limitRepository,
+		repository:            repository,
+		roleRepository:        roleRepository,
+		limitRepository:       limitRepository,
+		limitPolicyRepository: limitPolicyRepository,
+		installmentRepository: installmentRepository,
+		creditScoreRepository: creditScoreRepository,
 	}
 }
 
@@ -163,6 +170,35 @@ func (s *registrationService) Activate(ctx context.Context, uuid uuid.UUID) help
 		})
 	}
 
+	policies, err := s.limitPolicyRepository.FindAllActive(ctx)
+	if err != nil || policies == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error getting limit policy data",
+			Errors:  err,
+		})
+	}
+
+	policy := selectLimitPolicy(*policies, user.Profile, time.Now())
+	if policy == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusUnprocessableEntity,
+			Success: false,
+			Message: "No limit policy match the user profile",
+		})
+	}
+
+	score, err := calculateCreditScore(ctx, s.installmentRepository, user, time.Now())
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error calculating credit score",
+			Errors:  err,
+		})
+	}
+
 	user.ValidatedAt = sql.NullTime{Valid: true, Time: time.Now()}
 	user.UpdatedAt = time.Now()
 
@@ -178,7 +214,16 @@ func (s *registrationService) Activate(ctx context.Context, uuid uuid.UUID) help
 		})
 	}
 
-	if err := s.generateUserLimit(ctx, tx, user.ID); err != nil {
+	if err := s.creditScoreRepository.InsertWithTransaction(ctx, tx, score); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating credit score data",
+			Errors:  err,
+		})
+	}
+
+	if err := s.generateUserLimit(ctx, tx, user.ID, policy, score); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
@@ -278,16 +323,46 @@ func (s *registrationService) ValidateEntityInput(ctx context.Context, user *ent
 	return nil
 }
 
-func (s *registrationService) generateUserLimit(ctx context.Context, tx *gorm.DB, user_id uint) error {
-	limits := []entity.Limit{
-		{UserID: user_id, Tenor: 1, OriginalLimit: decimal.NewFromInt(100000), CurrentLimit: decimal.NewFromInt(100000)},
-		{UserID: user_id, Tenor: 2, OriginalLimit: decimal.NewFromInt(200000), CurrentLimit: decimal.NewFromInt(200000)},
-		{UserID: user_id, Tenor: 3, OriginalLimit: decimal.NewFromInt(500000), CurrentLimit: decimal.NewFromInt(500000)},
-		{UserID: user_id, Tenor: 6, OriginalLimit: decimal.NewFromInt(700000), CurrentLimit: decimal.NewFromInt(700000)},
+// generateUserLimit give the user every tenor of the policy, and the global cap on
+// LimitPoolGlobalCap, scaled by the credit grade, each recorded as a created limit
+func (s *registrationService) generateUserLimit(ctx context.Context, tx *gorm.DB, user_id uint,
+	policy *entity.LimitPolicy, score *entity.CreditScore) error {
+	limits := []entity.Limit{}
+	for _, item := range policy.LimitItems() {
+		amount := item.Amount.Mul(score.LimitMultiplier).Round(2)
+		limits = append(limits, entity.Limit{
+			UserID:        user_id,
+			Tenor:         item.Tenor,
+			OriginalLimit: amount,
+			CurrentLimit:  amount,
+			LimitPolicyID: &policy.ID,
+			PoolMode:      policy.PoolMode,
+		})
+	}
+	if len(limits) == 0 {
+		return nil
 	}
+
 	if err := s.limitRepository.BulkInsertWithTransaction(ctx, tx, limits); err != nil {
 		return err
 	}
 
+	changed_by := changedBy(ctx)
+	for _, limit := range limits {
+		if err := s.limitRepository.InsertChangeWithTransaction(ctx, tx, &entity.LimitChange{
+			UserID:        user_id,
+			LimitID:       limit.ID,
+			Tenor:         limit.Tenor,
+			Action:        entity.LimitCreate,
+			Amount:        limit.OriginalLimit,
+			OriginalLimit: limit.OriginalLimit,
+			CurrentLimit:  limit.CurrentLimit,
+			Reason:        fmt.Sprintf("Activation with limit policy %s and credit grade %s", policy.Name, score.Grade),
+			ChangedBy:     changed_by,
+		}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
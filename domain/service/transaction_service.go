@@ -2,17 +2,28 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/amortization"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/calendar"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/contract"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/limit"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type TransactionService interface {
@@ -20,16 +31,49 @@ type TransactionService interface {
 	GetAll(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
 	GetAllByUserUUID(ctx context.Context, query *model.QueryGet, url string, uuid uuid.UUID) helpers.BaseResponse
 	Create(ctx context.Context, input *model.TransactionInput) helpers.BaseResponse
+	CreateForPartner(ctx context.Context, input *model.PartnerTransactionInput) helpers.BaseResponse
+	CreateCheckout(ctx context.Context, input *model.PartnerTransactionInput) helpers.BaseResponse
+	GetCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetCheckouts(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	ConfirmCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	CancelCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	ExpireCheckouts(ctx context.Context, as_of time.Time) (int64, error)
+	Import(ctx context.Context, input *model.TransactionImportInput) helpers.BaseResponse
+	Simulate(ctx context.Context, input *model.TransactionInput) helpers.BaseResponse
 	UpdateByUUID(ctx context.Context, input *model.TransactionInput, uuid uuid.UUID) helpers.BaseResponse
 	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	GetContractFile(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	ChangeStatus(ctx context.Context, input *model.TransactionStatusInput, uuid uuid.UUID) helpers.BaseResponse
+	GetApprovalQueue(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse
+	Review(ctx context.Context, input *model.TransactionReviewInput, uuid uuid.UUID) helpers.BaseResponse
 }
 
+// manualTransitions map the status an admin can set by hand to the status it must come
+// from. Active only cure a defaulted contract here, a paid one is reopened by payment reversal
+var manualTransitions = map[entity.TransactionStatus]entity.TransactionStatus{
+	entity.TransactionDefaulted:  entity.TransactionActive,
+	entity.TransactionWrittenOff: entity.TransactionDefaulted,
+	entity.TransactionActive:     entity.TransactionDefaulted,
+}
+
+// contractFolder hold generated contract pdf, it is not served as static file
+// so download always go through the owner check
+var contractFolder = filepath.Join("storage", "contracts")
+
 type transactionService struct {
-	transactionRepository repository.TransactionRepository
-	userRepository        repository.UserRepository
-	limitRepository       repository.LimitRepository
-	installmentRepository repository.InstallmentRepository
-	lockRedis             *redis.LockClient
+	transactionRepository     repository.TransactionRepository
+	userRepository            repository.UserRepository
+	limitRepository           repository.LimitRepository
+	installmentRepository     repository.InstallmentRepository
+	ledgerRepository          repository.LedgerRepository
+	productRepository         repository.ProductRepository
+	holidayRepository         repository.HolidayRepository
+	creditScoreRepository     repository.CreditScoreRepository
+	merchantRepository        repository.MerchantRepository
+	disbursementRepository    repository.DisbursementRepository
+	partnerConsentRepository  repository.PartnerConsentRepository
+	checkoutSessionRepository repository.CheckoutSessionRepository
+	lockRedis                 *redis.LockClient
 }
 
 func NewTransactionService(
@@ -37,14 +81,30 @@ func NewTransactionService(
 	userRepository repository.UserRepository,
 	limitRepository repository.LimitRepository,
 	installmentRepository repository.InstallmentRepository,
+	ledgerRepository repository.LedgerRepository,
+	productRepository repository.ProductRepository,
+	holidayRepository repository.HolidayRepository,
+	creditScoreRepository repository.CreditScoreRepository,
+	merchantRepository repository.MerchantRepository,
+	disbursementRepository repository.DisbursementRepository,
+	partnerConsentRepository repository.PartnerConsentRepository,
+	checkoutSessionRepository repository.CheckoutSessionRepository,
 	lockRedis *redis.LockClient,
 ) TransactionService {
 	return &transactionService{
-		transactionRepository: transactionRepository,
-		userRepository:        userRepository,
-		limitRepository:       limitRepository,
-		installmentRepository: installmentRepository,
-		lockRedis:             lockRedis,
+		transactionRepository:     transactionRepository,
+		userRepository:            userRepository,
+		limitRepository:           limitRepository,
+		installmentRepository:     installmentRepository,
+		ledgerRepository:          ledgerRepository,
+		productRepository:         productRepository,
+		holidayRepository:         holidayRepository,
+		creditScoreRepository:     creditScoreRepository,
+		merchantRepository:        merchantRepository,
+		disbursementRepository:    disbursementRepository,
+		partnerConsentRepository:  partnerConsentRepository,
+		checkoutSessionRepository: checkoutSessionRepository,
+		lockRedis:                 lockRedis,
 	}
 }
 
@@ -182,6 +242,14 @@ func (s *transactionService) Create(ctx context.Context, input *model.Transactio
 		})
 	}
 
+	if errResponse := s.applyProduct(ctx, transactionEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	if errResponse := s.applyMerchant(ctx, transactionEntity, input.MerchantCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
 	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
 	if session_user_id == 0 || !ok {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
@@ -201,103 +269,219 @@ func (s *transactionService) Create(ctx context.Context, input *model.Transactio
 		})
 	}
 
-	transactionEntity.UserID = user.ID
+	if errResponse := s.create(ctx, user, transactionEntity, s.approvalReason(ctx, user, transactionEntity), nil); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
 
-	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
-	lock_ttl := 10 * time.Second
-	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
-	if !acquireUserLimit || err != nil {
+	if transactionEntity.Status == entity.TransactionPendingApproval {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusCreated,
+			Success: true,
+			Message: "Transaction submitted for approval",
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Transaction succesffully created",
+	})
+}
+
+// CreateForPartner create a contract for a consenting customer on behalf of the partner
+// merchant, the merchant of the contract is always the partner itself
+func (s *transactionService) CreateForPartner(ctx context.Context, input *model.PartnerTransactionInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	customer_uuid, err := uuid.Parse(input.CustomerUUID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+		})
+	}
+
+	user, errResponse := partnerCustomer(ctx, s.userRepository, s.partnerConsentRepository, customer_uuid)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	transactionEntity, err := input.ToTransactionInput().ToEntity()
+	if err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "failed to acquire lock",
+			Message: "Failed parsing input",
+		})
+	}
+
+	if errResponse := s.applyProduct(ctx, transactionEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	partner_id, _ := ctx.Value(helpers.CtxKeyPartnerID).(uint)
+	merchant, err := s.merchantRepository.FindByID(ctx, partner_id)
+	if err != nil || merchant == nil || !merchant.IsActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Merchant is not active",
 			Errors:  err,
 		})
 	}
-	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+	transactionEntity.MerchantID = &merchant.ID
+	transactionEntity.Merchant = merchant
 
-	// Limit calculation
-	updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, user.ID, transactionEntity.Tenor, transactionEntity.OnTheRoad, true)
+	if errResponse := s.create(ctx, user, transactionEntity, s.approvalReason(ctx, user, transactionEntity), nil); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	message := "Transaction succesffully created"
+	if transactionEntity.Status == entity.TransactionPendingApproval {
+		message = "Transaction submitted for approval"
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: message,
+		Data:    model.TransactionToPartnerModel(transactionEntity),
+	})
+}
+
+// CreateCheckout hold the on the road of a partner checkout against the limit of a consenting
+// customer, the hold become a contract when the customer confirm it before it expire
+func (s *transactionService) CreateCheckout(ctx context.Context, input *model.PartnerTransactionInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	customer_uuid, err := uuid.Parse(input.CustomerUUID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+		})
+	}
+
+	user, errResponse := partnerCustomer(ctx, s.userRepository, s.partnerConsentRepository, customer_uuid)
 	if errResponse != nil {
 		return helpers.LogBaseResponse(&logData, *errResponse)
 	}
 
-	tx := s.transactionRepository.BeginTransaction(ctx)
-	defer tx.Rollback()
+	// Product rules are checked now so a confirmed checkout does not fail on them later
+	transactionEntity, err := input.ToTransactionInput().ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
 
-	if err := s.transactionRepository.InsertWithTransaction(ctx, tx, transactionEntity); err != nil {
+	if errResponse := s.applyProduct(ctx, transactionEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	partner_id, _ := ctx.Value(helpers.CtxKeyPartnerID).(uint)
+	merchant, err := s.merchantRepository.FindByID(ctx, partner_id)
+	if err != nil || merchant == nil || !merchant.IsActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Merchant is not active",
+			Errors:  err,
+		})
+	}
+
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
+	lock_ttl := 10 * time.Second
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Error creating transaction data",
-			Errors:  logData.Err,
+			Message: "failed to acquire lock",
+			Errors:  err,
 		})
 	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
 
-	// Generate installment
-	newInstallments := make([]entity.TransactionInstallment, transactionEntity.Tenor)
-	for month := range int(transactionEntity.Tenor) {
-		dueDate := time.Now().AddDate(0, month+1, 0)
-		newInstallments[month] = entity.TransactionInstallment{
-			TransactionID:     transactionEntity.ID,
-			InstallmentNumber: uint(month + 1),
-			AmountDue:         transactionEntity.MonthlyInstallment,
-			DueDate:           dueDate,
-		}
+	limits, updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, user.ID, transactionEntity.Tenor, "", transactionEntity.OnTheRoad, true)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
 	}
 
-	if err := s.installmentRepository.BulkInsertWithTransaction(ctx, tx, newInstallments); err != nil {
+	session := &entity.CheckoutSession{
+		UserID:        user.ID,
+		MerchantID:    merchant.ID,
+		ProductCode:   input.ProductCode,
+		AssetName:     transactionEntity.AssetName,
+		OnTheRoad:     transactionEntity.OnTheRoad,
+		Tenor:         transactionEntity.Tenor,
+		BillingDay:    transactionEntity.BillingDay,
+		LimitPoolMode: limit.PoolMode(limits, transactionEntity.Tenor),
+		Status:        entity.CheckoutPending,
+		ExpiresAt:     time.Now().Add(time.Duration(config.AppConfig.CheckoutSessionExp) * time.Minute),
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := s.checkoutSessionRepository.InsertWithTransaction(ctx, tx, session); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Error creating installments data",
-			Errors:  logData.Err,
+			Message: "Error creating checkout session data",
 		})
 	}
 
-	if err := s.limitRepository.BulkUpdateWithTransaction(ctx, tx, updatedLimits); err != nil {
+	movement := checkoutLimitMovement(ctx, session, entity.LimitCheckoutHold,
+		fmt.Sprintf("Checkout %s held by merchant %s", session.UUID, merchant.Code))
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, limits, updatedLimits, movement); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Message: "Error updating limits data",
-			Errors:  logData.Err,
 		})
 	}
 
 	tx.Commit()
-	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-		Status:  fiber.StatusCreated,
-		Success: true,
-		Message: "Transaction succesffully created",
-	})
-}
 
-func (s *transactionService) UpdateByUUID(ctx context.Context, input *model.TransactionInput, uuid uuid.UUID) helpers.BaseResponse {
-	logData := helpers.CreateLog(s)
-	defer helpers.LogSystemWithDefer(ctx, &logData)
+	session.Merchant = merchant
 
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-		Status:  fiber.StatusOK,
+		Status:  fiber.StatusCreated,
 		Success: true,
-		Message: "To be changed",
+		Message: "Checkout session successfully created",
+		Data:    model.CheckoutToDetailModel(session),
 	})
 }
 
-func (s *transactionService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+// GetCheckout is open to the partner merchant of the session, the customer and admin
+func (s *transactionService) GetCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
-	if err != nil || transaction == nil {
+	session, err := s.checkoutSessionRepository.FindByUUID(ctx, uuid)
+	if err != nil || session == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusNotFound,
 			Success: false,
-			Message: "Transaction Not Found",
+			Message: "Checkout session not found",
 			Errors:  err,
 		})
 	}
 
-	if !helpers.SelfOrAdminOnly(ctx, transaction.UserID) {
+	is_allowed := false
+	if partner_id, ok := ctx.Value(helpers.CtxKeyPartnerID).(uint); ok && partner_id != 0 {
+		is_allowed = session.MerchantID == partner_id
+	} else {
+		is_allowed = helpers.SelfOrAdminOnly(ctx, session.UserID)
+	}
+	if !is_allowed {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
 			Success: false,
@@ -305,175 +489,1748 @@ func (s *transactionService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) h
 		})
 	}
 
-	if transaction.Status != entity.TransactionActive {
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Checkout session data found",
+		Data:    model.CheckoutToDetailModel(session),
+	})
+}
+
+// GetCheckouts list the checkout sessions of the session user
+func (s *transactionService) GetCheckouts(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user_id := changedBy(ctx)
+	if user_id == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusBadRequest,
+			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "Transaction already paid or already cancelled",
+			Message: "Missing user id",
 		})
 	}
 
-	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
-	if session_user_id == 0 || !ok {
+	sessions, err := s.checkoutSessionRepository.FindAllByUserID(ctx, query, *user_id)
+	if err != nil || sessions == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusNotFound,
 			Success: false,
-			Message: "Missing user id",
+			Message: "Checkout session not found",
+			Errors:  err,
 		})
 	}
 
-	user, err := s.userRepository.FindByID(ctx, uint(session_user_id))
-	if err != nil || user == nil {
+	totalData := s.checkoutSessionRepository.CountByUserID(ctx, query, *user_id)
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Checkout session data found",
+		Data:    model.CheckoutToDetailModels(*sessions),
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+// ConfirmCheckout turn the hold into a contract, only the customer of the session can
+// confirm it and only before it expire
+func (s *transactionService) ConfirmCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	session, err := s.checkoutSessionRepository.FindByUUID(ctx, uuid)
+	if err != nil || session == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusNotFound,
 			Success: false,
-			Message: "User Not Found",
+			Message: "Checkout session not found",
 			Errors:  err,
 		})
 	}
 
-	lock_ttl := 10 * time.Second
+	if user_id := changedBy(ctx); user_id == nil || *user_id != session.UserID {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Only the customer can confirm the checkout",
+		})
+	}
 
-	// Lock limit
-	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
-	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
-	if !acquireUserLimit || err != nil {
+	if !session.IsOpen(time.Now()) {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
-			Message: "failed to acquire lock",
-			Errors:  err,
+			Message: "Checkout session is no longer pending",
 		})
 	}
-	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
 
-	// Lock transaction
-	transaction_lock_name := fmt.Sprintf("lock:transaction:%s", transaction.UUID)
-	acquireTransaction, err := s.lockRedis.AcquireLock(ctx, transaction_lock_name, lock_ttl)
-	if !acquireTransaction || err != nil {
+	transactionEntity, err := (&model.TransactionInput{
+		AssetName:  session.AssetName,
+		OnTheRoad:  session.OnTheRoad.String(),
+		Tenor:      session.Tenor,
+		BillingDay: session.BillingDay,
+	}).ToEntity()
+	if err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "failed to acquire lock",
-			Errors:  err,
+			Message: "Failed parsing input",
 		})
 	}
-	defer s.lockRedis.ReleaseLock(ctx, transaction_lock_name)
 
-	// Lock installment
-	installment_lock_name := fmt.Sprintf("lock:installment:%s", transaction.UUID)
-	acquireinstallment, err := s.lockRedis.AcquireLock(ctx, installment_lock_name, lock_ttl)
-	if !acquireinstallment || err != nil {
+	if errResponse := s.applyProduct(ctx, transactionEntity, session.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	merchant, err := s.merchantRepository.FindByID(ctx, session.MerchantID)
+	if err != nil || merchant == nil || !merchant.IsActive {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
-			Message: "failed to acquire lock",
+			Message: "Merchant is not active",
 			Errors:  err,
 		})
 	}
-	defer s.lockRedis.ReleaseLock(ctx, installment_lock_name)
+	transactionEntity.MerchantID = &merchant.ID
+	transactionEntity.Merchant = merchant
 
-	// Limit calculation
-	updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, user.ID, transaction.Tenor, transaction.OnTheRoad, false)
-	if errResponse != nil {
+	user := session.User
+	if errResponse := s.create(ctx, &user, transactionEntity, s.approvalReason(ctx, &user, transactionEntity), session); errResponse != nil {
 		return helpers.LogBaseResponse(&logData, *errResponse)
 	}
 
-	tx := s.transactionRepository.BeginTransaction(ctx)
-	defer tx.Rollback()
+	session.Transaction = transactionEntity
+
+	message := "Checkout successfully confirmed"
+	if transactionEntity.Status == entity.TransactionPendingApproval {
+		message = "Checkout confirmed and submitted for approval"
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: message,
+		Data:    model.CheckoutToDetailModel(session),
+	})
+}
+
+// CancelCheckout release the hold of a pending session on the customer or admin request
+func (s *transactionService) CancelCheckout(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	transaction.Status = entity.TransactionCanceled
-	if err := s.transactionRepository.UpdateWithTransaction(ctx, tx, transaction); err != nil {
+	session, err := s.checkoutSessionRepository.FindByUUID(ctx, uuid)
+	if err != nil || session == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusNotFound,
 			Success: false,
-			Message: "Error updating transaction data",
-			Errors:  logData.Err,
+			Message: "Checkout session not found",
+			Errors:  err,
 		})
 	}
 
-	if err := s.installmentRepository.CancelWithTransaction(ctx, tx, transaction.ID); err != nil {
+	if !helpers.SelfOrAdminOnly(ctx, session.UserID) {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusForbidden,
 			Success: false,
-			Message: "Error updating installments data",
-			Errors:  logData.Err,
+			Message: "Unauthorized to access this data",
 		})
 	}
 
-	if err := s.limitRepository.BulkUpdateWithTransaction(ctx, tx, updatedLimits); err != nil {
+	if session.Status != entity.CheckoutPending {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
-			Message: "Error updating limits data",
-			Errors:  logData.Err,
+			Message: "Checkout session is no longer pending",
 		})
 	}
 
-	tx.Commit()
+	if errResponse := s.releaseCheckout(ctx, session, entity.CheckoutCanceled,
+		fmt.Sprintf("Checkout %s canceled", session.UUID)); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
-		Message: "Transaction successfully cancelled",
+		Message: "Checkout session successfully canceled",
 	})
 }
 
-func (s *transactionService) generateUpdatedLimitList(ctx context.Context, user_id uint, tenor uint, otr decimal.Decimal, is_reduce bool) ([]entity.Limit, *helpers.BaseResponse) {
-	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, user_id)
-	if err != nil || limits == nil {
-		return []entity.Limit{}, &helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
-			Success: false,
-			Message: "User limit not found",
-			Errors:  err,
-		}
+// ExpireCheckouts is run by the checkout worker, not exposed through http. Session that
+// fail to release, like when its user limit is locked, is left pending for the next run
+func (s *transactionService) ExpireCheckouts(ctx context.Context, as_of time.Time) (int64, error) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	sessions, err := s.checkoutSessionRepository.FindExpired(ctx, as_of, 500)
+	if err != nil {
+		logData.Message = "Failed finding expired checkout sessions"
+		logData.Err = err.Error()
+		return 0, err
 	}
 
-	tenor_limit_found := false
-	updatedLimits := make([]entity.Limit, len(*limits))
-	for i, limit := range *limits {
-		if limit.Tenor == tenor {
-			tenor_limit_found = true
-			if limit.CurrentLimit.LessThan(otr) && is_reduce {
-				return []entity.Limit{}, &helpers.BaseResponse{
-					Status:  fiber.StatusBadRequest,
-					Success: false,
-					Message: "Overlimit",
-					Errors:  err,
-				}
-			}
+	var total int64
+	for i := range *sessions {
+		session := &(*sessions)[i]
+		if errResponse := s.releaseCheckout(ctx, session, entity.CheckoutExpired,
+			fmt.Sprintf("Checkout %s expired", session.UUID)); errResponse != nil {
+			continue
 		}
+		total++
+	}
 
-		var newLimit decimal.Decimal
-		if is_reduce {
-			newLimit = limit.CurrentLimit.Sub(otr)
-			if newLimit.IsNegative() {
-				newLimit = decimal.Zero
-			}
-		} else {
-			newLimit = limit.CurrentLimit.Add(otr)
-			if newLimit.GreaterThan(limit.OriginalLimit) {
-				newLimit = limit.OriginalLimit
-			}
-		}
+	logData.Message = fmt.Sprintf("%d of %d checkout sessions expired as of %s", total, len(*sessions), as_of.Format(time.DateTime))
+	return total, nil
+}
+
+// releaseCheckout give the hold of a pending session back to the limits it was taken from
+// and close the session with status. Session need its user loaded
+func (s *transactionService) releaseCheckout(ctx context.Context, session *entity.CheckoutSession, status entity.CheckoutStatus, reason string) *helpers.BaseResponse {
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", session.User.UUID)
+	lock_ttl := 10 * time.Second
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		}
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	limits, updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, session.UserID, session.Tenor, session.LimitPoolMode, session.OnTheRoad, false)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	session.Status = status
+	if err := s.checkoutSessionRepository.UpdateStatusWithTransaction(ctx, tx, session, entity.CheckoutPending); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Checkout session is no longer pending",
+		}
+	}
+
+	movement := checkoutLimitMovement(ctx, session, entity.LimitCheckoutRelease, reason)
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, limits, updatedLimits, movement); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+		}
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// Import create a legacy contract for the user with its own contract number, the
+// contract still use the user limit like a new one. Only admin can import
+func (s *transactionService) Import(ctx context.Context, input *model.TransactionImportInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if is_admin, _ := ctx.Value(helpers.CtxKeyIsAdmin).(bool); !is_admin {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	transactionEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	// Number of the generated form would later collide with the sequence
+	if contract.Matches(config.AppConfig.ContractNumberFormat, transactionEntity.ContractNumber) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Legacy contract number can not use the generated contract number format",
+		})
+	}
+
+	if s.transactionRepository.ContractNumberExist(ctx, transactionEntity.ContractNumber) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Contract number already exist",
+		})
+	}
+
+	if errResponse := s.applyProduct(ctx, transactionEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	if errResponse := s.applyMerchant(ctx, transactionEntity, input.MerchantCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	user_uuid, err := uuid.Parse(input.UserUUID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+		})
+	}
+
+	user, err := s.userRepository.FindByUUID(ctx, user_uuid)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		})
+	}
+
+	// Legacy contract is already running, it skip underwriting
+	if errResponse := s.create(ctx, user, transactionEntity, "", nil); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Transaction successfully imported",
+	})
+}
+
+// Simulate run the same limit check and installment generation as Create
+// without writing anything or acquiring limit lock
+func (s *transactionService) Simulate(ctx context.Context, input *model.TransactionInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	transactionEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if errResponse := s.applyProduct(ctx, transactionEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
+	if session_user_id == 0 || !ok {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Missing user id",
+		})
+	}
+
+	user, err := s.userRepository.FindByID(ctx, uint(session_user_id))
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "User Not Found",
+			Errors:  err,
+		})
+	}
+
+	transactionEntity.UserID = user.ID
+	transactionEntity.Status = entity.TransactionActive
+	if transactionEntity.BillingDay == 0 {
+		transactionEntity.BillingDay = config.AppConfig.BillingDay
+	}
+
+	schedule, err := s.generateSchedule(transactionEntity)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Failed generating installment schedule",
+			Errors:  err.Error(),
+		})
+	}
+
+	dueDates, errResponse := s.generateDueDates(ctx, transactionEntity)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	// Limit calculation, result only used as projection
+	_, updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, user.ID, transactionEntity.Tenor, "", transactionEntity.OnTheRoad, true)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	installments := generateInstallmentList(0, dueDates, schedule)
+	simulationModel := model.TransactionToSimulationModel(transactionEntity, installments, updatedLimits)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Transaction simulation generated",
+		Data:    simulationModel,
+	})
+}
+
+// UpdateByUUID amend contract terms of an active transaction that has no payment yet.
+// Previous terms are kept as revision and unpaid installments are regenerated. Only admin
+// can amend, the contract is already disbursed.
+func (s *transactionService) UpdateByUUID(ctx context.Context, input *model.TransactionInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if is_admin, _ := ctx.Value(helpers.CtxKeyIsAdmin).(bool); !is_admin {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status != entity.TransactionActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction already paid or already cancelled",
+		})
+	}
+
+	if len(transaction.Payments) > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction with payment can not be amended",
+		})
+	}
+
+	amendedEntity, err := input.ToEntity()
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Failed parsing input",
+		})
+	}
+
+	if errResponse := s.applyProduct(ctx, amendedEntity, input.ProductCode); errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	session_user_id, ok := ctx.Value(helpers.CtxKeyUserID).(float64)
+	if session_user_id == 0 || !ok {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Missing user id",
+		})
+	}
+
+	lock_ttl := 10 * time.Second
+
+	// Lock limit
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	// Lock transaction
+	transaction_lock_name := fmt.Sprintf("lock:transaction:%s", transaction.UUID)
+	acquireTransaction, err := s.lockRedis.AcquireLock(ctx, transaction_lock_name, lock_ttl)
+	if !acquireTransaction || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, transaction_lock_name)
+
+	// Read again under the lock, a payment or another amendment may have landed meanwhile
+	transaction, err = s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status != entity.TransactionActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Transaction already paid or already cancelled",
+		})
+	}
+
+	if len(transaction.Payments) > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Transaction with payment can not be amended",
+		})
+	}
+
+	// Lock installments, payment and penalty lock them one by one
+	for _, installment := range transaction.Installments {
+		installment_lock_name := fmt.Sprintf("lock:installment:%s", installment.UUID)
+		acquireInstallment, err := s.lockRedis.AcquireLock(ctx, installment_lock_name, lock_ttl)
+		if !acquireInstallment || err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "failed to acquire lock",
+				Errors:  err,
+			})
+		}
+		defer s.lockRedis.ReleaseLock(ctx, installment_lock_name)
+
+		// Penalty stay on the installment regenerated below, it must be settled first.
+		// Read under the installment lock so a penalty accrued meanwhile is seen
+		current, err := s.installmentRepository.FindByID(ctx, installment.ID)
+		if err != nil || current == nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusNotFound,
+				Success: false,
+				Message: "Installment Not Found",
+				Errors:  err,
+			})
+		}
+		if model.UnpaidPenalty(current.Penalties).IsPositive() {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Transaction with unpaid penalty can not be amended",
+			})
+		}
+	}
+
+	revision := entity.NewTransactionRevision(transaction, uint(len(transaction.Revisions)+1), uint(session_user_id))
+
+	previousTenor := transaction.Tenor
+	previousOnTheRoad := transaction.OnTheRoad
+	previousAdminFee := transaction.AdminFee
+
+	// Contract number, start date and merchant are kept from the original contract
+	transaction.ProductID = amendedEntity.ProductID
+	transaction.Product = amendedEntity.Product
+	transaction.AssetName = amendedEntity.AssetName
+	transaction.OnTheRoad = amendedEntity.OnTheRoad
+	transaction.AdminFee = amendedEntity.AdminFee
+	transaction.InterestMethod = amendedEntity.InterestMethod
+	transaction.InterestRate = amendedEntity.InterestRate
+	transaction.Tenor = amendedEntity.Tenor
+	if amendedEntity.BillingDay != 0 {
+		transaction.BillingDay = amendedEntity.BillingDay
+	}
+	transaction.UpdatedAt = time.Now()
+
+	// An amended contract is already disbursed so it can not wait for approval again,
+	// terms that need a checker must go through a new contract
+	if reason := s.approvalReason(ctx, &transaction.User, transaction); reason != "" {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Amended contract needs approval, " + reason,
+		})
+	}
+
+	schedule, err := s.generateSchedule(transaction)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Failed generating installment schedule",
+			Errors:  err.Error(),
+		})
+	}
+
+	dueDates, errResponse := s.generateDueDates(ctx, transaction)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	// Limit calculation, restore previous otr then consume the amended one
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, transaction.UserID)
+	if err != nil || limits == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "User limit not found",
+			Errors:  err,
+		})
+	}
+
+	updatedLimits, mode, err := limit.Amend(*limits, previousTenor, transaction.LimitPoolMode, previousOnTheRoad, transaction.Tenor, transaction.OnTheRoad)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, *limitErrorResponse(err))
+	}
+	transaction.LimitPoolMode = mode
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := s.transactionRepository.InsertRevisionWithTransaction(ctx, tx, revision); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating transaction revision data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.transactionRepository.AmendWithTransaction(ctx, tx, transaction); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating transaction data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.disbursementRepository.UpdateAmountWithTransaction(ctx, tx, transaction.ID, transaction.OnTheRoad); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating disbursement data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.installmentRepository.DeleteUnpaidWithTransaction(ctx, tx, transaction.ID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating installments data",
+			Errors:  logData.Err,
+		})
+	}
+
+	newInstallments := generateInstallmentList(transaction.ID, dueDates, schedule)
+	if err := s.installmentRepository.BulkInsertWithTransaction(ctx, tx, newInstallments); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating installments data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if entry := ledger.Amendment(transaction, previousOnTheRoad, previousAdminFee); len(entry.Postings) > 0 {
+		if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, entry); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error posting ledger entry",
+				Errors:  logData.Err,
+			})
+		}
+	}
+
+	movement := transactionLimitMovement(ctx, transaction, entity.LimitTransactionAmend,
+		fmt.Sprintf("Contract %s amended, otr %s to %s", transaction.ContractNumber,
+			previousOnTheRoad.StringFixed(2), transaction.OnTheRoad.StringFixed(2)))
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, *limits, updatedLimits, movement); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+			Errors:  logData.Err,
+		})
+	}
+
+	file, err := s.linkContractFile(ctx, tx, transaction, newInstallments, revision.Revision)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating contract document",
+			Errors:  logData.Err,
+		})
+	}
+
+	tx.Commit()
+	file.save()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Transaction successfully amended",
+	})
+}
+
+func (s *transactionService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, transaction.UserID) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	if transaction.Status != entity.TransactionActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction already paid or already cancelled",
+		})
+	}
+
+	lock_ttl := 10 * time.Second
+
+	// Lock limit of the contract owner, admin may cancel on behalf of the customer
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	// Lock transaction
+	transaction_lock_name := fmt.Sprintf("lock:transaction:%s", transaction.UUID)
+	acquireTransaction, err := s.lockRedis.AcquireLock(ctx, transaction_lock_name, lock_ttl)
+	if !acquireTransaction || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, transaction_lock_name)
+
+	// Read again under the lock, a payment may have landed meanwhile
+	transaction, err = s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status != entity.TransactionActive {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Transaction already paid or already cancelled",
+		})
+	}
+
+	// Cancellation reverse the whole disbursement, repaid receivable can not be reversed
+	// so payment must be reversed first
+	for _, payment := range transaction.Payments {
+		if payment.IsEffective() {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Transaction with payment can not be cancelled, reverse the payment first",
+			})
+		}
+	}
+
+	// Lock installments, payment and penalty lock them one by one
+	for _, installment := range transaction.Installments {
+		installment_lock_name := fmt.Sprintf("lock:installment:%s", installment.UUID)
+		acquireInstallment, err := s.lockRedis.AcquireLock(ctx, installment_lock_name, lock_ttl)
+		if !acquireInstallment || err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "failed to acquire lock",
+				Errors:  err,
+			})
+		}
+		defer s.lockRedis.ReleaseLock(ctx, installment_lock_name)
+	}
+
+	// Limit calculation
+	limits, updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, transaction.UserID, transaction.Tenor, transaction.LimitPoolMode, transaction.OnTheRoad, false)
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionCanceled,
+		fmt.Sprintf("Contract %s cancelled", transaction.ContractNumber)); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.installmentRepository.CancelWithTransaction(ctx, tx, transaction.ID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating installments data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.disbursementRepository.CancelWithTransaction(ctx, tx, transaction.ID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating disbursement data",
+			Errors:  logData.Err,
+		})
+	}
+
+	if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, ledger.Cancellation(transaction)); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error posting ledger entry",
+			Errors:  logData.Err,
+		})
+	}
+
+	movement := transactionLimitMovement(ctx, transaction, entity.LimitTransactionCancel,
+		fmt.Sprintf("Contract %s cancelled", transaction.ContractNumber))
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, limits, updatedLimits, movement); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+			Errors:  logData.Err,
+		})
+	}
+
+	tx.Commit()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Transaction successfully cancelled",
+	})
+}
+
+// ChangeStatus set the status an admin decide by hand, see manualTransitions. Only admin
+// can change the status
+func (s *transactionService) ChangeStatus(ctx context.Context, input *model.TransactionStatusInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if is_admin, _ := ctx.Value(helpers.CtxKeyIsAdmin).(bool); !is_admin {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	to := entity.TransactionStatus(input.Status)
+	if from, ok := manualTransitions[to]; !ok || transaction.Status != from || !transaction.Status.CanTransitionTo(to) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: fmt.Sprintf("Transaction can not move from %s to %s", transaction.Status, to),
+		})
+	}
+
+	transaction_lock_name := fmt.Sprintf("lock:transaction:%s", transaction.UUID)
+	acquireTransaction, err := s.lockRedis.AcquireLock(ctx, transaction_lock_name, 10*time.Second)
+	if !acquireTransaction || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, transaction_lock_name)
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, to, input.Reason); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
+			Errors:  logData.Err,
+		})
+	}
+
+	tx.Commit()
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Transaction status successfully changed",
+	})
+}
+
+// GetApprovalQueue list contract waiting for approval
+func (s *transactionService) GetApprovalQueue(ctx context.Context, query *model.QueryGet, url string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	transactions, err := s.transactionRepository.FindAllByStatus(ctx, query, entity.TransactionPendingApproval)
+	if err != nil || transactions == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+	totalData := s.transactionRepository.CountByStatus(ctx, query, entity.TransactionPendingApproval)
+
+	transactionModels := model.TransactionToListModels(*transactions)
+
+	pagination := helpers.GeneratePaginationMetadata(query, url, totalData)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Transactions data found",
+		Data:    transactionModels,
+		Meta: &helpers.Meta{
+			Pagination: pagination,
+		},
+	})
+}
+
+// Review approve or reject a contract waiting for approval, the reviewer can not be the one
+// who created it. Approved contract is disbursed from today, rejected one release its limit.
+// Only admin can review
+func (s *transactionService) Review(ctx context.Context, input *model.TransactionReviewInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if is_admin, _ := ctx.Value(helpers.CtxKeyIsAdmin).(bool); !is_admin {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status != entity.TransactionPendingApproval {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Transaction is not waiting for approval",
+		})
+	}
+
+	reviewer := changedBy(ctx)
+	if reviewer == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Missing user id",
+		})
+	}
+
+	if transaction.CreatedBy != nil && *transaction.CreatedBy == *reviewer {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Creator can not review own contract",
+		})
+	}
+
+	lock_ttl := 10 * time.Second
+
+	// Lock limit
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", transaction.User.UUID)
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	// Lock transaction
+	transaction_lock_name := fmt.Sprintf("lock:transaction:%s", transaction.UUID)
+	acquireTransaction, err := s.lockRedis.AcquireLock(ctx, transaction_lock_name, lock_ttl)
+	if !acquireTransaction || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed to acquire lock",
+			Errors:  err,
+		})
+	}
+	defer s.lockRedis.ReleaseLock(ctx, transaction_lock_name)
+
+	// Read again under the lock, another reviewer may have decided it meanwhile
+	transaction, err = s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if transaction.Status != entity.TransactionPendingApproval {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Transaction is not waiting for approval",
+		})
+	}
+
+	var errResponse *helpers.BaseResponse
+	message := "Transaction successfully approved"
+	if input.Decision == model.ReviewApprove {
+		errResponse = s.approve(ctx, transaction, input.Notes)
+	} else {
+		message = "Transaction successfully rejected"
+		errResponse = s.reject(ctx, transaction, input.Notes)
+	}
+	if errResponse != nil {
+		return helpers.LogBaseResponse(&logData, *errResponse)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: message,
+	})
+}
+
+// approve disburse the contract from today, its schedule and due dates are generated again
+// because the contract may have waited past its original start date
+func (s *transactionService) approve(ctx context.Context, transaction *entity.Transaction, notes string) *helpers.BaseResponse {
+	transaction.StartDate = time.Now()
+
+	schedule, err := s.generateSchedule(transaction)
+	if err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Failed generating installment schedule",
+			Errors:  err.Error(),
+		}
+	}
+
+	dueDates, errResponse := s.generateDueDates(ctx, transaction)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := s.transactionRepository.AmendWithTransaction(ctx, tx, transaction); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating transaction data",
+		}
+	}
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionApproved, notes); err != nil {
+		return &helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
+		}
+	}
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionActive,
+		fmt.Sprintf("Contract %s disbursed", transaction.ContractNumber)); err != nil {
+		return &helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
+		}
+	}
+
+	file, errResponse := s.disburse(ctx, tx, transaction, schedule, dueDates)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	tx.Commit()
+	file.save()
+	return nil
+}
+
+// reject cancel the contract and release the limit held while it wait for approval.
+// Nothing was disbursed so there is no installment or ledger entry to reverse
+func (s *transactionService) reject(ctx context.Context, transaction *entity.Transaction, notes string) *helpers.BaseResponse {
+	limits, updatedLimits, errResponse := s.generateUpdatedLimitList(ctx, transaction.UserID, transaction.Tenor, transaction.LimitPoolMode, transaction.OnTheRoad, false)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if err := transitionTransaction(ctx, tx, s.transactionRepository, transaction, entity.TransactionCanceled, notes); err != nil {
+		return &helpers.BaseResponse{
+			Status:  transitionErrorStatus(err),
+			Success: false,
+			Message: "Error updating transaction data",
+		}
+	}
+
+	movement := transactionLimitMovement(ctx, transaction, entity.LimitTransactionCancel,
+		fmt.Sprintf("Contract %s rejected", transaction.ContractNumber))
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, limits, updatedLimits, movement); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+		}
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// GetContractFile return the path of the contract pdf, the file is generated again
+// when the transaction has none yet or the file is missing from storage
+func (s *transactionService) GetContractFile(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	transaction, err := s.transactionRepository.FindByUUID(ctx, uuid)
+	if err != nil || transaction == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Transaction Not Found",
+			Errors:  err,
+		})
+	}
+
+	if !helpers.SelfOrAdminOnly(ctx, transaction.UserID) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this data",
+		})
+	}
+
+	if transaction.Status == entity.TransactionPendingApproval {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Contract is waiting for approval",
+		})
+	}
+
+	if _, err := os.Stat(transaction.ContractFile); transaction.ContractFile == "" || err != nil {
+		tx := s.transactionRepository.BeginTransaction(ctx)
+		defer tx.Rollback()
+
+		file, err := s.linkContractFile(ctx, tx, transaction, transaction.Installments, uint(len(transaction.Revisions)))
+		if err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error generating contract document",
+				Errors:  logData.Err,
+			})
+		}
+
+		tx.Commit()
+		if err := file.save(); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error generating contract document",
+				Errors:  err.Error(),
+			})
+		}
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Contract document found",
+		Data:    transaction.ContractFile,
+	})
+}
+
+// create insert the transaction of the user with its limit movement. Contract number is
+// generated when the transaction has none. Contract with approval reason wait in the
+// underwriting queue with its limit held, otherwise it is disbursed right away. A confirmed
+// checkout give its hold back to the contract in the same db transaction
+func (s *transactionService) create(ctx context.Context, user *entity.User, transactionEntity *entity.Transaction, approval_reason string, checkout *entity.CheckoutSession) *helpers.BaseResponse {
+	transactionEntity.UserID = user.ID
+	transactionEntity.CreatedBy = changedBy(ctx)
+	transactionEntity.Status = entity.TransactionActive
+	status_reason := "Contract %s created"
+	if approval_reason != "" {
+		transactionEntity.Status = entity.TransactionPendingApproval
+		status_reason = "Contract %s waiting approval, " + approval_reason
+	}
+	if transactionEntity.BillingDay == 0 {
+		transactionEntity.BillingDay = config.AppConfig.BillingDay
+	}
 
-		updatedLimits[i] = entity.Limit{
-			ID:            limit.ID,
-			UserID:        limit.UserID,
-			OriginalLimit: limit.OriginalLimit,
-			CurrentLimit:  newLimit,
-			Tenor:         limit.Tenor,
-			UpdatedAt:     time.Now(),
+	schedule, err := s.generateSchedule(transactionEntity)
+	if err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Failed generating installment schedule",
+			Errors:  err.Error(),
 		}
 	}
-	if !tenor_limit_found {
-		return []entity.Limit{}, &helpers.BaseResponse{
+
+	dueDates, errResponse := s.generateDueDates(ctx, transactionEntity)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	user_limit_lock_name := fmt.Sprintf("lock:userLimit:%s", user.UUID)
+	lock_ttl := 10 * time.Second
+	acquireUserLimit, err := s.lockRedis.AcquireLock(ctx, user_limit_lock_name, lock_ttl)
+	if !acquireUserLimit || err != nil {
+		return &helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Message: "User limit tenor not found",
+			Message: "failed to acquire lock",
+			Errors:  err,
+		}
+	}
+	defer s.lockRedis.ReleaseLock(ctx, user_limit_lock_name)
+
+	// Limit calculation, a checkout hold is released first so the contract consume the
+	// limits under the pool mode they were held with
+	var heldLimits, limits, updatedLimits []entity.Limit
+	if checkout != nil {
+		currentLimits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, user.ID)
+		if err != nil || currentLimits == nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "User limit not found",
+				Errors:  err,
+			}
+		}
+		heldLimits = *currentLimits
+		limits, updatedLimits, err = limit.Confirm(heldLimits, checkout.Tenor, checkout.LimitPoolMode, checkout.OnTheRoad,
+			transactionEntity.Tenor, transactionEntity.OnTheRoad)
+		if err != nil {
+			errResponse = limitErrorResponse(err)
+		}
+		transactionEntity.LimitPoolMode = checkout.LimitPoolMode
+	} else {
+		limits, updatedLimits, errResponse = s.generateUpdatedLimitList(ctx, user.ID, transactionEntity.Tenor, "", transactionEntity.OnTheRoad, true)
+		transactionEntity.LimitPoolMode = limit.PoolMode(limits, transactionEntity.Tenor)
+	}
+	if errResponse != nil {
+		return errResponse
+	}
+
+	tx := s.transactionRepository.BeginTransaction(ctx)
+	defer tx.Rollback()
+
+	if transactionEntity.ContractNumber == "" {
+		contract_number, err := s.generateContractNumber(ctx, tx, transactionEntity.StartDate)
+		if err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error generating contract number",
+				Errors:  err.Error(),
+			}
+		}
+		transactionEntity.ContractNumber = contract_number
+	}
+
+	if err := s.transactionRepository.InsertWithTransaction(ctx, tx, transactionEntity); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating transaction data",
+		}
+	}
+
+	// First status of the contract
+	if err := s.transactionRepository.UpdateStatusWithTransaction(ctx, tx, transactionEntity, &entity.TransactionStatusLog{
+		TransactionID: transactionEntity.ID,
+		ToStatus:      transactionEntity.Status,
+		Reason:        fmt.Sprintf(status_reason, transactionEntity.ContractNumber),
+		ChangedBy:     transactionEntity.CreatedBy,
+	}); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating transaction data",
+		}
+	}
+
+	if checkout != nil {
+		confirmed_at := time.Now()
+		checkout.Status = entity.CheckoutConfirmed
+		checkout.TransactionID = &transactionEntity.ID
+		checkout.ConfirmedAt = &confirmed_at
+		if err := s.checkoutSessionRepository.UpdateStatusWithTransaction(ctx, tx, checkout, entity.CheckoutPending); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusConflict,
+				Success: false,
+				Message: "Checkout session is no longer pending",
+			}
+		}
+
+		movement := checkoutLimitMovement(ctx, checkout, entity.LimitCheckoutRelease,
+			fmt.Sprintf("Checkout %s confirmed as contract %s", checkout.UUID, transactionEntity.ContractNumber))
+		if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, heldLimits, limits, movement); err != nil {
+			return &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error updating limits data",
+			}
+		}
+	}
+
+	// Limit is held while the contract wait for approval
+	movement := transactionLimitMovement(ctx, transactionEntity, entity.LimitTransactionCreate,
+		fmt.Sprintf("Contract %s created", transactionEntity.ContractNumber))
+	if err := updateLimitsWithMovement(ctx, tx, s.limitRepository, limits, updatedLimits, movement); err != nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating limits data",
+		}
+	}
+
+	var file *contractFile
+	if transactionEntity.Status == entity.TransactionActive {
+		// User is set after insert so it is not written back as association
+		transactionEntity.User = *user
+		if file, errResponse = s.disburse(ctx, tx, transactionEntity, schedule, dueDates); errResponse != nil {
+			return errResponse
+		}
+	}
+
+	tx.Commit()
+	if file != nil {
+		file.save()
+	}
+	return nil
+}
+
+// disburse insert the installments, the disbursement ledger entry, the amount owed to the
+// merchant and link the contract document of a contract that start repaying. The returned
+// document is saved by the caller once tx is committed. Transaction need its user and
+// merchant loaded
+func (s *transactionService) disburse(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, schedule *amortization.Schedule, due_dates []time.Time) (*contractFile, *helpers.BaseResponse) {
+	newInstallments := generateInstallmentList(transaction.ID, due_dates, schedule)
+
+	if err := s.installmentRepository.BulkInsertWithTransaction(ctx, tx, newInstallments); err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating installments data",
+		}
+	}
+
+	if err := s.ledgerRepository.InsertEntryWithTransaction(ctx, tx, ledger.Disbursement(transaction)); err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error posting ledger entry",
+		}
+	}
+
+	if transaction.Merchant != nil {
+		if err := s.disbursementRepository.InsertWithTransaction(ctx, tx, &entity.Disbursement{
+			TransactionID: transaction.ID,
+			MerchantID:    transaction.Merchant.ID,
+			Amount:        transaction.OnTheRoad,
+			DueDate:       transaction.Merchant.SettlementDate(transaction.StartDate),
+			Status:        entity.DisbursementPending,
+		}); err != nil {
+			return nil, &helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error creating disbursement data",
+			}
+		}
+	}
+
+	file, err := s.linkContractFile(ctx, tx, transaction, newInstallments, 0)
+	if err != nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating contract document",
+		}
+	}
+
+	return file, nil
+}
+
+// approvalReason tell why the contract of the user must wait for approval, empty when
+// it can be disbursed right away
+func (s *transactionService) approvalReason(ctx context.Context, user *entity.User, transaction *entity.Transaction) string {
+	threshold := decimal.NewFromFloat(config.AppConfig.ApprovalOtrThreshold)
+	if threshold.IsPositive() && transaction.OnTheRoad.GreaterThan(threshold) {
+		return fmt.Sprintf("otr above %s", threshold.StringFixed(2))
+	}
+
+	if config.AppConfig.ApprovalRiskGrades == "" {
+		return ""
+	}
+
+	score, err := s.creditScoreRepository.FindLatestByUserID(ctx, user.ID)
+	if err != nil || score == nil {
+		return ""
+	}
+
+	for _, grade := range strings.Split(config.AppConfig.ApprovalRiskGrades, ",") {
+		if entity.CreditGrade(strings.TrimSpace(grade)) == score.Grade {
+			return fmt.Sprintf("credit grade %s", score.Grade)
+		}
+	}
+
+	return ""
+}
+
+// contractFile is a rendered contract document waiting for its db transaction to commit
+type contractFile struct {
+	path     string
+	document []byte
+}
+
+// linkContractFile render the contract pdf and link it on the transaction inside tx. The
+// file is only written by save once tx is committed, so a rolled back transaction leave
+// no file behind. Every revision get its own file so the document of an amended contract is kept
+func (s *transactionService) linkContractFile(ctx context.Context, tx *gorm.DB, transaction *entity.Transaction, installments []entity.TransactionInstallment, revision uint) (*contractFile, error) {
+	document, err := contract.Document(transaction, installments)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.ContractFile = filepath.Join(contractFolder, fmt.Sprintf("%s_%d.pdf", transaction.UUID, revision))
+	if err := s.transactionRepository.UpdateContractFileWithTransaction(ctx, tx, transaction); err != nil {
+		return nil, err
+	}
+
+	return &contractFile{path: transaction.ContractFile, document: document}, nil
+}
+
+// save write the document after its transaction is committed. A file failing to save is
+// rendered again when the contract document is requested
+func (f *contractFile) save() error {
+	if err := os.MkdirAll(contractFolder, os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.path, f.document, 0o644)
+}
+
+// transitionTransaction move the transaction through its lifecycle and log the change
+// with the session user, illegal move is returned as error
+func transitionTransaction(ctx context.Context, tx *gorm.DB, transactionRepository repository.TransactionRepository,
+	transaction *entity.Transaction, to entity.TransactionStatus, reason string) error {
+	statusLog, err := transaction.Transition(to, reason, changedBy(ctx))
+	if err != nil {
+		return err
+	}
+
+	return transactionRepository.UpdateStatusWithTransaction(ctx, tx, transaction, statusLog)
+}
+
+// transitionErrorStatus answer conflict when the transaction was moved by another request
+// while this one was working on it
+func transitionErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrTransactionStatusChanged) {
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// generateContractNumber take the next sequence of the configured format, the sequence
+// is taken inside tx so it is only used when the transaction is committed
+func (s *transactionService) generateContractNumber(ctx context.Context, tx *gorm.DB, at time.Time) (string, error) {
+	format := config.AppConfig.ContractNumberFormat
+	branch := config.AppConfig.ContractBranch
+	if err := contract.Validate(format); err != nil {
+		return "", err
+	}
+
+	seq, err := s.transactionRepository.NextContractSequenceWithTransaction(ctx, tx, contract.Scope(format, branch, at))
+	if err != nil {
+		return "", err
+	}
+
+	return contract.Render(format, branch, at, seq), nil
+}
+
+// generateUpdatedLimitList return the current limits of the user and the limits after otr is applied
+func (s *transactionService) generateUpdatedLimitList(ctx context.Context, user_id uint, tenor uint, mode entity.LimitPoolMode, otr decimal.Decimal, is_reduce bool) ([]entity.Limit, []entity.Limit, *helpers.BaseResponse) {
+	limits, err := s.limitRepository.FindAllByUserID(ctx, &model.QueryGet{}, user_id)
+	if err != nil || limits == nil {
+		return []entity.Limit{}, []entity.Limit{}, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "User limit not found",
 			Errors:  err,
 		}
 	}
 
+	updatedLimits, errResponse := calculateLimitList(*limits, tenor, mode, otr, is_reduce)
+	return *limits, updatedLimits, errResponse
+}
+
+// calculateLimitList apply otr to the limits consumed under the pool mode, without reading or
+// writing database. Empty mode use the pool mode of the tenor limit
+func calculateLimitList(limits []entity.Limit, tenor uint, mode entity.LimitPoolMode, otr decimal.Decimal, is_reduce bool) ([]entity.Limit, *helpers.BaseResponse) {
+	updatedLimits, err := limit.Apply(limits, tenor, mode, otr, is_reduce)
+	if err != nil {
+		return updatedLimits, limitErrorResponse(err)
+	}
+
 	return updatedLimits, nil
 }
+
+// limitErrorResponse turn the error of the limit calculation into the response of the api
+func limitErrorResponse(err error) *helpers.BaseResponse {
+	switch {
+	case errors.Is(err, limit.ErrFrozen):
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Limit is frozen",
+		}
+	case errors.Is(err, limit.ErrOverlimit):
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Overlimit",
+		}
+	default:
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "User limit tenor not found",
+		}
+	}
+}
+
+// applyMerchant link the dealer of the code to the transaction, empty code is a contract
+// without dealer
+func (s *transactionService) applyMerchant(ctx context.Context, transaction *entity.Transaction, code string) *helpers.BaseResponse {
+	if code == "" {
+		return nil
+	}
+
+	merchant, err := s.merchantRepository.FindByCode(ctx, code)
+	if err != nil || merchant == nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Merchant not found",
+			Errors:  err,
+		}
+	}
+
+	if !merchant.IsActive {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Merchant is not active",
+		}
+	}
+
+	transaction.MerchantID = &merchant.ID
+	transaction.Merchant = merchant
+
+	return nil
+}
+
+// applyProduct check the tenor and otr against the product rules, then set the admin
+// fee and interest of the transaction from the product
+func (s *transactionService) applyProduct(ctx context.Context, transaction *entity.Transaction, code string) *helpers.BaseResponse {
+	product, err := s.productRepository.FindByCode(ctx, code)
+	if err != nil || product == nil {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Product not found",
+			Errors:  err,
+		}
+	}
+
+	if !product.IsActive {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Product is not active",
+		}
+	}
+
+	if !product.AllowTenor(transaction.Tenor) {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Tenor is not offered by the product",
+			Errors:  model.ProductTenorToModels(product.Tenors),
+		}
+	}
+
+	if !product.AllowOnTheRoad(transaction.OnTheRoad) {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "On the road is outside the product range",
+			Errors: map[string]interface{}{
+				"min_on_the_road": product.MinOnTheRoad,
+				"max_on_the_road": product.MaxOnTheRoad,
+			},
+		}
+	}
+
+	transaction.ProductID = &product.ID
+	transaction.Product = product
+	transaction.AdminFee = product.AdminFee(transaction.OnTheRoad)
+	transaction.InterestMethod = product.InterestMethod
+	transaction.InterestRate = product.InterestRate
+
+	return nil
+}
+
+// generateSchedule build amortization schedule of the transaction and fill
+// the interest total and monthly installment from it
+func (s *transactionService) generateSchedule(transaction *entity.Transaction) (*amortization.Schedule, error) {
+	principal := transaction.OnTheRoad.Add(transaction.AdminFee)
+
+	schedule, err := amortization.Generate(transaction.InterestMethod, principal, transaction.InterestRate, transaction.Tenor)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.InterestAmount = schedule.TotalInterest
+	transaction.MonthlyInstallment = schedule.MonthlyInstallment()
+
+	return schedule, nil
+}
+
+// generateDueDates return the due date of every installment on the transaction billing day,
+// moved off weekend and holiday. The transaction end on the last due date
+func (s *transactionService) generateDueDates(ctx context.Context, transaction *entity.Transaction) ([]time.Time, *helpers.BaseResponse) {
+	// A month past the last due date is more than any run of holiday can shift it
+	last := calendar.DueDate(transaction.StartDate, transaction.Tenor, transaction.BillingDay)
+	holidays, err := s.holidayRepository.FindAllBetween(ctx, transaction.StartDate, last.AddDate(0, 1, 0))
+	if err != nil || holidays == nil {
+		return nil, &helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving holiday data",
+		}
+	}
+
+	holidayDates := make([]time.Time, len(*holidays))
+	for i, holiday := range *holidays {
+		holidayDates[i] = holiday.Date
+	}
+
+	dueDates := calendar.New(holidayDates).DueDates(transaction.StartDate, transaction.Tenor, transaction.BillingDay)
+	if len(dueDates) > 0 {
+		transaction.EndDate = dueDates[len(dueDates)-1]
+	}
+
+	return dueDates, nil
+}
+
+// generateInstallmentList pair schedule row with the due date of the same installment number
+func generateInstallmentList(transaction_id uint, due_dates []time.Time, schedule *amortization.Schedule) []entity.TransactionInstallment {
+	installments := make([]entity.TransactionInstallment, len(schedule.Rows))
+	for i, row := range schedule.Rows {
+		dueDate := due_dates[row.Number-1]
+		installments[i] = entity.TransactionInstallment{
+			TransactionID:     transaction_id,
+			InstallmentNumber: row.Number,
+			PrincipalDue:      row.Principal,
+			InterestDue:       row.Interest,
+			AmountDue:         row.Amount,
+			Outstanding:       row.Balance,
+			DueDate:           dueDate,
+		}
+	}
+
+	return installments
+}
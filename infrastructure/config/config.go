@@ -36,6 +36,9 @@ type Config struct {
 	// Caching
 	CacheExp int `mapstructure:"CACHE_EXPIRATION"`
 
+	// Idempotency key lifetime, in hours
+	IdempotencyKeyExp int `mapstructure:"IDEMPOTENCY_KEY_EXPIRATION"`
+
 	// Whitelist IP
 	AllowedIPs string `mapstructure:"ALLOWED_IP"`
 
@@ -45,6 +48,37 @@ type Config struct {
 	SmtpHost     string `mapstructure:"SMTP_HOST"`
 	SmtPort      string `mapstructure:"SMTP_PORT"`
 
+	// Early settlement (payoff), rate in percent
+	PayoffInterestRebate float64 `mapstructure:"PAYOFF_INTEREST_REBATE"`
+	PayoffFeeRate        float64 `mapstructure:"PAYOFF_FEE_RATE"`
+
+	// Overdue job interval, in minutes
+	OverdueJobInterval int `mapstructure:"OVERDUE_JOB_INTERVAL"`
+
+	// Payment gateway callback, shared secret of the HMAC signature
+	PaymentWebhookSecret string `mapstructure:"PAYMENT_WEBHOOK_SECRET"`
+
+	// Partner request timestamp must be within this many seconds of server time
+	PartnerSignatureTolerance int `mapstructure:"PARTNER_SIGNATURE_TOLERANCE"`
+	// Hex of 32 bytes, encrypt partner secrets at rest with AES-256-GCM
+	PartnerSecretKey string `mapstructure:"PARTNER_SECRET_KEY"`
+
+	// Checkout session hold the limit for this many minutes, the expiry job run every interval minutes
+	CheckoutSessionExp  int `mapstructure:"CHECKOUT_SESSION_EXPIRATION"`
+	CheckoutJobInterval int `mapstructure:"CHECKOUT_JOB_INTERVAL"`
+
+	// Contract number, format token are listed in domain/contract
+	ContractNumberFormat string `mapstructure:"CONTRACT_NUMBER_FORMAT"`
+	ContractBranch       string `mapstructure:"CONTRACT_BRANCH"`
+
+	// Day of month installment fall due, 0 use the day the contract start (anniversary)
+	BillingDay uint `mapstructure:"BILLING_DAY"`
+
+	// Underwriting, contract with otr above the threshold (0 is off) or from user whose
+	// latest credit grade is listed (comma separated) wait for approval
+	ApprovalOtrThreshold float64 `mapstructure:"APPROVAL_OTR_THRESHOLD"`
+	ApprovalRiskGrades   string  `mapstructure:"APPROVAL_RISK_GRADES"`
+
 	// Redis
 	RedisAddress  string `mapstructure:"REDIS_ADDRESS"`
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
@@ -91,6 +125,18 @@ func LoadConfig() error {
 	viper.SetDefault("PORT", "4000")
 	viper.SetDefault("JWT_ACCESS_TIME", 30)
 	viper.SetDefault("JWT_REFRESH_TIME", 168)
+	viper.SetDefault("PAYOFF_INTEREST_REBATE", 100)
+	viper.SetDefault("PAYOFF_FEE_RATE", 1)
+	viper.SetDefault("OVERDUE_JOB_INTERVAL", 60)
+	viper.SetDefault("IDEMPOTENCY_KEY_EXPIRATION", 24)
+	viper.SetDefault("PARTNER_SIGNATURE_TOLERANCE", 300)
+	viper.SetDefault("CHECKOUT_SESSION_EXPIRATION", 30)
+	viper.SetDefault("CHECKOUT_JOB_INTERVAL", 5)
+	viper.SetDefault("CONTRACT_NUMBER_FORMAT", "KTR/{branch}/{yyyyMM}/{seq:6}")
+	viper.SetDefault("CONTRACT_BRANCH", "HO")
+	viper.SetDefault("BILLING_DAY", 0)
+	viper.SetDefault("APPROVAL_OTR_THRESHOLD", 0)
+	viper.SetDefault("APPROVAL_RISK_GRADES", "")
 
 	AppConfig = &Config{}
 	if err := viper.Unmarshal(AppConfig); err != nil {
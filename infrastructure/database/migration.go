@@ -15,7 +15,32 @@ func Migrate(db *gorm.DB) {
 	db.AutoMigrate(&entity.UserDocument{})
 	db.AutoMigrate(&entity.RefreshToken{})
 	db.AutoMigrate(&entity.Limit{})
+	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.ProductTenor{})
+	db.AutoMigrate(&entity.ProductFeeTier{})
+	db.AutoMigrate(&entity.Holiday{})
+	db.AutoMigrate(&entity.Merchant{})
+	db.AutoMigrate(&entity.PartnerKey{})
+	db.AutoMigrate(&entity.PartnerConsent{})
 	db.AutoMigrate(&entity.Transaction{})
+	db.AutoMigrate(&entity.CheckoutSession{})
+	db.AutoMigrate(&entity.ContractSequence{})
 	db.AutoMigrate(&entity.TransactionInstallment{})
 	db.AutoMigrate(&entity.Payment{})
+	db.AutoMigrate(&entity.TransactionRevision{})
+	db.AutoMigrate(&entity.TransactionStatusLog{})
+	db.AutoMigrate(&entity.Disbursement{})
+	db.AutoMigrate(&entity.PenaltyPolicy{})
+	db.AutoMigrate(&entity.InstallmentPenalty{})
+	db.AutoMigrate(&entity.PaymentAllocation{})
+	db.AutoMigrate(&entity.CustomerCredit{})
+	db.AutoMigrate(&entity.PaymentCallback{})
+	db.AutoMigrate(&entity.LedgerAccount{})
+	db.AutoMigrate(&entity.JournalEntry{})
+	db.AutoMigrate(&entity.JournalPosting{})
+	db.AutoMigrate(&entity.LimitChange{})
+	db.AutoMigrate(&entity.LimitPolicy{})
+	db.AutoMigrate(&entity.LimitPolicyItem{})
+	db.AutoMigrate(&entity.CreditScore{})
+	db.AutoMigrate(&entity.CreditScoreFactor{})
 }
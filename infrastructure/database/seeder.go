@@ -9,8 +9,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Seeding(db *gorm.DB) {
@@ -100,6 +103,48 @@ func Seeding(db *gorm.DB) {
 		}
 	}
 
+	{ // Seeding ledger account
+		var totalAccount int64
+		tx.Model(&entity.LedgerAccount{}).Count(&totalAccount)
+		if totalAccount != int64(len(ledger.Accounts())) {
+			if err := seedingLedgerAccount(tx); err != nil {
+				log.Printf("Seeding ledger account failed: %v", err)
+				tx.Rollback()
+				return
+			}
+
+			log.Println("Success seeding ledger account")
+		}
+	}
+
+	{ // Seeding default limit policy
+		var totalPolicy int64
+		tx.Model(&entity.LimitPolicy{}).Count(&totalPolicy)
+		if totalPolicy == 0 {
+			if err := seedingLimitPolicy(tx); err != nil {
+				log.Printf("Seeding limit policy failed: %v", err)
+				tx.Rollback()
+				return
+			}
+
+			log.Println("Success seeding limit policy")
+		}
+	}
+
+	{ // Seeding default product
+		var totalProduct int64
+		tx.Model(&entity.Product{}).Count(&totalProduct)
+		if totalProduct == 0 {
+			if err := seedingProduct(tx); err != nil {
+				log.Printf("Seeding product failed: %v", err)
+				tx.Rollback()
+				return
+			}
+
+			log.Println("Success seeding product")
+		}
+	}
+
 	// Commit the transaction if everything is successful
 	if err := tx.Commit().Error; err != nil {
 		log.Printf("Transaction commit failed: %v", err)
@@ -501,3 +546,65 @@ func seedingUserGuest(tx *gorm.DB) error {
 
 	return nil
 }
+
+func seedingLedgerAccount(tx *gorm.DB) error {
+	accounts := ledger.Accounts()
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&accounts).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// seedingLimitPolicy create the default policy, the limits every user got before limit
+// policy exist
+func seedingLimitPolicy(tx *gorm.DB) error {
+	policy := entity.LimitPolicy{
+		Name:        "Default",
+		Description: "Default limit for every user",
+		IsActive:    true,
+		PoolMode:    entity.LimitPoolShared,
+		Items: []entity.LimitPolicyItem{
+			{Tenor: 1, Amount: decimal.NewFromInt(100000)},
+			{Tenor: 2, Amount: decimal.NewFromInt(200000)},
+			{Tenor: 3, Amount: decimal.NewFromInt(500000)},
+			{Tenor: 6, Amount: decimal.NewFromInt(700000)},
+		},
+	}
+
+	if err := tx.Create(&policy).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// seedingProduct create the default product, it offer every tenor of the default limit
+// policy without fee and interest like transaction before product exist
+func seedingProduct(tx *gorm.DB) error {
+	product := entity.Product{
+		Code:           "DEFAULT",
+		Name:           "Default",
+		Description:    "Default product without fee and interest",
+		InterestMethod: entity.InterestFlat,
+		InterestRate:   decimal.Zero,
+		AdminFeeType:   entity.AdminFeeFlat,
+		AdminFeeAmount: decimal.Zero,
+		AdminFeeRate:   decimal.Zero,
+		MinOnTheRoad:   decimal.Zero,
+		IsActive:       true,
+		Tenors: []entity.ProductTenor{
+			{Tenor: 1},
+			{Tenor: 2},
+			{Tenor: 3},
+			{Tenor: 6},
+		},
+	}
+
+	if err := tx.Create(&product).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -53,6 +53,11 @@ func (c *CacheClient) Set(ctx context.Context, key string, value any, expiration
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX set the key only when it does not exist yet, returning false when it does
+func (c *CacheClient) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (c *CacheClient) Del(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
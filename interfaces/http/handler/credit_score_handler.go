@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type CreditScoreHandler interface {
+	GetCreditScore(c *fiber.Ctx) error
+	CalculateCreditScore(c *fiber.Ctx) error
+}
+
+type creditScoreHandler struct {
+	service service.CreditScoreService
+}
+
+func NewCreditScoreHandler(service service.CreditScoreService) CreditScoreHandler {
+	return &creditScoreHandler{
+		service: service,
+	}
+}
+
+func (h *creditScoreHandler) GetCreditScore(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	query := new(model.QueryGet)
+
+	if err := c.QueryParser(query); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request query",
+			Log:     &logData,
+			Errors:  err,
+		})
+
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	uuid, err := uuid.Parse(c.Params("user_uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+			Log:     &logData,
+			Errors:  err,
+		})
+
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	model.SanitizeQueryGet(query)
+
+	url := c.BaseURL() + c.OriginalURL()
+	response = h.service.GetAllByUser(ctx, uuid, query, url)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *creditScoreHandler) CalculateCreditScore(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	uuid, err := uuid.Parse(c.Params("user_uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.Calculate(ctx, uuid)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
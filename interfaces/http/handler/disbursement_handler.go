@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type DisbursementHandler interface {
+	GetDisbursement(c *fiber.Ctx) error
+	GetAllDisbursement(c *fiber.Ctx) error
+	GetOwed(c *fiber.Ctx) error
+	ChangeStatus(c *fiber.Ctx) error
+}
+
+type disbursementHandler struct {
+	service service.DisbursementService
+}
+
+func NewDisbursementHandler(service service.DisbursementService) DisbursementHandler {
+	return &disbursementHandler{
+		service: service,
+	}
+}
+
+func (h *disbursementHandler) GetDisbursement(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID Format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.GetByUUID(ctx, uuid)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *disbursementHandler) GetAllDisbursement(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	query := new(model.QueryGet)
+
+	if err := c.QueryParser(query); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request query",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		model.SanitizeQueryGet(query)
+
+		url := c.BaseURL() + c.OriginalURL()
+		response = h.service.GetAll(ctx, query, url)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *disbursementHandler) GetOwed(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	response := h.service.GetOwed(ctx)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *disbursementHandler) ChangeStatus(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	var input model.DisbursementStatusInput
+
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID Format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.ChangeStatus(ctx, &input, uuid)
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
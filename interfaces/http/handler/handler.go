@@ -10,10 +10,19 @@ type UserManagementHandler struct {
 }
 
 type TransactionManagementHandler struct {
-	LimitHandler       LimitHandler
-	TransactionHandler TransactionHandler
-	InstallmentHandler InstallmentHandler
-	PaymentHandler     PaymentHandler
+	LimitHandler        LimitHandler
+	LimitPolicyHandler  LimitPolicyHandler
+	ProductHandler      ProductHandler
+	TransactionHandler  TransactionHandler
+	InstallmentHandler  InstallmentHandler
+	PaymentHandler      PaymentHandler
+	PenaltyHandler      PenaltyHandler
+	LedgerHandler       LedgerHandler
+	CreditScoreHandler  CreditScoreHandler
+	HolidayHandler      HolidayHandler
+	MerchantHandler     MerchantHandler
+	DisbursementHandler DisbursementHandler
+	PartnerHandler      PartnerHandler
 }
 
 type Handlers struct {
@@ -21,4 +30,5 @@ type Handlers struct {
 	AuthHandler                  AuthHandler
 	RegistrationHandler          RegistrationHandler
 	TransactionManagementHandler *TransactionManagementHandler
+	WebhookHandler               WebhookHandler
 }
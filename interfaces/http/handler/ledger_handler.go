@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type LedgerHandler interface {
+	GetTrialBalance(c *fiber.Ctx) error
+}
+
+type ledgerHandler struct {
+	service service.LedgerService
+}
+
+func NewLedgerHandler(service service.LedgerService) LedgerHandler {
+	return &ledgerHandler{
+		service: service,
+	}
+}
+
+// GetTrialBalance as_of query is a date, entries posted until the end of that date are included
+func (h *ledgerHandler) GetTrialBalance(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+
+	as_of := time.Now()
+	if query := c.Query("as_of"); query != "" {
+		date, err := time.ParseInLocation(time.DateOnly, query, time.Local)
+		if err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request query",
+				Log:     &logData,
+				Errors:  err,
+			})
+			return helpers.ResponseFormatter(c, response)
+		}
+		as_of = date.AddDate(0, 0, 1)
+	}
+
+	response = h.service.TrialBalance(ctx, as_of)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +13,10 @@ func Cache() func(*fiber.Ctx) error {
 	cfg := config.AppConfig
 
 	return cache.New(cache.Config{
+		// Partner response is per signed request, cached one would skip the signature check
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasPrefix(c.Path(), "/api/v1/partner")
+		},
 		CacheControl: true,
 		Expiration:   time.Duration(cfg.CacheExp) * time.Second,
 	})
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+const (
+	idempotencyHeader     = "Idempotency-Key"
+	idempotencyProcessing = "processing"
+	idempotencyDone       = "done"
+)
+
+// IdempotencyStore keep the idempotency records, satisfied by redis.CacheClient
+type IdempotencyStore interface {
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	GetObject(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value any, expiration time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// Global variable to hold the store of idempotency keys
+var idempotencyCache IdempotencyStore
+
+type idempotencyRecord struct {
+	Fingerprint string               `json:"fingerprint"`
+	State       string               `json:"state"`
+	Response    helpers.BaseResponse `json:"response"`
+}
+
+func InitIdempotency(cacheRedis *redis.CacheClient) {
+	// Nil client must leave the store nil, not a nil pointer in the interface
+	if cacheRedis == nil {
+		idempotencyCache = nil
+		return
+	}
+	idempotencyCache = cacheRedis
+}
+
+// SetIdempotencyStore replace the store of idempotency keys, used by tests
+func SetIdempotencyStore(store IdempotencyStore) {
+	idempotencyCache = store
+}
+
+// Idempotency replay the first response of a request sent again with the same
+// Idempotency-Key header, so a client retrying on timeout does not create data twice.
+// Key is scoped by route and user, or by client ip for unauthenticated request, and a key
+// reused with a different body is rejected.
+// Request without the header is passed as is.
+//
+// ! Important, when used with Authentication or PartnerSignature this middleware must be called after it
+func Idempotency() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(idempotencyHeader)
+		if key == "" || idempotencyCache == nil {
+			return c.Next()
+		}
+
+		if len(key) > 255 {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Idempotency-Key must not exceed 255 characters",
+			})
+		}
+
+		owner := "guest:" + c.IP()
+		if user_id, ok := c.Locals("user_id").(float64); ok {
+			owner = fmt.Sprintf("%.0f", user_id)
+		} else if partner_id, ok := c.Locals("partner_id").(uint); ok {
+			owner = fmt.Sprintf("partner:%d", partner_id)
+		}
+		cache_key := fmt.Sprintf("idempotency:%s:%s:%s:%s", c.Method(), c.Route().Path, owner, key)
+
+		hash := sha256.Sum256(append([]byte(c.Method()+" "+c.Path()+"\n"), c.Body()...))
+		fingerprint := hex.EncodeToString(hash[:])
+
+		ctx := context.Background()
+		expiration := time.Duration(config.AppConfig.IdempotencyKeyExp) * time.Hour
+
+		processing, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, State: idempotencyProcessing})
+		reserved, err := idempotencyCache.SetNX(ctx, cache_key, processing, expiration)
+		if err != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Failed to check Idempotency-Key",
+				Errors:  err,
+			})
+		}
+
+		if !reserved {
+			var record idempotencyRecord
+			if err := idempotencyCache.GetObject(ctx, cache_key, &record); err != nil {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusInternalServerError,
+					Success: false,
+					Message: "Failed to check Idempotency-Key",
+					Errors:  err,
+				})
+			}
+
+			if record.Fingerprint != fingerprint {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusUnprocessableEntity,
+					Success: false,
+					Message: "Idempotency-Key already used with a different request",
+				})
+			}
+
+			if record.State != idempotencyDone {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusConflict,
+					Success: false,
+					Message: "Request with the same Idempotency-Key is still processed",
+				})
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			return helpers.ResponseFormatter(c, record.Response)
+		}
+
+		if err := c.Next(); err != nil {
+			idempotencyCache.Del(ctx, cache_key)
+			return err
+		}
+
+		// Server error is not stored so the client can retry with the same key
+		var response helpers.BaseResponse
+		if c.Response().StatusCode() >= fiber.StatusInternalServerError ||
+			json.Unmarshal(c.Response().Body(), &response) != nil {
+			idempotencyCache.Del(ctx, cache_key)
+			return nil
+		}
+
+		done, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, State: idempotencyDone, Response: response})
+		idempotencyCache.Set(ctx, cache_key, done, expiration)
+
+		return nil
+	}
+}
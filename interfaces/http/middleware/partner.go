@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+const (
+	PartnerKeyHeader       = "X-Partner-Key"
+	PartnerTimestampHeader = "X-Partner-Timestamp"
+	PartnerNonceHeader     = "X-Partner-Nonce"
+	PartnerSignatureHeader = "X-Partner-Signature"
+)
+
+// Global variable to hold partner credential lookup and redis client used to store nonces
+var (
+	partnerKeyRepository repository.PartnerKeyRepository
+	partnerNonceCache    *redis.CacheClient
+)
+
+func InitPartnerSignature(partnerKeyRepo repository.PartnerKeyRepository, cacheRedis *redis.CacheClient) {
+	partnerKeyRepository = partnerKeyRepo
+	partnerNonceCache = cacheRedis
+}
+
+// PartnerSignature authenticate merchant backend request. Signature is hex HMAC SHA256,
+// keyed by the partner secret decrypted with PARTNER_SECRET_KEY, of helpers.PartnerSigningPayload.
+// Timestamp (unix seconds) must be within PARTNER_SIGNATURE_TOLERANCE and a nonce is accepted
+// once, so a captured request can not be replayed. Merchant id is set to context local
+func PartnerSignature() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key_id := c.Get(PartnerKeyHeader)
+		timestamp := c.Get(PartnerTimestampHeader)
+		nonce := c.Get(PartnerNonceHeader)
+		signature := c.Get(PartnerSignatureHeader)
+
+		if key_id == "" || timestamp == "" || nonce == "" || signature == "" || len(nonce) > 64 {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Missing partner signature headers",
+			})
+		}
+
+		tolerance := time.Duration(config.AppConfig.PartnerSignatureTolerance) * time.Second
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(unix, 0)).Abs() > tolerance {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Request timestamp is outside the allowed window",
+			})
+		}
+
+		if partnerKeyRepository == nil || partnerNonceCache == nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusServiceUnavailable,
+				Success: false,
+				Message: "Partner API is not available",
+			})
+		}
+
+		ctx := context.Background()
+		key, err := partnerKeyRepository.FindByKeyID(ctx, key_id)
+		if err != nil || key == nil || !key.IsActive() || key.Merchant == nil || !key.Merchant.IsActive {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Invalid signature",
+			})
+		}
+
+		secret_key, err := helpers.PartnerSecretKey()
+		if err != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusServiceUnavailable,
+				Success: false,
+				Message: "Partner API is not available",
+			})
+		}
+		secret, err := helpers.DecryptSecret(secret_key, key.SecretCipher)
+		if err != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Invalid signature",
+			})
+		}
+
+		payload := helpers.PartnerSigningPayload(c.Method(), c.OriginalURL(), timestamp, nonce, c.Body())
+		if !helpers.ValidSignature(secret, payload, signature) {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Invalid signature",
+			})
+		}
+
+		// Nonce outlive the timestamp window, after it the timestamp check reject the request
+		nonce_key := fmt.Sprintf("partner:nonce:%s:%s", key.KeyID, nonce)
+		fresh, err := partnerNonceCache.SetNX(ctx, nonce_key, timestamp, 2*tolerance)
+		if err != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Failed to check request nonce",
+				Errors:  err,
+			})
+		}
+		if !fresh {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Request nonce already used",
+			})
+		}
+
+		c.Locals("partner_id", key.MerchantID)
+		c.Locals("name", "partner "+key.KeyID)
+
+		return c.Next()
+	}
+}
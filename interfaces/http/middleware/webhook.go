@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+const WebhookSignatureHeader = "X-Callback-Signature"
+
+// WebhookSignature verify payment gateway callback is signed with the shared secret,
+// signature is hex HMAC SHA256 of the raw request body
+func WebhookSignature() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		secret := config.AppConfig.PaymentWebhookSecret
+		signature := c.Get(WebhookSignatureHeader)
+
+		if secret == "" || signature == "" || !helpers.ValidSignature(secret, c.Body(), signature) {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Invalid signature",
+			})
+		}
+
+		return c.Next()
+	}
+}
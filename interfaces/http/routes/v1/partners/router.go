@@ -0,0 +1,35 @@
+package partners
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterRoutes(route fiber.Router, handler handler.PartnerHandler) {
+	partnerRoutes := route.Group("/partner")
+
+	partnerRoutes.Use(middleware.PartnerSignature())
+
+	partnerRoutes.Get(
+		"/customers/:uuid/limit",
+		handler.GetCustomerLimit,
+	)
+
+	partnerRoutes.Post(
+		"/transactions",
+		middleware.Idempotency(),
+		handler.CreateTransaction,
+	)
+
+	partnerRoutes.Post(
+		"/checkouts",
+		middleware.Idempotency(),
+		handler.CreateCheckout,
+	)
+
+	partnerRoutes.Get(
+		"/checkouts/:uuid",
+		handler.GetCheckout,
+	)
+}
@@ -9,7 +9,7 @@ import (
 func RegisterRoutes(route fiber.Router, handler handler.RegistrationHandler) {
 	registrationRoutes := route.Group("/registration")
 
-	registrationRoutes.Post("/", handler.Register)
+	registrationRoutes.Post("/", middleware.Idempotency(), handler.Register)
 
 	registrationRoutes.Post(
 		"/activate/:uuid",
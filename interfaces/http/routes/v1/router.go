@@ -4,9 +4,11 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/auth"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/partners"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/registrations"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/transactions"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/users"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/webhooks"
 )
 
 func RegisterRoutes(route fiber.Router, handler *handler.Handlers) {
@@ -16,4 +18,6 @@ func RegisterRoutes(route fiber.Router, handler *handler.Handlers) {
 	auth.RegisterRoutes(v1, handler.AuthHandler)
 	registrations.RegisterRoutes(v1, handler.RegistrationHandler)
 	transactions.RegisterRoutes(v1, handler.TransactionManagementHandler)
+	webhooks.RegisterRoutes(v1, handler.WebhookHandler)
+	partners.RegisterRoutes(v1, handler.TransactionManagementHandler.PartnerHandler)
 }
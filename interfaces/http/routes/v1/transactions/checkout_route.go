@@ -0,0 +1,37 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterCheckoutRoutes(route fiber.Router, handler handler.TransactionHandler) {
+	checkout := route.Group("/checkout")
+
+	checkout.Use(middleware.Authentication())
+
+	checkout.Get(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetAllCheckout,
+	)
+
+	checkout.Get(
+		"/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetCheckout,
+	)
+
+	checkout.Post(
+		"/:uuid/confirm",
+		middleware.Authorization(false, true, []string{}),
+		handler.ConfirmCheckout,
+	)
+
+	checkout.Post(
+		"/:uuid/cancel",
+		middleware.Authorization(false, true, []string{}),
+		handler.CancelCheckout,
+	)
+}
@@ -0,0 +1,31 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterConsentRoutes(route fiber.Router, handler handler.PartnerHandler) {
+	consent := route.Group("/consent")
+
+	consent.Use(middleware.Authentication())
+
+	consent.Get(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetAllConsent,
+	)
+
+	consent.Post(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GrantConsent,
+	)
+
+	consent.Delete(
+		"/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		handler.RevokeConsent,
+	)
+}
@@ -0,0 +1,25 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterCreditScoreRoutes(route fiber.Router, handler handler.CreditScoreHandler) {
+	creditScore := route.Group("/credit-score")
+
+	creditScore.Use(middleware.Authentication())
+
+	creditScore.Get(
+		"/:user_uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetCreditScore,
+	)
+
+	creditScore.Post(
+		"/:user_uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.CalculateCreditScore,
+	)
+}
@@ -0,0 +1,37 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterDisbursementRoutes(route fiber.Router, handler handler.DisbursementHandler) {
+	disbursement := route.Group("/disbursement")
+
+	disbursement.Use(middleware.Authentication())
+
+	disbursement.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetAllDisbursement,
+	)
+
+	disbursement.Get(
+		"/owed",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetOwed,
+	)
+
+	disbursement.Get(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetDisbursement,
+	)
+
+	disbursement.Post(
+		"/status/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.ChangeStatus,
+	)
+}
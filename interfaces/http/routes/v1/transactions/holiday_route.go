@@ -0,0 +1,43 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterHolidayRoutes(route fiber.Router, handler handler.HolidayHandler) {
+	holiday := route.Group("/holiday")
+
+	holiday.Use(middleware.Authentication())
+
+	holiday.Get(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetAllHoliday,
+	)
+
+	holiday.Get(
+		"/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetHoliday,
+	)
+
+	holiday.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateHoliday,
+	)
+
+	holiday.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateHoliday,
+	)
+
+	holiday.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteHoliday,
+	)
+}
@@ -0,0 +1,19 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterLedgerRoutes(route fiber.Router, handler handler.LedgerHandler) {
+	ledger := route.Group("/ledger")
+
+	ledger.Use(middleware.Authentication())
+
+	ledger.Get(
+		"/trial-balance",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetTrialBalance,
+	)
+}
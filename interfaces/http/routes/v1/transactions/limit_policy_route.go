@@ -0,0 +1,49 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterLimitPolicyRoutes(route fiber.Router, handler handler.LimitPolicyHandler) {
+	policy := route.Group("/limit-policy")
+
+	policy.Use(middleware.Authentication())
+
+	policy.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetAllLimitPolicy,
+	)
+
+	policy.Get(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetLimitPolicy,
+	)
+
+	policy.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateLimitPolicy,
+	)
+
+	policy.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateLimitPolicy,
+	)
+
+	policy.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteLimitPolicy,
+	)
+
+	policy.Post(
+		"/:uuid/apply",
+		middleware.Authorization(true, false, []string{}),
+		handler.ApplyLimitPolicy,
+	)
+}
@@ -16,4 +16,46 @@ func RegisterLimitRoutes(route fiber.Router, handler handler.LimitHandler) {
 		middleware.Authorization(false, true, []string{}),
 		handler.GetLimit,
 	)
+
+	limit.Get(
+		"/:user_uuid/history",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetLimitHistory,
+	)
+
+	limit.Post(
+		"/:user_uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.Create,
+	)
+
+	limit.Post(
+		"/:user_uuid/raise",
+		middleware.Authorization(true, false, []string{}),
+		handler.Raise,
+	)
+
+	limit.Post(
+		"/:user_uuid/lower",
+		middleware.Authorization(true, false, []string{}),
+		handler.Lower,
+	)
+
+	limit.Post(
+		"/:user_uuid/freeze",
+		middleware.Authorization(true, false, []string{}),
+		handler.Freeze,
+	)
+
+	limit.Post(
+		"/:user_uuid/unfreeze",
+		middleware.Authorization(true, false, []string{}),
+		handler.Unfreeze,
+	)
+
+	limit.Delete(
+		"/:user_uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.Remove,
+	)
 }
@@ -0,0 +1,44 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterMerchantRoutes(route fiber.Router, handler handler.MerchantHandler) {
+	merchant := route.Group("/merchant")
+
+	merchant.Use(middleware.Authentication())
+
+	merchant.Get(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetAllMerchant,
+	)
+
+	// Detail show the bank account, it is for admin only
+	merchant.Get(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetMerchant,
+	)
+
+	merchant.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateMerchant,
+	)
+
+	merchant.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateMerchant,
+	)
+
+	merchant.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteMerchant,
+	)
+}
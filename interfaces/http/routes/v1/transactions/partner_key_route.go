@@ -0,0 +1,31 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterPartnerKeyRoutes(route fiber.Router, handler handler.PartnerHandler) {
+	partnerKey := route.Group("/partner-key")
+
+	partnerKey.Use(middleware.Authentication())
+
+	partnerKey.Get(
+		"/merchant/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetAllPartnerKey,
+	)
+
+	partnerKey.Post(
+		"/merchant/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.IssuePartnerKey,
+	)
+
+	partnerKey.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.RevokePartnerKey,
+	)
+}
@@ -26,6 +26,33 @@ func RegisterPaymentRoutes(route fiber.Router, handler handler.PaymentHandler) {
 	payment.Post(
 		"/",
 		middleware.Authorization(false, true, []string{}),
+		middleware.Idempotency(),
 		handler.Create,
 	)
+
+	payment.Get(
+		"/payoff/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetPayoffQuote,
+	)
+
+	payment.Post(
+		"/payoff/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		middleware.Idempotency(),
+		handler.Payoff,
+	)
+
+	payment.Post(
+		"/credit/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		middleware.Idempotency(),
+		handler.ApplyCredit,
+	)
+
+	payment.Post(
+		"/reverse/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.Reverse,
+	)
 }
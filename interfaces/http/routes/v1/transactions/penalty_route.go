@@ -0,0 +1,43 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterPenaltyRoutes(route fiber.Router, handler handler.PenaltyHandler) {
+	penalty := route.Group("/penalty-policy")
+
+	penalty.Use(middleware.Authentication())
+
+	penalty.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetAllPenaltyPolicy,
+	)
+
+	penalty.Get(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetPenaltyPolicy,
+	)
+
+	penalty.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreatePenaltyPolicy,
+	)
+
+	penalty.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdatePenaltyPolicy,
+	)
+
+	penalty.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeletePenaltyPolicy,
+	)
+}
@@ -0,0 +1,44 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterProductRoutes(route fiber.Router, handler handler.ProductHandler) {
+	product := route.Group("/product")
+
+	product.Use(middleware.Authentication())
+
+	// Every user can read the catalog to pick a product code
+	product.Get(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetAllProduct,
+	)
+
+	product.Get(
+		"/:uuid",
+		middleware.Authorization(false, true, []string{}),
+		handler.GetProduct,
+	)
+
+	product.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateProduct,
+	)
+
+	product.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateProduct,
+	)
+
+	product.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteProduct,
+	)
+}
@@ -9,7 +9,20 @@ func RegisterRoutes(route fiber.Router, handler *handler.TransactionManagementHa
 	transactions := route.Group("/transactions/")
 
 	RegisterLimitRoutes(transactions, handler.LimitHandler)
+	RegisterLimitPolicyRoutes(transactions, handler.LimitPolicyHandler)
+	RegisterProductRoutes(transactions, handler.ProductHandler)
 	RegisterTransactionRoutes(transactions, handler.TransactionHandler)
+	RegisterSimulationRoutes(transactions, handler.TransactionHandler)
+	RegisterUnderwritingRoutes(transactions, handler.TransactionHandler)
+	RegisterCheckoutRoutes(transactions, handler.TransactionHandler)
 	RegisterInstallmentRoutes(transactions, handler.InstallmentHandler)
 	RegisterPaymentRoutes(transactions, handler.PaymentHandler)
+	RegisterPenaltyRoutes(transactions, handler.PenaltyHandler)
+	RegisterLedgerRoutes(transactions, handler.LedgerHandler)
+	RegisterCreditScoreRoutes(transactions, handler.CreditScoreHandler)
+	RegisterHolidayRoutes(transactions, handler.HolidayHandler)
+	RegisterMerchantRoutes(transactions, handler.MerchantHandler)
+	RegisterDisbursementRoutes(transactions, handler.DisbursementHandler)
+	RegisterPartnerKeyRoutes(transactions, handler.PartnerHandler)
+	RegisterConsentRoutes(transactions, handler.PartnerHandler)
 }
@@ -17,6 +17,13 @@ func RegisterTransactionRoutes(route fiber.Router, handler handler.TransactionHa
 		handler.GetTransaction,
 	)
 
+	// Contract pdf, only the owner or admin can download it
+	transaction.Get(
+		"/:uuid/contract",
+		middleware.Authorization(false, true, []string{}),
+		handler.DownloadContract,
+	)
+
 	transaction.Get(
 		"/",
 		middleware.Authorization(false, true, []string{}),
@@ -26,12 +33,47 @@ func RegisterTransactionRoutes(route fiber.Router, handler handler.TransactionHa
 	transaction.Post(
 		"/",
 		middleware.Authorization(false, true, []string{}),
+		middleware.Idempotency(),
 		handler.Create,
 	)
 
+	// Legacy contract keep its own contract number, admin only
+	transaction.Post(
+		"/import",
+		middleware.Authorization(true, false, []string{}),
+		middleware.Idempotency(),
+		handler.Import,
+	)
+
+	// Amendment of a disbursed contract is for admin only
+	transaction.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.Update,
+	)
+
 	transaction.Post(
 		"/cancel/:uuid",
 		middleware.Authorization(false, true, []string{}),
 		handler.Cancel,
 	)
+
+	// Default, cure and write off, payment and cancel move the status on their own
+	transaction.Post(
+		"/status/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.ChangeStatus,
+	)
+}
+
+func RegisterSimulationRoutes(route fiber.Router, handler handler.TransactionHandler) {
+	simulation := route.Group("/simulate")
+
+	simulation.Use(middleware.Authentication())
+
+	simulation.Post(
+		"/",
+		middleware.Authorization(false, true, []string{}),
+		handler.Simulate,
+	)
 }
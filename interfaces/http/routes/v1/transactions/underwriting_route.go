@@ -0,0 +1,25 @@
+package transactions
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterUnderwritingRoutes(route fiber.Router, handler handler.TransactionHandler) {
+	underwriting := route.Group("/underwriting")
+
+	underwriting.Use(middleware.Authentication())
+
+	underwriting.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetApprovalQueue,
+	)
+
+	underwriting.Post(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.Review,
+	)
+}
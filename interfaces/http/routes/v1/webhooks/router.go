@@ -0,0 +1,17 @@
+package webhooks
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterRoutes(route fiber.Router, handler handler.WebhookHandler) {
+	webhookRoutes := route.Group("/webhooks")
+
+	webhookRoutes.Post(
+		"/payment",
+		middleware.WebhookSignature(),
+		handler.PaymentCallback,
+	)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	CheckoutDetail struct {
+		UUID            uuid.UUID             `json:"uuid"`
+		MerchantCode    string                `json:"merchant_code"`
+		MerchantName    string                `json:"merchant_name"`
+		ProductCode     string                `json:"product_code"`
+		AssetName       string                `json:"asset_name"`
+		OnTheRoad       decimal.Decimal       `json:"on_the_road"`
+		Tenor           uint                  `json:"tenor"`
+		Status          entity.CheckoutStatus `json:"status"`
+		ExpiresAt       time.Time             `json:"expires_at"`
+		ConfirmedAt     *time.Time            `json:"confirmed_at"`
+		TransactionUUID *uuid.UUID            `json:"transaction_uuid"`
+		ContractNumber  string                `json:"contract_number"`
+		CreatedAt       time.Time             `json:"created_at"`
+	}
+)
+
+func CheckoutToDetailModel(session *entity.CheckoutSession) *CheckoutDetail {
+	detail := &CheckoutDetail{
+		UUID:        session.UUID,
+		ProductCode: session.ProductCode,
+		AssetName:   session.AssetName,
+		OnTheRoad:   session.OnTheRoad,
+		Tenor:       session.Tenor,
+		Status:      session.Status,
+		ExpiresAt:   session.ExpiresAt,
+		ConfirmedAt: session.ConfirmedAt,
+		CreatedAt:   session.CreatedAt,
+	}
+
+	if session.Merchant != nil {
+		detail.MerchantCode = session.Merchant.Code
+		detail.MerchantName = session.Merchant.Name
+	}
+
+	if session.Transaction != nil {
+		detail.TransactionUUID = &session.Transaction.UUID
+		detail.ContractNumber = session.Transaction.ContractNumber
+	}
+
+	return detail
+}
+
+func CheckoutToDetailModels(sessions []entity.CheckoutSession) (detailModels []CheckoutDetail) {
+	for _, session := range sessions {
+		detailModels = append(detailModels, *CheckoutToDetailModel(&session))
+	}
+
+	return detailModels
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	CreditScoreDetail struct {
+		UUID            uuid.UUID           `json:"uuid"`
+		UserID          uint                `json:"user_id"`
+		Score           uint                `json:"score"`
+		Grade           entity.CreditGrade  `json:"grade"`
+		LimitMultiplier decimal.Decimal     `json:"limit_multiplier"`
+		Factors         []CreditScoreFactor `json:"factors"`
+		CreatedAt       time.Time           `json:"created_at"`
+	}
+
+	CreditScoreFactor struct {
+		Code        string `json:"code"`
+		Description string `json:"description"`
+		Value       string `json:"value"`
+		Points      int    `json:"points"`
+	}
+)
+
+func CreditScoreToDetailModel(score *entity.CreditScore) *CreditScoreDetail {
+	factors := []CreditScoreFactor{}
+	for _, factor := range score.Factors {
+		factors = append(factors, CreditScoreFactor{
+			Code:        factor.Code,
+			Description: factor.Description,
+			Value:       factor.Value,
+			Points:      factor.Points,
+		})
+	}
+
+	return &CreditScoreDetail{
+		UUID:            score.UUID,
+		UserID:          score.UserID,
+		Score:           score.Score,
+		Grade:           score.Grade,
+		LimitMultiplier: score.LimitMultiplier,
+		Factors:         factors,
+		CreatedAt:       score.CreatedAt,
+	}
+}
+
+func CreditScoreToDetailModels(scores []entity.CreditScore) (detailModels []CreditScoreDetail) {
+	for _, score := range scores {
+		detailModels = append(detailModels, *CreditScoreToDetailModel(&score))
+	}
+
+	return detailModels
+}
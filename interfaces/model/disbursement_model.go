@@ -0,0 +1,128 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	DisbursementDetail struct {
+		ID                uint                      `json:"id"`
+		UUID              uuid.UUID                 `json:"uuid"`
+		TransactionUUID   uuid.UUID                 `json:"transaction_uuid"`
+		ContractNumber    string                    `json:"contract_number"`
+		MerchantCode      string                    `json:"merchant_code"`
+		MerchantName      string                    `json:"merchant_name"`
+		BankName          string                    `json:"bank_name"`
+		BankAccountNumber string                    `json:"bank_account_number"`
+		BankAccountName   string                    `json:"bank_account_name"`
+		Amount            decimal.Decimal           `json:"amount"`
+		DueDate           time.Time                 `json:"due_date"`
+		Status            entity.DisbursementStatus `json:"status"`
+		Reference         string                    `json:"reference"`
+		Notes             string                    `json:"notes"`
+		SentAt            *time.Time                `json:"sent_at"`
+		CreatedAt         time.Time                 `json:"created_at"`
+		UpdatedAt         time.Time                 `json:"updated_at"`
+	}
+
+	DisbursementList struct {
+		ID             uint                      `json:"id"`
+		UUID           uuid.UUID                 `json:"uuid"`
+		ContractNumber string                    `json:"contract_number"`
+		MerchantCode   string                    `json:"merchant_code"`
+		Amount         decimal.Decimal           `json:"amount"`
+		DueDate        time.Time                 `json:"due_date"`
+		Status         entity.DisbursementStatus `json:"status"`
+		SentAt         *time.Time                `json:"sent_at"`
+	}
+
+	// MerchantOwed is the pending and failed disbursement total of a merchant
+	MerchantOwed struct {
+		UUID            uuid.UUID       `json:"uuid"`
+		Code            string          `json:"code"`
+		Name            string          `json:"name"`
+		TotalContract   int64           `json:"total_contract"`
+		Amount          decimal.Decimal `json:"amount"`
+		EarliestDueDate time.Time       `json:"earliest_due_date"`
+	}
+
+	// DisbursementStatusInput mark a disbursement sent with the bank reference or failed
+	// with the reason
+	DisbursementStatusInput struct {
+		Status    string `json:"status" form:"status" xml:"status" validate:"required,oneof=sent failed"`
+		Reference string `json:"reference" form:"reference" xml:"reference" validate:"required_if=Status sent,max=100"`
+		Notes     string `json:"notes" form:"notes" xml:"notes" validate:"required_if=Status failed,max=255"`
+	}
+)
+
+func DisbursementToDetailModel(disbursement *entity.Disbursement) *DisbursementDetail {
+	detail := &DisbursementDetail{
+		ID:        disbursement.ID,
+		UUID:      disbursement.UUID,
+		Amount:    disbursement.Amount,
+		DueDate:   disbursement.DueDate,
+		Status:    disbursement.Status,
+		Reference: disbursement.Reference,
+		Notes:     disbursement.Notes,
+		SentAt:    disbursement.SentAt,
+		CreatedAt: disbursement.CreatedAt,
+		UpdatedAt: disbursement.UpdatedAt,
+	}
+
+	if disbursement.Transaction != nil {
+		detail.TransactionUUID = disbursement.Transaction.UUID
+		detail.ContractNumber = disbursement.Transaction.ContractNumber
+	}
+
+	if disbursement.Merchant != nil {
+		detail.MerchantCode = disbursement.Merchant.Code
+		detail.MerchantName = disbursement.Merchant.Name
+		detail.BankName = disbursement.Merchant.BankName
+		detail.BankAccountNumber = disbursement.Merchant.BankAccountNumber
+		detail.BankAccountName = disbursement.Merchant.BankAccountName
+	}
+
+	return detail
+}
+
+func DisbursementToListModel(disbursement *entity.Disbursement) *DisbursementList {
+	list := &DisbursementList{
+		ID:      disbursement.ID,
+		UUID:    disbursement.UUID,
+		Amount:  disbursement.Amount,
+		DueDate: disbursement.DueDate,
+		Status:  disbursement.Status,
+		SentAt:  disbursement.SentAt,
+	}
+
+	if disbursement.Transaction != nil {
+		list.ContractNumber = disbursement.Transaction.ContractNumber
+	}
+
+	if disbursement.Merchant != nil {
+		list.MerchantCode = disbursement.Merchant.Code
+	}
+
+	return list
+}
+
+func DisbursementToListModels(disbursements []entity.Disbursement) (listModels []DisbursementList) {
+	for _, disbursement := range disbursements {
+		listModels = append(listModels, *DisbursementToListModel(&disbursement))
+	}
+
+	return listModels
+}
+
+func (input *DisbursementStatusInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Status = sanitizer.Sanitize(input.Status)
+	input.Reference = sanitizer.Sanitize(input.Reference)
+	input.Notes = sanitizer.Sanitize(input.Notes)
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	HolidayDetail struct {
+		ID        uint      `json:"id"`
+		UUID      uuid.UUID `json:"uuid"`
+		Date      time.Time `json:"date"`
+		Name      string    `json:"name"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	HolidayList struct {
+		ID   uint      `json:"id"`
+		UUID uuid.UUID `json:"uuid"`
+		Date time.Time `json:"date"`
+		Name string    `json:"name"`
+	}
+
+	HolidayInput struct {
+		Date string `json:"date" form:"date" xml:"date" validate:"required,datetime=2006-01-02"`
+		Name string `json:"name" form:"name" xml:"name" validate:"required,max=255"`
+	}
+)
+
+func HolidayToDetailModel(holiday *entity.Holiday) *HolidayDetail {
+	return &HolidayDetail{
+		ID:        holiday.ID,
+		UUID:      holiday.UUID,
+		Date:      holiday.Date,
+		Name:      holiday.Name,
+		CreatedAt: holiday.CreatedAt,
+		UpdatedAt: holiday.UpdatedAt,
+	}
+}
+
+func HolidayToListModel(holiday *entity.Holiday) *HolidayList {
+	return &HolidayList{
+		ID:   holiday.ID,
+		UUID: holiday.UUID,
+		Date: holiday.Date,
+		Name: holiday.Name,
+	}
+}
+
+func HolidayToListModels(holidays []entity.Holiday) (listModels []HolidayList) {
+	for _, holiday := range holidays {
+		listModels = append(listModels, *HolidayToListModel(&holiday))
+	}
+
+	return listModels
+}
+
+func (input *HolidayInput) ToEntity() (*entity.Holiday, error) {
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Holiday{
+		Date: date,
+		Name: input.Name,
+	}, nil
+}
+
+func (input *HolidayInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Date = sanitizer.Sanitize(input.Date)
+	input.Name = sanitizer.Sanitize(input.Name)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	TrialBalance struct {
+		AsOf        time.Time             `json:"as_of"`
+		Accounts    []TrialBalanceAccount `json:"accounts"`
+		TotalDebit  decimal.Decimal       `json:"total_debit"`
+		TotalCredit decimal.Decimal       `json:"total_credit"`
+		IsBalanced  bool                  `json:"is_balanced"`
+	}
+
+	// TrialBalanceAccount Balance is on the normal side of the account type
+	TrialBalanceAccount struct {
+		Code    string                   `json:"code"`
+		Name    string                   `json:"name"`
+		Type    entity.LedgerAccountType `json:"type"`
+		Debit   decimal.Decimal          `json:"debit"`
+		Credit  decimal.Decimal          `json:"credit"`
+		Balance decimal.Decimal          `json:"balance"`
+	}
+)
+
+func ToTrialBalanceModel(accounts []TrialBalanceAccount, as_of time.Time) *TrialBalance {
+	trialBalance := &TrialBalance{
+		AsOf:        as_of,
+		Accounts:    make([]TrialBalanceAccount, len(accounts)),
+		TotalDebit:  decimal.Zero,
+		TotalCredit: decimal.Zero,
+	}
+
+	for i, account := range accounts {
+		if account.Type.DebitNormal() {
+			account.Balance = account.Debit.Sub(account.Credit)
+		} else {
+			account.Balance = account.Credit.Sub(account.Debit)
+		}
+
+		trialBalance.Accounts[i] = account
+		trialBalance.TotalDebit = trialBalance.TotalDebit.Add(account.Debit)
+		trialBalance.TotalCredit = trialBalance.TotalCredit.Add(account.Credit)
+	}
+	trialBalance.IsBalanced = trialBalance.TotalDebit.Equal(trialBalance.TotalCredit)
+
+	return trialBalance
+}
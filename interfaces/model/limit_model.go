@@ -1,17 +1,53 @@
 package model
 
 import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/shopspring/decimal"
 )
 
 type (
 	LimitList struct {
-		ID            uint            `json:"id"`
-		UserID        uint            `json:"user_id"`
-		Tenor         uint            `json:"tenor"`
-		CurrentLimit  decimal.Decimal `json:"current_limit"`
-		OriginalLimit decimal.Decimal `json:"original_limit"`
+		ID            uint                 `json:"id"`
+		UserID        uint                 `json:"user_id"`
+		Tenor         uint                 `json:"tenor"`
+		CurrentLimit  decimal.Decimal      `json:"current_limit"`
+		OriginalLimit decimal.Decimal      `json:"original_limit"`
+		IsFrozen      bool                 `json:"is_frozen"`
+		PoolMode      entity.LimitPoolMode `json:"pool_mode"`
+	}
+
+	LimitChangeList struct {
+		UUID                  uuid.UUID                `json:"uuid"`
+		LimitID               uint                     `json:"limit_id"`
+		Tenor                 uint                     `json:"tenor"`
+		Action                entity.LimitChangeAction `json:"action"`
+		TransactionUUID       *uuid.UUID               `json:"transaction_uuid"`
+		CheckoutUUID          *uuid.UUID               `json:"checkout_uuid"`
+		Amount                decimal.Decimal          `json:"amount"`
+		PreviousOriginalLimit decimal.Decimal          `json:"previous_original_limit"`
+		PreviousCurrentLimit  decimal.Decimal          `json:"previous_current_limit"`
+		OriginalLimit         decimal.Decimal          `json:"original_limit"`
+		CurrentLimit          decimal.Decimal          `json:"current_limit"`
+		Reason                string                   `json:"reason"`
+		ChangedBy             *uint                    `json:"changed_by"`
+		CreatedAt             time.Time                `json:"created_at"`
+	}
+
+	// LimitInput is used to create, raise or lower a user limit by amount
+	LimitInput struct {
+		Tenor  uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required,min=1"`
+		Amount string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
+		Reason string `json:"reason" form:"reason" xml:"reason" validate:"required,max=255"`
+	}
+
+	// LimitActionInput is used to freeze, unfreeze or remove a user limit
+	LimitActionInput struct {
+		Tenor  uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required,min=1"`
+		Reason string `json:"reason" form:"reason" xml:"reason" validate:"required,max=255"`
 	}
 )
 
@@ -22,6 +58,8 @@ func LimitToListModel(limit *entity.Limit) *LimitList {
 		Tenor:         limit.Tenor,
 		CurrentLimit:  limit.CurrentLimit,
 		OriginalLimit: limit.OriginalLimit,
+		IsFrozen:      limit.FrozenAt.Valid,
+		PoolMode:      limit.PoolMode,
 	}
 }
 
@@ -32,3 +70,43 @@ func LimitToListModels(limits []entity.Limit) (listModels []LimitList) {
 
 	return listModels
 }
+
+func LimitChangeToListModel(change *entity.LimitChange) *LimitChangeList {
+	return &LimitChangeList{
+		UUID:                  change.UUID,
+		LimitID:               change.LimitID,
+		Tenor:                 change.Tenor,
+		Action:                change.Action,
+		TransactionUUID:       change.TransactionUUID,
+		CheckoutUUID:          change.CheckoutUUID,
+		Amount:                change.Amount,
+		PreviousOriginalLimit: change.PreviousOriginalLimit,
+		PreviousCurrentLimit:  change.PreviousCurrentLimit,
+		OriginalLimit:         change.OriginalLimit,
+		CurrentLimit:          change.CurrentLimit,
+		Reason:                change.Reason,
+		ChangedBy:             change.ChangedBy,
+		CreatedAt:             change.CreatedAt,
+	}
+}
+
+func LimitChangeToListModels(changes []entity.LimitChange) (listModels []LimitChangeList) {
+	for _, change := range changes {
+		listModels = append(listModels, *LimitChangeToListModel(&change))
+	}
+
+	return listModels
+}
+
+func (input *LimitInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Amount = sanitizer.Sanitize(input.Amount)
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *LimitActionInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
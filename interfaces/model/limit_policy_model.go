@@ -0,0 +1,231 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	LimitPolicyDetail struct {
+		ID          uint                 `json:"id"`
+		UUID        uuid.UUID            `json:"uuid"`
+		Name        string               `json:"name"`
+		Description string               `json:"description"`
+		MinSalary   decimal.NullDecimal  `json:"min_salary"`
+		MaxSalary   decimal.NullDecimal  `json:"max_salary"`
+		MinAge      *uint                `json:"min_age"`
+		MaxAge      *uint                `json:"max_age"`
+		Priority    int                  `json:"priority"`
+		IsActive    bool                 `json:"is_active"`
+		PoolMode    entity.LimitPoolMode `json:"pool_mode"`
+		GlobalCap   decimal.NullDecimal  `json:"global_cap"`
+		Items       []LimitPolicyItem    `json:"items"`
+		CreatedAt   time.Time            `json:"created_at"`
+		UpdatedAt   time.Time            `json:"updated_at"`
+	}
+
+	LimitPolicyList struct {
+		ID        uint                 `json:"id"`
+		UUID      uuid.UUID            `json:"uuid"`
+		Name      string               `json:"name"`
+		MinSalary decimal.NullDecimal  `json:"min_salary"`
+		MaxSalary decimal.NullDecimal  `json:"max_salary"`
+		MinAge    *uint                `json:"min_age"`
+		MaxAge    *uint                `json:"max_age"`
+		Priority  int                  `json:"priority"`
+		IsActive  bool                 `json:"is_active"`
+		PoolMode  entity.LimitPoolMode `json:"pool_mode"`
+		GlobalCap decimal.NullDecimal  `json:"global_cap"`
+		Items     []LimitPolicyItem    `json:"items"`
+	}
+
+	LimitPolicyItem struct {
+		Tenor  uint            `json:"tenor"`
+		Amount decimal.Decimal `json:"amount"`
+	}
+
+	// LimitPolicyApplyResult is the outcome of re-applying a policy to one user
+	LimitPolicyApplyResult struct {
+		UserUUID string `json:"user_uuid"`
+		Success  bool   `json:"success"`
+		Message  string `json:"message"`
+	}
+
+	LimitPolicyInput struct {
+		Name        string                 `json:"name" form:"name" xml:"name" validate:"required,max=255"`
+		Description string                 `json:"description" form:"description" xml:"description" validate:"max=255"`
+		MinSalary   string                 `json:"min_salary" form:"min_salary" xml:"min_salary" validate:"omitempty,numeric"`
+		MaxSalary   string                 `json:"max_salary" form:"max_salary" xml:"max_salary" validate:"omitempty,numeric"`
+		MinAge      *uint                  `json:"min_age" form:"min_age" xml:"min_age"`
+		MaxAge      *uint                  `json:"max_age" form:"max_age" xml:"max_age"`
+		Priority    int                    `json:"priority" form:"priority" xml:"priority"`
+		IsActive    *bool                  `json:"is_active" form:"is_active" xml:"is_active"`
+		PoolMode    string                 `json:"pool_mode" form:"pool_mode" xml:"pool_mode" validate:"omitempty,oneof=shared per_tenor global_cap"`
+		GlobalCap   string                 `json:"global_cap" form:"global_cap" xml:"global_cap" validate:"required_if=PoolMode global_cap,omitempty,numeric"`
+		Items       []LimitPolicyItemInput `json:"items" form:"items" xml:"items" validate:"required,min=1,dive"`
+	}
+
+	LimitPolicyItemInput struct {
+		Tenor  uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required,min=1"`
+		Amount string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
+	}
+
+	// LimitPolicyApplyInput list the users whose limits are replaced by the policy
+	LimitPolicyApplyInput struct {
+		UserUUIDs []string `json:"user_uuids" form:"user_uuids" xml:"user_uuids" validate:"required,min=1,max=100,dive,uuid"`
+		Reason    string   `json:"reason" form:"reason" xml:"reason" validate:"required,max=255"`
+	}
+)
+
+func LimitPolicyToDetailModel(policy *entity.LimitPolicy) *LimitPolicyDetail {
+	return &LimitPolicyDetail{
+		ID:          policy.ID,
+		UUID:        policy.UUID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		MinSalary:   policy.MinSalary,
+		MaxSalary:   policy.MaxSalary,
+		MinAge:      policy.MinAge,
+		MaxAge:      policy.MaxAge,
+		Priority:    policy.Priority,
+		IsActive:    policy.IsActive,
+		PoolMode:    policy.PoolMode,
+		GlobalCap:   policy.GlobalCap,
+		Items:       LimitPolicyItemToModels(policy.Items),
+		CreatedAt:   policy.CreatedAt,
+		UpdatedAt:   policy.UpdatedAt,
+	}
+}
+
+func LimitPolicyToListModel(policy *entity.LimitPolicy) *LimitPolicyList {
+	return &LimitPolicyList{
+		ID:        policy.ID,
+		UUID:      policy.UUID,
+		Name:      policy.Name,
+		MinSalary: policy.MinSalary,
+		MaxSalary: policy.MaxSalary,
+		MinAge:    policy.MinAge,
+		MaxAge:    policy.MaxAge,
+		Priority:  policy.Priority,
+		IsActive:  policy.IsActive,
+		PoolMode:  policy.PoolMode,
+		GlobalCap: policy.GlobalCap,
+		Items:     LimitPolicyItemToModels(policy.Items),
+	}
+}
+
+func LimitPolicyToListModels(policies []entity.LimitPolicy) (listModels []LimitPolicyList) {
+	for _, policy := range policies {
+		listModels = append(listModels, *LimitPolicyToListModel(&policy))
+	}
+
+	return listModels
+}
+
+func LimitPolicyItemToModels(items []entity.LimitPolicyItem) []LimitPolicyItem {
+	itemModels := []LimitPolicyItem{}
+	for _, item := range items {
+		itemModels = append(itemModels, LimitPolicyItem{
+			Tenor:  item.Tenor,
+			Amount: item.Amount,
+		})
+	}
+
+	return itemModels
+}
+
+func (input *LimitPolicyInput) ToEntity() (*entity.LimitPolicy, error) {
+	min_salary, err := nullDecimal(input.MinSalary)
+	if err != nil {
+		return nil, err
+	}
+	max_salary, err := nullDecimal(input.MaxSalary)
+	if err != nil {
+		return nil, err
+	}
+
+	global_cap, err := nullDecimal(input.GlobalCap)
+	if err != nil {
+		return nil, err
+	}
+
+	pool_mode := entity.LimitPoolShared
+	if input.PoolMode != "" {
+		pool_mode = entity.LimitPoolMode(input.PoolMode)
+	}
+	if pool_mode != entity.LimitPoolGlobalCap {
+		global_cap = decimal.NullDecimal{}
+	}
+
+	is_active := true
+	if input.IsActive != nil {
+		is_active = *input.IsActive
+	}
+
+	items := []entity.LimitPolicyItem{}
+	for _, item := range input.Items {
+		amount, err := decimal.NewFromString(item.Amount)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, entity.LimitPolicyItem{
+			Tenor:  item.Tenor,
+			Amount: amount.Round(2),
+		})
+	}
+
+	return &entity.LimitPolicy{
+		Name:        input.Name,
+		Description: input.Description,
+		MinSalary:   min_salary,
+		MaxSalary:   max_salary,
+		MinAge:      input.MinAge,
+		MaxAge:      input.MaxAge,
+		Priority:    input.Priority,
+		IsActive:    is_active,
+		PoolMode:    pool_mode,
+		GlobalCap:   global_cap,
+		Items:       items,
+	}, nil
+}
+
+func (input *LimitPolicyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Name = sanitizer.Sanitize(input.Name)
+	input.Description = sanitizer.Sanitize(input.Description)
+	input.MinSalary = sanitizer.Sanitize(input.MinSalary)
+	input.MaxSalary = sanitizer.Sanitize(input.MaxSalary)
+	input.PoolMode = sanitizer.Sanitize(input.PoolMode)
+	input.GlobalCap = sanitizer.Sanitize(input.GlobalCap)
+	for i := range input.Items {
+		input.Items[i].Amount = sanitizer.Sanitize(input.Items[i].Amount)
+	}
+}
+
+func (input *LimitPolicyApplyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	for i := range input.UserUUIDs {
+		input.UserUUIDs[i] = sanitizer.Sanitize(input.UserUUIDs[i])
+	}
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+// nullDecimal parse optional decimal input, empty string is null
+func nullDecimal(value string) (decimal.NullDecimal, error) {
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, err
+	}
+
+	return decimal.NewNullDecimal(parsed), nil
+}
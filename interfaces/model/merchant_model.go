@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	MerchantDetail struct {
+		ID                uint      `json:"id"`
+		UUID              uuid.UUID `json:"uuid"`
+		Code              string    `json:"code"`
+		Name              string    `json:"name"`
+		BankName          string    `json:"bank_name"`
+		BankAccountNumber string    `json:"bank_account_number"`
+		BankAccountName   string    `json:"bank_account_name"`
+		SettlementDays    uint      `json:"settlement_days"`
+		IsActive          bool      `json:"is_active"`
+		CreatedAt         time.Time `json:"created_at"`
+		UpdatedAt         time.Time `json:"updated_at"`
+	}
+
+	MerchantList struct {
+		ID             uint      `json:"id"`
+		UUID           uuid.UUID `json:"uuid"`
+		Code           string    `json:"code"`
+		Name           string    `json:"name"`
+		SettlementDays uint      `json:"settlement_days"`
+		IsActive       bool      `json:"is_active"`
+	}
+
+	MerchantInput struct {
+		Code              string `json:"code" form:"code" xml:"code" validate:"required,max=50"`
+		Name              string `json:"name" form:"name" xml:"name" validate:"required,max=255"`
+		BankName          string `json:"bank_name" form:"bank_name" xml:"bank_name" validate:"required,max=100"`
+		BankAccountNumber string `json:"bank_account_number" form:"bank_account_number" xml:"bank_account_number" validate:"required,numeric,max=50"`
+		BankAccountName   string `json:"bank_account_name" form:"bank_account_name" xml:"bank_account_name" validate:"required,max=255"`
+		SettlementDays    uint   `json:"settlement_days" form:"settlement_days" xml:"settlement_days" validate:"max=90"`
+		IsActive          *bool  `json:"is_active" form:"is_active" xml:"is_active"`
+	}
+)
+
+func MerchantToDetailModel(merchant *entity.Merchant) *MerchantDetail {
+	return &MerchantDetail{
+		ID:                merchant.ID,
+		UUID:              merchant.UUID,
+		Code:              merchant.Code,
+		Name:              merchant.Name,
+		BankName:          merchant.BankName,
+		BankAccountNumber: merchant.BankAccountNumber,
+		BankAccountName:   merchant.BankAccountName,
+		SettlementDays:    merchant.SettlementDays,
+		IsActive:          merchant.IsActive,
+		CreatedAt:         merchant.CreatedAt,
+		UpdatedAt:         merchant.UpdatedAt,
+	}
+}
+
+func MerchantToListModel(merchant *entity.Merchant) *MerchantList {
+	return &MerchantList{
+		ID:             merchant.ID,
+		UUID:           merchant.UUID,
+		Code:           merchant.Code,
+		Name:           merchant.Name,
+		SettlementDays: merchant.SettlementDays,
+		IsActive:       merchant.IsActive,
+	}
+}
+
+func MerchantToListModels(merchants []entity.Merchant) (listModels []MerchantList) {
+	for _, merchant := range merchants {
+		listModels = append(listModels, *MerchantToListModel(&merchant))
+	}
+
+	return listModels
+}
+
+func (input *MerchantInput) ToEntity() *entity.Merchant {
+	is_active := true
+	if input.IsActive != nil {
+		is_active = *input.IsActive
+	}
+
+	return &entity.Merchant{
+		Code:              input.Code,
+		Name:              input.Name,
+		BankName:          input.BankName,
+		BankAccountNumber: input.BankAccountNumber,
+		BankAccountName:   input.BankAccountName,
+		SettlementDays:    input.SettlementDays,
+		IsActive:          is_active,
+	}
+}
+
+func (input *MerchantInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Code = sanitizer.Sanitize(input.Code)
+	input.Name = sanitizer.Sanitize(input.Name)
+	input.BankName = sanitizer.Sanitize(input.BankName)
+	input.BankAccountNumber = sanitizer.Sanitize(input.BankAccountNumber)
+	input.BankAccountName = sanitizer.Sanitize(input.BankAccountName)
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	PartnerKeyList struct {
+		UUID      uuid.UUID  `json:"uuid"`
+		KeyID     string     `json:"key_id"`
+		Name      string     `json:"name"`
+		RevokedAt *time.Time `json:"revoked_at"`
+		CreatedAt time.Time  `json:"created_at"`
+	}
+
+	// PartnerKeyCreated is returned once when the key is issued, the secret is not kept
+	PartnerKeyCreated struct {
+		UUID   uuid.UUID `json:"uuid"`
+		KeyID  string    `json:"key_id"`
+		Secret string    `json:"secret"`
+		Name   string    `json:"name"`
+	}
+
+	PartnerKeyInput struct {
+		Name string `json:"name" form:"name" xml:"name" validate:"required,max=100"`
+	}
+
+	PartnerConsentList struct {
+		UUID         uuid.UUID  `json:"uuid"`
+		MerchantCode string     `json:"merchant_code"`
+		MerchantName string     `json:"merchant_name"`
+		RevokedAt    *time.Time `json:"revoked_at"`
+		CreatedAt    time.Time  `json:"created_at"`
+	}
+
+	PartnerConsentInput struct {
+		MerchantCode string `json:"merchant_code" form:"merchant_code" xml:"merchant_code" validate:"required,max=50"`
+	}
+
+	// PartnerLimit is the limit of a tenor as seen by partner, frozen limit is not available
+	PartnerLimit struct {
+		Tenor          uint            `json:"tenor"`
+		AvailableLimit decimal.Decimal `json:"available_limit"`
+		IsFrozen       bool            `json:"is_frozen"`
+	}
+
+	// PartnerTransactionInput create a transaction for the customer, the merchant is the partner
+	PartnerTransactionInput struct {
+		CustomerUUID string `json:"customer_uuid" form:"customer_uuid" xml:"customer_uuid" validate:"required,uuid"`
+		AssetName    string `json:"asset_name" form:"asset_name" xml:"asset_name" validate:"required"`
+		OnTheRoad    string `json:"on_the_road" form:"on_the_road" xml:"on_the_road" validate:"required,numeric"`
+		ProductCode  string `json:"product_code" form:"product_code" xml:"product_code" validate:"required,max=50"`
+		Tenor        uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required"`
+		BillingDay   uint   `json:"billing_day" form:"billing_day" xml:"billing_day" validate:"omitempty,min=1,max=31"`
+	}
+
+	PartnerTransaction struct {
+		UUID               uuid.UUID                `json:"uuid"`
+		ContractNumber     string                   `json:"contract_number"`
+		OnTheRoad          decimal.Decimal          `json:"on_the_road"`
+		AdminFee           decimal.Decimal          `json:"admin_fee"`
+		MonthlyInstallment decimal.Decimal          `json:"monthly_installment"`
+		Tenor              uint                     `json:"tenor"`
+		Status             entity.TransactionStatus `json:"status"`
+	}
+)
+
+func PartnerKeyToListModels(keys []entity.PartnerKey) (listModels []PartnerKeyList) {
+	for _, key := range keys {
+		listModels = append(listModels, PartnerKeyList{
+			UUID:      key.UUID,
+			KeyID:     key.KeyID,
+			Name:      key.Name,
+			RevokedAt: key.RevokedAt,
+			CreatedAt: key.CreatedAt,
+		})
+	}
+
+	return listModels
+}
+
+func PartnerConsentToListModels(consents []entity.PartnerConsent) (listModels []PartnerConsentList) {
+	for _, consent := range consents {
+		listModel := PartnerConsentList{
+			UUID:      consent.UUID,
+			RevokedAt: consent.RevokedAt,
+			CreatedAt: consent.CreatedAt,
+		}
+		if consent.Merchant != nil {
+			listModel.MerchantCode = consent.Merchant.Code
+			listModel.MerchantName = consent.Merchant.Name
+		}
+		listModels = append(listModels, listModel)
+	}
+
+	return listModels
+}
+
+func LimitToPartnerModels(limits []entity.Limit) (listModels []PartnerLimit) {
+	for _, limit := range limits {
+		available := limit.CurrentLimit
+		if limit.FrozenAt.Valid {
+			available = decimal.Zero
+		}
+		listModels = append(listModels, PartnerLimit{
+			Tenor:          limit.Tenor,
+			AvailableLimit: available,
+			IsFrozen:       limit.FrozenAt.Valid,
+		})
+	}
+
+	return listModels
+}
+
+func TransactionToPartnerModel(transaction *entity.Transaction) *PartnerTransaction {
+	return &PartnerTransaction{
+		UUID:               transaction.UUID,
+		ContractNumber:     transaction.ContractNumber,
+		OnTheRoad:          transaction.OnTheRoad,
+		AdminFee:           transaction.AdminFee,
+		MonthlyInstallment: transaction.MonthlyInstallment,
+		Tenor:              transaction.Tenor,
+		Status:             transaction.Status,
+	}
+}
+
+// ToTransactionInput reuse the customer transaction input, merchant is set by the service
+func (input *PartnerTransactionInput) ToTransactionInput() *TransactionInput {
+	return &TransactionInput{
+		AssetName:   input.AssetName,
+		OnTheRoad:   input.OnTheRoad,
+		ProductCode: input.ProductCode,
+		Tenor:       input.Tenor,
+		BillingDay:  input.BillingDay,
+	}
+}
+
+func (input *PartnerKeyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Name = sanitizer.Sanitize(input.Name)
+}
+
+func (input *PartnerConsentInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.MerchantCode = sanitizer.Sanitize(input.MerchantCode)
+}
+
+func (input *PartnerTransactionInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.CustomerUUID = sanitizer.Sanitize(input.CustomerUUID)
+	input.AssetName = sanitizer.Sanitize(input.AssetName)
+	input.OnTheRoad = sanitizer.Sanitize(input.OnTheRoad)
+	input.ProductCode = sanitizer.Sanitize(input.ProductCode)
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	PaymentCallbackDetail struct {
+		UUID                  uuid.UUID                    `json:"uuid"`
+		Provider              string                       `json:"provider"`
+		ProviderTransactionID string                       `json:"provider_transaction_id"`
+		Event                 entity.PaymentCallbackEvent  `json:"event"`
+		Status                entity.PaymentCallbackStatus `json:"status"`
+		TransactionID         *uint                        `json:"transaction_id"`
+		PaymentID             *uint                        `json:"payment_id"`
+		Amount                decimal.Decimal              `json:"amount"`
+		Message               string                       `json:"message"`
+		CreatedAt             time.Time                    `json:"created_at"`
+	}
+
+	// PaymentCallbackInput is sent by payment gateway, either transaction or installment
+	// uuid is the reference of the virtual account or qr code
+	PaymentCallbackInput struct {
+		Provider              string `json:"provider" form:"provider" xml:"provider" validate:"required,max=50"`
+		ProviderTransactionID string `json:"provider_transaction_id" form:"provider_transaction_id" xml:"provider_transaction_id" validate:"required,max=100"`
+		Event                 string `json:"event" form:"event" xml:"event" validate:"required,oneof=va.paid qr.paid expired"`
+		TransactionUUID       string `json:"transaction_uuid" form:"transaction_uuid" xml:"transaction_uuid" validate:"required_without=InstallmentUUID,omitempty,uuid"`
+		InstallmentUUID       string `json:"installment_uuid" form:"installment_uuid" xml:"installment_uuid" validate:"required_without=TransactionUUID,omitempty,uuid"`
+		Amount                string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
+	}
+)
+
+func PaymentCallbackToDetailModel(callback *entity.PaymentCallback) *PaymentCallbackDetail {
+	return &PaymentCallbackDetail{
+		UUID:                  callback.UUID,
+		Provider:              callback.Provider,
+		ProviderTransactionID: callback.ProviderTransactionID,
+		Event:                 callback.Event,
+		Status:                callback.Status,
+		TransactionID:         callback.TransactionID,
+		PaymentID:             callback.PaymentID,
+		Amount:                callback.Amount,
+		Message:               callback.Message,
+		CreatedAt:             callback.CreatedAt,
+	}
+}
+
+func (input *PaymentCallbackInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Provider = sanitizer.Sanitize(input.Provider)
+	input.ProviderTransactionID = sanitizer.Sanitize(input.ProviderTransactionID)
+	input.Event = sanitizer.Sanitize(input.Event)
+	input.TransactionUUID = sanitizer.Sanitize(input.TransactionUUID)
+	input.InstallmentUUID = sanitizer.Sanitize(input.InstallmentUUID)
+	input.Amount = sanitizer.Sanitize(input.Amount)
+}
@@ -11,38 +11,94 @@ import (
 
 type (
 	PaymentDetail struct {
-		ID                uint            `json:"id"`
-		UUID              uuid.UUID       `json:"uuid"`
-		TransactionID     uint            `json:"transaction_id"`
+		ID                uint                    `json:"id"`
+		UUID              uuid.UUID               `json:"uuid"`
+		TransactionID     uint                    `json:"transaction_id"`
+		InstallmentID     uint                    `json:"installment_id"`
+		Amount            decimal.Decimal         `json:"amount"`
+		PaymentMethod     string                  `json:"payment_method"`
+		Type              entity.PaymentType      `json:"type"`
+		ReversalOfID      *uint                   `json:"reversal_of_id"`
+		Reason            string                  `json:"reason"`
+		ReversedAt        *time.Time              `json:"reversed_at"`
+		AssetName         string                  `json:"asset_name"`
+		ContractNumber    string                  `json:"contract_number"`
+		InstallmentNumber uint                    `json:"installment_number"`
+		CreditAmount      decimal.Decimal         `json:"credit_amount"`
+		CreditUUID        *uuid.UUID              `json:"credit_uuid"`
+		Allocations       []PaymentAllocationList `json:"allocations"`
+		CreatedAt         time.Time               `json:"created_at"`
+		UpdatedAt         time.Time               `json:"updated_at"`
+	}
+
+	PaymentAllocationList struct {
 		InstallmentID     uint            `json:"installment_id"`
-		Amount            decimal.Decimal `json:"amount"`
-		PaymentMethod     string          `json:"payment_method"`
-		AssetName         string          `json:"asset_name"`
-		ContractNumber    string          `json:"contract_number"`
 		InstallmentNumber uint            `json:"installment_number"`
-		CreatedAt         time.Time       `json:"created_at"`
-		UpdatedAt         time.Time       `json:"updated_at"`
+		PenaltyAmount     decimal.Decimal `json:"penalty_amount"`
+		InterestAmount    decimal.Decimal `json:"interest_amount"`
+		PrincipalAmount   decimal.Decimal `json:"principal_amount"`
+		Amount            decimal.Decimal `json:"amount"`
 	}
 
 	PaymentList struct {
-		ID               uint            `json:"id"`
-		UUID             uuid.UUID       `json:"uuid"`
-		TransactionID    uint            `json:"transaction_id"`
-		InstallmentID    uint            `json:"installment_id"`
-		Amount           decimal.Decimal `json:"amount"`
-		InstalmentNumber uint            `json:"instalment_number"`
-		PaymentMethod    string          `json:"payment_method"`
-		CreatedAt        time.Time       `json:"created_at"`
+		ID               uint               `json:"id"`
+		UUID             uuid.UUID          `json:"uuid"`
+		TransactionID    uint               `json:"transaction_id"`
+		InstallmentID    uint               `json:"installment_id"`
+		Amount           decimal.Decimal    `json:"amount"`
+		InstalmentNumber uint               `json:"instalment_number"`
+		PaymentMethod    string             `json:"payment_method"`
+		Type             entity.PaymentType `json:"type"`
+		ReversedAt       *time.Time         `json:"reversed_at"`
+		CreatedAt        time.Time          `json:"created_at"`
 	}
 
-	PaymentInput struct {
-		InstallmentID uint   `json:"installment_id" form:"installment_id" xml:"installment_id" validate:"required,numeric"`
-		Amount        string `json:"amount" form:"amount" xml:"amount" validate:"required"`
+	PayoffQuote struct {
+		TransactionUUID      uuid.UUID       `json:"transaction_uuid"`
+		ContractNumber       string          `json:"contract_number"`
+		RemainingInstallment int             `json:"remaining_installment"`
+		RemainingPrincipal   decimal.Decimal `json:"remaining_principal"`
+		AccruedInterest      decimal.Decimal `json:"accrued_interest"`
+		InterestRebate       decimal.Decimal `json:"interest_rebate"`
+		OutstandingPenalty   decimal.Decimal `json:"outstanding_penalty"`
+		TerminationFee       decimal.Decimal `json:"termination_fee"`
+		TotalPayoff          decimal.Decimal `json:"total_payoff"`
+		QuotedAt             time.Time       `json:"quoted_at"`
+	}
+
+	PayoffInput struct {
+		Amount        string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
 		PaymentMethod string `json:"payment_method" form:"payment_method" xml:"payment_method" validate:"required"`
 	}
+
+	PaymentInput struct {
+		TransactionUUID string `json:"transaction_uuid" form:"transaction_uuid" xml:"transaction_uuid" validate:"required,uuid"`
+		Amount          string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
+		PaymentMethod   string `json:"payment_method" form:"payment_method" xml:"payment_method" validate:"required"`
+	}
+
+	CreditApplyInput struct {
+		TransactionUUID string `json:"transaction_uuid" form:"transaction_uuid" xml:"transaction_uuid" validate:"required,uuid"`
+	}
+
+	PaymentReversalInput struct {
+		Reason string `json:"reason" form:"reason" xml:"reason" validate:"required,max=255"`
+	}
 )
 
 func PaymentToDetailModel(payment *entity.Payment) *PaymentDetail {
+	creditAmount := decimal.Zero
+	var creditUUID *uuid.UUID
+	if payment.Credit != nil {
+		creditAmount = payment.Credit.Amount
+		creditUUID = &payment.Credit.UUID
+	}
+
+	var reversedAt *time.Time
+	if payment.ReversedAt.Valid {
+		reversedAt = &payment.ReversedAt.Time
+	}
+
 	return &PaymentDetail{
 		ID:                payment.ID,
 		UUID:              payment.UUID,
@@ -52,13 +108,44 @@ func PaymentToDetailModel(payment *entity.Payment) *PaymentDetail {
 		InstallmentID:     payment.InstallmentID,
 		InstallmentNumber: payment.Installment.InstallmentNumber,
 		PaymentMethod:     payment.PaymentMethod,
+		Type:              payment.Type,
+		ReversalOfID:      payment.ReversalOfID,
+		Reason:            payment.Reason,
+		ReversedAt:        reversedAt,
 		Amount:            payment.Amount,
+		CreditAmount:      creditAmount,
+		CreditUUID:        creditUUID,
+		Allocations:       PaymentAllocationToListModels(payment.Allocations),
 		CreatedAt:         payment.CreatedAt,
 		UpdatedAt:         payment.UpdatedAt,
 	}
 }
 
+func PaymentAllocationToListModel(allocation *entity.PaymentAllocation) *PaymentAllocationList {
+	return &PaymentAllocationList{
+		InstallmentID:     allocation.InstallmentID,
+		InstallmentNumber: allocation.Installment.InstallmentNumber,
+		PenaltyAmount:     allocation.PenaltyAmount,
+		InterestAmount:    allocation.InterestAmount,
+		PrincipalAmount:   allocation.PrincipalAmount,
+		Amount:            allocation.Amount,
+	}
+}
+
+func PaymentAllocationToListModels(allocations []entity.PaymentAllocation) (listModels []PaymentAllocationList) {
+	for _, allocation := range allocations {
+		listModels = append(listModels, *PaymentAllocationToListModel(&allocation))
+	}
+
+	return listModels
+}
+
 func PaymentToListModel(payment *entity.Payment) *PaymentList {
+	var reversedAt *time.Time
+	if payment.ReversedAt.Valid {
+		reversedAt = &payment.ReversedAt.Time
+	}
+
 	return &PaymentList{
 		ID:               payment.ID,
 		UUID:             payment.UUID,
@@ -66,6 +153,8 @@ func PaymentToListModel(payment *entity.Payment) *PaymentList {
 		InstallmentID:    payment.InstallmentID,
 		InstalmentNumber: payment.Installment.InstallmentNumber,
 		PaymentMethod:    payment.PaymentMethod,
+		Type:             payment.Type,
+		ReversedAt:       reversedAt,
 		Amount:           payment.Amount,
 		CreatedAt:        payment.CreatedAt,
 	}
@@ -86,7 +175,6 @@ func (input *PaymentInput) ToEntity() (*entity.Payment, error) {
 	}
 
 	return &entity.Payment{
-		InstallmentID: input.InstallmentID,
 		Amount:        amount,
 		PaymentMethod: input.PaymentMethod,
 	}, nil
@@ -95,6 +183,26 @@ func (input *PaymentInput) ToEntity() (*entity.Payment, error) {
 func (input *PaymentInput) Sanitize() {
 	sanitizer := bluemonday.StrictPolicy()
 
+	input.TransactionUUID = sanitizer.Sanitize(input.TransactionUUID)
 	input.Amount = sanitizer.Sanitize(input.Amount)
 	input.PaymentMethod = sanitizer.Sanitize(input.PaymentMethod)
 }
+
+func (input *PayoffInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Amount = sanitizer.Sanitize(input.Amount)
+	input.PaymentMethod = sanitizer.Sanitize(input.PaymentMethod)
+}
+
+func (input *PaymentReversalInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *CreditApplyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.TransactionUUID = sanitizer.Sanitize(input.TransactionUUID)
+}
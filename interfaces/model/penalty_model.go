@@ -0,0 +1,180 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	PenaltyPolicyDetail struct {
+		ID        uint               `json:"id"`
+		UUID      uuid.UUID          `json:"uuid"`
+		Name      string             `json:"name"`
+		Type      entity.PenaltyType `json:"type"`
+		Rate      decimal.Decimal    `json:"rate"`
+		Amount    decimal.Decimal    `json:"amount"`
+		Cap       decimal.Decimal    `json:"cap"`
+		GraceDays uint               `json:"grace_days"`
+		IsActive  bool               `json:"is_active"`
+		CreatedAt time.Time          `json:"created_at"`
+		UpdatedAt time.Time          `json:"updated_at"`
+	}
+
+	PenaltyPolicyList struct {
+		ID        uint               `json:"id"`
+		UUID      uuid.UUID          `json:"uuid"`
+		Name      string             `json:"name"`
+		Type      entity.PenaltyType `json:"type"`
+		Rate      decimal.Decimal    `json:"rate"`
+		Amount    decimal.Decimal    `json:"amount"`
+		Cap       decimal.Decimal    `json:"cap"`
+		GraceDays uint               `json:"grace_days"`
+		IsActive  bool               `json:"is_active"`
+	}
+
+	InstallmentPenaltyList struct {
+		ID              uint            `json:"id"`
+		UUID            uuid.UUID       `json:"uuid"`
+		InstallmentID   uint            `json:"installment_id"`
+		PenaltyPolicyID uint            `json:"penalty_policy_id"`
+		AccrualDate     time.Time       `json:"accrual_date"`
+		Days            uint            `json:"days"`
+		Amount          decimal.Decimal `json:"amount"`
+		AmountPaid      decimal.Decimal `json:"amount_paid"`
+		PaidAt          sql.NullTime    `json:"paid_at"`
+	}
+
+	PenaltyPolicyInput struct {
+		Name      string `json:"name" form:"name" xml:"name" validate:"required"`
+		Type      string `json:"type" form:"type" xml:"type" validate:"required,oneof=daily flat"`
+		Rate      string `json:"rate" form:"rate" xml:"rate" validate:"required_if=Type daily,omitempty,numeric"`
+		Amount    string `json:"amount" form:"amount" xml:"amount" validate:"required_if=Type flat,omitempty,numeric"`
+		Cap       string `json:"cap" form:"cap" xml:"cap" validate:"omitempty,numeric"`
+		GraceDays uint   `json:"grace_days" form:"grace_days" xml:"grace_days"`
+		IsActive  *bool  `json:"is_active" form:"is_active" xml:"is_active"`
+	}
+)
+
+func PenaltyPolicyToDetailModel(policy *entity.PenaltyPolicy) *PenaltyPolicyDetail {
+	return &PenaltyPolicyDetail{
+		ID:        policy.ID,
+		UUID:      policy.UUID,
+		Name:      policy.Name,
+		Type:      policy.Type,
+		Rate:      policy.Rate,
+		Amount:    policy.Amount,
+		Cap:       policy.Cap,
+		GraceDays: policy.GraceDays,
+		IsActive:  policy.IsActive,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
+
+func PenaltyPolicyToListModel(policy *entity.PenaltyPolicy) *PenaltyPolicyList {
+	return &PenaltyPolicyList{
+		ID:        policy.ID,
+		UUID:      policy.UUID,
+		Name:      policy.Name,
+		Type:      policy.Type,
+		Rate:      policy.Rate,
+		Amount:    policy.Amount,
+		Cap:       policy.Cap,
+		GraceDays: policy.GraceDays,
+		IsActive:  policy.IsActive,
+	}
+}
+
+func PenaltyPolicyToListModels(policies []entity.PenaltyPolicy) (listModels []PenaltyPolicyList) {
+	for _, policy := range policies {
+		listModels = append(listModels, *PenaltyPolicyToListModel(&policy))
+	}
+
+	return listModels
+}
+
+func InstallmentPenaltyToListModel(penalty *entity.InstallmentPenalty) *InstallmentPenaltyList {
+	return &InstallmentPenaltyList{
+		ID:              penalty.ID,
+		UUID:            penalty.UUID,
+		InstallmentID:   penalty.InstallmentID,
+		PenaltyPolicyID: penalty.PenaltyPolicyID,
+		AccrualDate:     penalty.AccrualDate,
+		Days:            penalty.Days,
+		Amount:          penalty.Amount,
+		AmountPaid:      penalty.AmountPaid,
+		PaidAt:          penalty.PaidAt,
+	}
+}
+
+func InstallmentPenaltyToListModels(penalties []entity.InstallmentPenalty) (listModels []InstallmentPenaltyList) {
+	for _, penalty := range penalties {
+		listModels = append(listModels, *InstallmentPenaltyToListModel(&penalty))
+	}
+
+	return listModels
+}
+
+// UnpaidPenalty sum penalty amount not yet settled
+func UnpaidPenalty(penalties []entity.InstallmentPenalty) decimal.Decimal {
+	total := decimal.Zero
+	for _, penalty := range penalties {
+		total = total.Add(penalty.Unpaid())
+	}
+
+	return total
+}
+
+func (input *PenaltyPolicyInput) ToEntity() (*entity.PenaltyPolicy, error) {
+	rate_decimal, err := decimalOrZero(input.Rate)
+	if err != nil {
+		return nil, err
+	}
+	amount_decimal, err := decimalOrZero(input.Amount)
+	if err != nil {
+		return nil, err
+	}
+	cap_decimal, err := decimalOrZero(input.Cap)
+	if err != nil {
+		return nil, err
+	}
+
+	is_active := true
+	if input.IsActive != nil {
+		is_active = *input.IsActive
+	}
+
+	return &entity.PenaltyPolicy{
+		Name:      input.Name,
+		Type:      entity.PenaltyType(input.Type),
+		Rate:      rate_decimal,
+		Amount:    amount_decimal,
+		Cap:       cap_decimal,
+		GraceDays: input.GraceDays,
+		IsActive:  is_active,
+	}, nil
+}
+
+func (input *PenaltyPolicyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Name = sanitizer.Sanitize(input.Name)
+	input.Type = sanitizer.Sanitize(input.Type)
+	input.Rate = sanitizer.Sanitize(input.Rate)
+	input.Amount = sanitizer.Sanitize(input.Amount)
+	input.Cap = sanitizer.Sanitize(input.Cap)
+}
+
+// decimalOrZero parse optional decimal input, empty string is zero
+func decimalOrZero(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	return decimal.NewFromString(value)
+}
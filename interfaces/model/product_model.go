@@ -0,0 +1,238 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	ProductDetail struct {
+		ID             uint                  `json:"id"`
+		UUID           uuid.UUID             `json:"uuid"`
+		Code           string                `json:"code"`
+		Name           string                `json:"name"`
+		Description    string                `json:"description"`
+		InterestMethod entity.InterestMethod `json:"interest_method"`
+		InterestRate   decimal.Decimal       `json:"interest_rate"`
+		AdminFeeType   entity.AdminFeeType   `json:"admin_fee_type"`
+		AdminFeeAmount decimal.Decimal       `json:"admin_fee_amount"`
+		AdminFeeRate   decimal.Decimal       `json:"admin_fee_rate"`
+		FeeTiers       []ProductFeeTier      `json:"fee_tiers"`
+		MinOnTheRoad   decimal.Decimal       `json:"min_on_the_road"`
+		MaxOnTheRoad   decimal.NullDecimal   `json:"max_on_the_road"`
+		Tenors         []uint                `json:"tenors"`
+		IsActive       bool                  `json:"is_active"`
+		CreatedAt      time.Time             `json:"created_at"`
+		UpdatedAt      time.Time             `json:"updated_at"`
+	}
+
+	ProductList struct {
+		ID             uint                  `json:"id"`
+		UUID           uuid.UUID             `json:"uuid"`
+		Code           string                `json:"code"`
+		Name           string                `json:"name"`
+		InterestMethod entity.InterestMethod `json:"interest_method"`
+		InterestRate   decimal.Decimal       `json:"interest_rate"`
+		AdminFeeType   entity.AdminFeeType   `json:"admin_fee_type"`
+		MinOnTheRoad   decimal.Decimal       `json:"min_on_the_road"`
+		MaxOnTheRoad   decimal.NullDecimal   `json:"max_on_the_road"`
+		Tenors         []uint                `json:"tenors"`
+		IsActive       bool                  `json:"is_active"`
+	}
+
+	ProductFeeTier struct {
+		MinOnTheRoad decimal.Decimal `json:"min_on_the_road"`
+		Amount       decimal.Decimal `json:"amount"`
+	}
+
+	// ProductInput hold the product rules, rate are in percent (1.5 means 1.5%)
+	ProductInput struct {
+		Code           string                `json:"code" form:"code" xml:"code" validate:"required,max=50"`
+		Name           string                `json:"name" form:"name" xml:"name" validate:"required,max=255"`
+		Description    string                `json:"description" form:"description" xml:"description" validate:"max=255"`
+		InterestMethod string                `json:"interest_method" form:"interest_method" xml:"interest_method" validate:"omitempty,oneof=flat annuity effective"`
+		InterestRate   string                `json:"interest_rate" form:"interest_rate" xml:"interest_rate" validate:"required,numeric"`
+		AdminFeeType   string                `json:"admin_fee_type" form:"admin_fee_type" xml:"admin_fee_type" validate:"required,oneof=flat percentage tiered"`
+		AdminFeeAmount string                `json:"admin_fee_amount" form:"admin_fee_amount" xml:"admin_fee_amount" validate:"required_if=AdminFeeType flat,omitempty,numeric"`
+		AdminFeeRate   string                `json:"admin_fee_rate" form:"admin_fee_rate" xml:"admin_fee_rate" validate:"required_if=AdminFeeType percentage,omitempty,numeric"`
+		FeeTiers       []ProductFeeTierInput `json:"fee_tiers" form:"fee_tiers" xml:"fee_tiers" validate:"required_if=AdminFeeType tiered,dive"`
+		MinOnTheRoad   string                `json:"min_on_the_road" form:"min_on_the_road" xml:"min_on_the_road" validate:"omitempty,numeric"`
+		MaxOnTheRoad   string                `json:"max_on_the_road" form:"max_on_the_road" xml:"max_on_the_road" validate:"omitempty,numeric"`
+		Tenors         []uint                `json:"tenors" form:"tenors" xml:"tenors" validate:"required,min=1,dive,min=1"`
+		IsActive       *bool                 `json:"is_active" form:"is_active" xml:"is_active"`
+	}
+
+	ProductFeeTierInput struct {
+		MinOnTheRoad string `json:"min_on_the_road" form:"min_on_the_road" xml:"min_on_the_road" validate:"required,numeric"`
+		Amount       string `json:"amount" form:"amount" xml:"amount" validate:"required,numeric"`
+	}
+)
+
+func ProductToDetailModel(product *entity.Product) *ProductDetail {
+	return &ProductDetail{
+		ID:             product.ID,
+		UUID:           product.UUID,
+		Code:           product.Code,
+		Name:           product.Name,
+		Description:    product.Description,
+		InterestMethod: product.InterestMethod,
+		InterestRate:   product.InterestRate,
+		AdminFeeType:   product.AdminFeeType,
+		AdminFeeAmount: product.AdminFeeAmount,
+		AdminFeeRate:   product.AdminFeeRate,
+		FeeTiers:       ProductFeeTierToModels(product.FeeTiers),
+		MinOnTheRoad:   product.MinOnTheRoad,
+		MaxOnTheRoad:   product.MaxOnTheRoad,
+		Tenors:         ProductTenorToModels(product.Tenors),
+		IsActive:       product.IsActive,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
+	}
+}
+
+func ProductToListModel(product *entity.Product) *ProductList {
+	return &ProductList{
+		ID:             product.ID,
+		UUID:           product.UUID,
+		Code:           product.Code,
+		Name:           product.Name,
+		InterestMethod: product.InterestMethod,
+		InterestRate:   product.InterestRate,
+		AdminFeeType:   product.AdminFeeType,
+		MinOnTheRoad:   product.MinOnTheRoad,
+		MaxOnTheRoad:   product.MaxOnTheRoad,
+		Tenors:         ProductTenorToModels(product.Tenors),
+		IsActive:       product.IsActive,
+	}
+}
+
+func ProductToListModels(products []entity.Product) (listModels []ProductList) {
+	for _, product := range products {
+		listModels = append(listModels, *ProductToListModel(&product))
+	}
+
+	return listModels
+}
+
+func ProductTenorToModels(tenors []entity.ProductTenor) []uint {
+	tenorModels := []uint{}
+	for _, tenor := range tenors {
+		tenorModels = append(tenorModels, tenor.Tenor)
+	}
+
+	return tenorModels
+}
+
+func ProductFeeTierToModels(tiers []entity.ProductFeeTier) []ProductFeeTier {
+	tierModels := []ProductFeeTier{}
+	for _, tier := range tiers {
+		tierModels = append(tierModels, ProductFeeTier{
+			MinOnTheRoad: tier.MinOnTheRoad,
+			Amount:       tier.Amount,
+		})
+	}
+
+	return tierModels
+}
+
+func (input *ProductInput) ToEntity() (*entity.Product, error) {
+	interest_rate, err := decimal.NewFromString(input.InterestRate)
+	if err != nil {
+		return nil, err
+	}
+	admin_fee_amount, err := optionalDecimal(input.AdminFeeAmount)
+	if err != nil {
+		return nil, err
+	}
+	admin_fee_rate, err := optionalDecimal(input.AdminFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	min_otr, err := optionalDecimal(input.MinOnTheRoad)
+	if err != nil {
+		return nil, err
+	}
+	max_otr, err := nullDecimal(input.MaxOnTheRoad)
+	if err != nil {
+		return nil, err
+	}
+
+	interest_method := entity.InterestMethod(input.InterestMethod)
+	if interest_method == "" {
+		interest_method = entity.InterestFlat
+	}
+
+	is_active := true
+	if input.IsActive != nil {
+		is_active = *input.IsActive
+	}
+
+	tenors := []entity.ProductTenor{}
+	for _, tenor := range input.Tenors {
+		tenors = append(tenors, entity.ProductTenor{Tenor: tenor})
+	}
+
+	tiers := []entity.ProductFeeTier{}
+	for _, tier := range input.FeeTiers {
+		min_tier, err := decimal.NewFromString(tier.MinOnTheRoad)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := decimal.NewFromString(tier.Amount)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, entity.ProductFeeTier{
+			MinOnTheRoad: min_tier.Round(2),
+			Amount:       amount.Round(2),
+		})
+	}
+
+	return &entity.Product{
+		Code:           input.Code,
+		Name:           input.Name,
+		Description:    input.Description,
+		InterestMethod: interest_method,
+		InterestRate:   interest_rate,
+		AdminFeeType:   entity.AdminFeeType(input.AdminFeeType),
+		AdminFeeAmount: admin_fee_amount.Round(2),
+		AdminFeeRate:   admin_fee_rate,
+		MinOnTheRoad:   min_otr.Round(2),
+		MaxOnTheRoad:   max_otr,
+		IsActive:       is_active,
+		Tenors:         tenors,
+		FeeTiers:       tiers,
+	}, nil
+}
+
+func (input *ProductInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Code = sanitizer.Sanitize(input.Code)
+	input.Name = sanitizer.Sanitize(input.Name)
+	input.Description = sanitizer.Sanitize(input.Description)
+	input.InterestMethod = sanitizer.Sanitize(input.InterestMethod)
+	input.InterestRate = sanitizer.Sanitize(input.InterestRate)
+	input.AdminFeeType = sanitizer.Sanitize(input.AdminFeeType)
+	input.AdminFeeAmount = sanitizer.Sanitize(input.AdminFeeAmount)
+	input.AdminFeeRate = sanitizer.Sanitize(input.AdminFeeRate)
+	input.MinOnTheRoad = sanitizer.Sanitize(input.MinOnTheRoad)
+	input.MaxOnTheRoad = sanitizer.Sanitize(input.MaxOnTheRoad)
+	for i := range input.FeeTiers {
+		input.FeeTiers[i].MinOnTheRoad = sanitizer.Sanitize(input.FeeTiers[i].MinOnTheRoad)
+		input.FeeTiers[i].Amount = sanitizer.Sanitize(input.FeeTiers[i].Amount)
+	}
+}
+
+// optionalDecimal parse optional decimal input, empty string is zero
+func optionalDecimal(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	return decimal.NewFromString(value)
+}
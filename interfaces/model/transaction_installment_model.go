@@ -11,21 +11,27 @@ import (
 
 type (
 	TransactionInstallmentDetail struct {
-		ID                uint                 `json:"id"`
-		UUID              uuid.UUID            `json:"uuid"`
-		TransactionID     uint                 `json:"transaction_id"`
-		InstallmentNumber uint                 `json:"installment_number"`
-		AssetName         string               `json:"asset_name"`
-		ContractNumber    string               `json:"contract_number"`
-		Tenor             uint                 `json:"tenor"`
-		DueDate           time.Time            `json:"due_date"`
-		AmountDue         decimal.Decimal      `json:"amount_due"`
-		AmountPaid        decimal.Decimal      `json:"amount_paid"`
-		PaymentStatus     entity.PaymentStatus `json:"payment_status"`
-		PaidAt            sql.NullTime         `json:"paid_at"`
-		Payments          []PaymentList        `json:"payments"`
-		CreatedAt         time.Time            `json:"created_at"`
-		UpdatedAt         time.Time            `json:"updated_at"`
+		ID                uint                     `json:"id"`
+		UUID              uuid.UUID                `json:"uuid"`
+		TransactionID     uint                     `json:"transaction_id"`
+		InstallmentNumber uint                     `json:"installment_number"`
+		AssetName         string                   `json:"asset_name"`
+		ContractNumber    string                   `json:"contract_number"`
+		Tenor             uint                     `json:"tenor"`
+		DueDate           time.Time                `json:"due_date"`
+		PrincipalDue      decimal.Decimal          `json:"principal_due"`
+		InterestDue       decimal.Decimal          `json:"interest_due"`
+		AmountDue         decimal.Decimal          `json:"amount_due"`
+		Outstanding       decimal.Decimal          `json:"outstanding"`
+		AmountPaid        decimal.Decimal          `json:"amount_paid"`
+		PaymentStatus     entity.PaymentStatus     `json:"payment_status"`
+		PaidAt            sql.NullTime             `json:"paid_at"`
+		DaysPastDue       uint                     `json:"days_past_due"`
+		PenaltyDue        decimal.Decimal          `json:"penalty_due"`
+		Payments          []PaymentList            `json:"payments"`
+		Penalties         []InstallmentPenaltyList `json:"penalties"`
+		CreatedAt         time.Time                `json:"created_at"`
+		UpdatedAt         time.Time                `json:"updated_at"`
 	}
 
 	TransactionInstallmentList struct {
@@ -36,10 +42,15 @@ type (
 		AssetName         string               `json:"asset_name"`
 		ContractNumber    string               `json:"contract_number"`
 		DueDate           time.Time            `json:"due_date"`
+		PrincipalDue      decimal.Decimal      `json:"principal_due"`
+		InterestDue       decimal.Decimal      `json:"interest_due"`
 		AmountDue         decimal.Decimal      `json:"amount_due"`
+		Outstanding       decimal.Decimal      `json:"outstanding"`
 		AmountPaid        decimal.Decimal      `json:"amount_paid"`
 		PaymentStatus     entity.PaymentStatus `json:"payment_status"`
 		PaidAt            sql.NullTime         `json:"paid_at"`
+		DaysPastDue       uint                 `json:"days_past_due"`
+		PenaltyDue        decimal.Decimal      `json:"penalty_due"`
 	}
 )
 
@@ -53,11 +64,17 @@ func TransactionInstallmentToDetailModel(transactionInstallment *entity.Transact
 		ContractNumber:    transactionInstallment.Transaction.ContractNumber,
 		Tenor:             transactionInstallment.Transaction.Tenor,
 		DueDate:           transactionInstallment.DueDate,
+		PrincipalDue:      transactionInstallment.PrincipalDue,
+		InterestDue:       transactionInstallment.InterestDue,
 		AmountDue:         transactionInstallment.AmountDue,
+		Outstanding:       transactionInstallment.Outstanding,
 		AmountPaid:        transactionInstallment.AmountPaid,
 		PaymentStatus:     transactionInstallment.PaymentStatus,
 		PaidAt:            transactionInstallment.PaidAt,
+		DaysPastDue:       transactionInstallment.DaysPastDue,
+		PenaltyDue:        UnpaidPenalty(transactionInstallment.Penalties),
 		Payments:          PaymentToListModels(transactionInstallment.Payments),
+		Penalties:         InstallmentPenaltyToListModels(transactionInstallment.Penalties),
 		CreatedAt:         transactionInstallment.CreatedAt,
 		UpdatedAt:         transactionInstallment.UpdatedAt,
 	}
@@ -72,10 +89,15 @@ func TransactionInstallmentToListModel(transactionInstallment *entity.Transactio
 		AssetName:         transactionInstallment.Transaction.AssetName,
 		ContractNumber:    transactionInstallment.Transaction.ContractNumber,
 		DueDate:           transactionInstallment.DueDate,
+		PrincipalDue:      transactionInstallment.PrincipalDue,
+		InterestDue:       transactionInstallment.InterestDue,
 		AmountDue:         transactionInstallment.AmountDue,
+		Outstanding:       transactionInstallment.Outstanding,
 		AmountPaid:        transactionInstallment.AmountPaid,
 		PaymentStatus:     transactionInstallment.PaymentStatus,
 		PaidAt:            transactionInstallment.PaidAt,
+		DaysPastDue:       transactionInstallment.DaysPastDue,
+		PenaltyDue:        UnpaidPenalty(transactionInstallment.Penalties),
 	}
 }
 
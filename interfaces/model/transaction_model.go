@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,19 +16,30 @@ type (
 		UUID               uuid.UUID                    `json:"uuid"`
 		UserID             uint                         `json:"user_id"`
 		CustomerName       string                       `json:"customer_name"`
+		ProductCode        string                       `json:"product_code"`
+		MerchantCode       string                       `json:"merchant_code"`
 		AssetName          string                       `json:"asset_name"`
 		ContractNumber     string                       `json:"contract_number"`
+		ContractFileUrl    string                       `json:"contract_file_url"`
+		DisbursementStatus entity.DisbursementStatus    `json:"disbursement_status"`
 		OnTheRoad          decimal.Decimal              `json:"on_the_road"`
 		AdminFee           decimal.Decimal              `json:"admin_fee"`
 		TotalLoanAmount    decimal.Decimal              `json:"total_loan_amount"`
 		MonthlyInstallment decimal.Decimal              `json:"monthly_installment"`
 		InterestAmount     decimal.Decimal              `json:"interest_amount"`
+		InterestMethod     entity.InterestMethod        `json:"interest_method"`
+		InterestRate       decimal.Decimal              `json:"interest_rate"`
+		PenaltyDue         decimal.Decimal              `json:"penalty_due"`
 		Tenor              uint                         `json:"tenor"`
+		BillingDay         uint                         `json:"billing_day"`
 		StartDate          time.Time                    `json:"start_date"`
 		EndDate            time.Time                    `json:"end_date"`
+		CreatedBy          *uint                        `json:"created_by"`
 		Status             entity.TransactionStatus     `json:"status"`
 		Installments       []TransactionInstallmentList `json:"installments"`
 		Payments           []PaymentList                `json:"payments"`
+		Revisions          []TransactionRevisionList    `json:"revisions"`
+		StatusLogs         []TransactionStatusLogList   `json:"status_logs"`
 		CreatedAt          time.Time                    `json:"created_at"`
 		UpdatedAt          time.Time                    `json:"updated_at"`
 	}
@@ -48,35 +60,99 @@ type (
 		Status          entity.TransactionStatus `json:"status"`
 	}
 
+	TransactionSimulation struct {
+		ProductCode        string                  `json:"product_code"`
+		AssetName          string                  `json:"asset_name"`
+		OnTheRoad          decimal.Decimal         `json:"on_the_road"`
+		AdminFee           decimal.Decimal         `json:"admin_fee"`
+		TotalLoanAmount    decimal.Decimal         `json:"total_loan_amount"`
+		MonthlyInstallment decimal.Decimal         `json:"monthly_installment"`
+		InterestMethod     entity.InterestMethod   `json:"interest_method"`
+		InterestRate       decimal.Decimal         `json:"interest_rate"`
+		TotalInterest      decimal.Decimal         `json:"total_interest"`
+		TotalPayable       decimal.Decimal         `json:"total_payable"`
+		Tenor              uint                    `json:"tenor"`
+		BillingDay         uint                    `json:"billing_day"`
+		StartDate          time.Time               `json:"start_date"`
+		EndDate            time.Time               `json:"end_date"`
+		Installments       []InstallmentSimulation `json:"installments"`
+		RemainingLimits    []LimitList             `json:"remaining_limits"`
+	}
+
+	InstallmentSimulation struct {
+		InstallmentNumber uint            `json:"installment_number"`
+		DueDate           time.Time       `json:"due_date"`
+		PrincipalDue      decimal.Decimal `json:"principal_due"`
+		InterestDue       decimal.Decimal `json:"interest_due"`
+		AmountDue         decimal.Decimal `json:"amount_due"`
+		Outstanding       decimal.Decimal `json:"outstanding"`
+	}
+
+	// TransactionInput BillingDay is the day of month installment fall due, empty use the
+	// configured billing day. MerchantCode is the dealer that sold the asset, if any
 	TransactionInput struct {
-		AssetName      string `json:"asset_name" form:"asset_name" xml:"asset_name" validate:"required"`
-		ContractNumber string `json:"contract_number" form:"contract_number" xml:"contract_number" validate:"required"`
-		OnTheRoad      string `json:"on_the_road" form:"on_the_road" xml:"on_the_road" validate:"required,numeric"`
-		AdminFee       string `json:"admin_fee" form:"admin_fee" xml:"admin_fee" validate:"required,numeric"`
-		InterestAmount string `json:"interest_amount" form:"interest_amount" xml:"interest_amount" validate:"required,numeric"`
-		Tenor          uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required"`
+		AssetName    string `json:"asset_name" form:"asset_name" xml:"asset_name" validate:"required"`
+		OnTheRoad    string `json:"on_the_road" form:"on_the_road" xml:"on_the_road" validate:"required,numeric"`
+		ProductCode  string `json:"product_code" form:"product_code" xml:"product_code" validate:"required,max=50"`
+		MerchantCode string `json:"merchant_code" form:"merchant_code" xml:"merchant_code" validate:"omitempty,max=50"`
+		Tenor        uint   `json:"tenor" form:"tenor" xml:"tenor" validate:"required"`
+		BillingDay   uint   `json:"billing_day" form:"billing_day" xml:"billing_day" validate:"omitempty,min=1,max=31"`
+	}
+
+	// TransactionImportInput bring a contract from the legacy system, it keep its
+	// contract number and start date instead of the generated one
+	TransactionImportInput struct {
+		TransactionInput
+		UserUUID       string `json:"user_uuid" form:"user_uuid" xml:"user_uuid" validate:"required,uuid"`
+		ContractNumber string `json:"contract_number" form:"contract_number" xml:"contract_number" validate:"required,max=255"`
+		StartDate      string `json:"start_date" form:"start_date" xml:"start_date" validate:"required,datetime=2006-01-02"`
 	}
 )
 
+// transactionContractFileUrl point to the download endpoint, the file itself is not public
+func transactionContractFileUrl(transaction *entity.Transaction) string {
+	if transaction.ContractFile == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("/api/v1/transactions/data/%s/contract", transaction.UUID)
+}
+
 func TransactionToDetailModel(transaction *entity.Transaction) *TransactionDetail {
+	penaltyDue := decimal.Zero
+	for _, installment := range transaction.Installments {
+		penaltyDue = penaltyDue.Add(UnpaidPenalty(installment.Penalties))
+	}
+
 	return &TransactionDetail{
 		ID:                 transaction.ID,
 		UUID:               transaction.UUID,
 		UserID:             transaction.UserID,
 		CustomerName:       transaction.User.Profile.Name,
+		ProductCode:        transactionProductCode(transaction),
+		MerchantCode:       transactionMerchantCode(transaction),
 		AssetName:          transaction.AssetName,
 		ContractNumber:     transaction.ContractNumber,
+		ContractFileUrl:    transactionContractFileUrl(transaction),
+		DisbursementStatus: transactionDisbursementStatus(transaction),
 		OnTheRoad:          transaction.OnTheRoad,
 		AdminFee:           transaction.AdminFee,
 		TotalLoanAmount:    transaction.TotalLoanAmount,
 		MonthlyInstallment: transaction.MonthlyInstallment,
 		InterestAmount:     transaction.InterestAmount,
+		InterestMethod:     transaction.InterestMethod,
+		InterestRate:       transaction.InterestRate,
+		PenaltyDue:         penaltyDue,
 		Tenor:              transaction.Tenor,
+		BillingDay:         transaction.BillingDay,
 		Status:             transaction.Status,
 		StartDate:          transaction.StartDate,
 		EndDate:            transaction.EndDate,
+		CreatedBy:          transaction.CreatedBy,
 		Installments:       TransactionInstallmentToListModels(transaction.Installments),
 		Payments:           PaymentToListModels(transaction.Payments),
+		Revisions:          TransactionRevisionToListModels(transaction.Revisions),
+		StatusLogs:         TransactionStatusLogToListModels(transaction.StatusLogs),
 		CreatedAt:          transaction.CreatedAt,
 		UpdatedAt:          transaction.UpdatedAt,
 	}
@@ -108,41 +184,115 @@ func TransactionToListModels(transactions []entity.Transaction) (listModels []Tr
 	return listModels
 }
 
+func TransactionToSimulationModel(transaction *entity.Transaction, installments []entity.TransactionInstallment, limits []entity.Limit) *TransactionSimulation {
+	simulation := &TransactionSimulation{
+		ProductCode:        transactionProductCode(transaction),
+		AssetName:          transaction.AssetName,
+		OnTheRoad:          transaction.OnTheRoad,
+		AdminFee:           transaction.AdminFee,
+		TotalLoanAmount:    transaction.OnTheRoad.Add(transaction.AdminFee),
+		MonthlyInstallment: transaction.MonthlyInstallment,
+		InterestMethod:     transaction.InterestMethod,
+		InterestRate:       transaction.InterestRate,
+		TotalInterest:      transaction.InterestAmount,
+		TotalPayable:       decimal.Zero,
+		Tenor:              transaction.Tenor,
+		BillingDay:         transaction.BillingDay,
+		StartDate:          transaction.StartDate,
+		EndDate:            transaction.EndDate,
+		Installments:       make([]InstallmentSimulation, len(installments)),
+		RemainingLimits:    LimitToListModels(limits),
+	}
+
+	for i, installment := range installments {
+		simulation.TotalPayable = simulation.TotalPayable.Add(installment.AmountDue)
+		simulation.Installments[i] = InstallmentSimulation{
+			InstallmentNumber: installment.InstallmentNumber,
+			DueDate:           installment.DueDate,
+			PrincipalDue:      installment.PrincipalDue,
+			InterestDue:       installment.InterestDue,
+			AmountDue:         installment.AmountDue,
+			Outstanding:       installment.Outstanding,
+		}
+	}
+
+	return simulation
+}
+
 func (input *TransactionInput) ToEntity() (*entity.Transaction, error) {
 	otr_decimal, err := decimal.NewFromString(input.OnTheRoad)
 	if err != nil {
 		return nil, err
 	}
-	admin_decimal, err := decimal.NewFromString(input.AdminFee)
+
+	// Admin fee and interest are set from the product by the service
+	return &entity.Transaction{
+		AssetName:  input.AssetName,
+		OnTheRoad:  otr_decimal,
+		StartDate:  time.Now(),
+		Tenor:      input.Tenor,
+		BillingDay: input.BillingDay,
+	}, nil
+}
+
+func (input *TransactionInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.AssetName = sanitizer.Sanitize(input.AssetName)
+	input.OnTheRoad = sanitizer.Sanitize(input.OnTheRoad)
+	input.ProductCode = sanitizer.Sanitize(input.ProductCode)
+	input.MerchantCode = sanitizer.Sanitize(input.MerchantCode)
+}
+
+func (input *TransactionImportInput) ToEntity() (*entity.Transaction, error) {
+	transaction, err := input.TransactionInput.ToEntity()
 	if err != nil {
 		return nil, err
 	}
-	interest_decimal, err := decimal.NewFromString(input.InterestAmount)
+
+	start_date, err := time.Parse("2006-01-02", input.StartDate)
 	if err != nil {
 		return nil, err
 	}
-	tenor_decimal := decimal.NewFromUint64(uint64(input.Tenor))
-	monthly_decimal := ((otr_decimal.Add(admin_decimal)).Div(tenor_decimal)).Add(interest_decimal)
 
-	return &entity.Transaction{
-		AssetName:          input.AssetName,
-		ContractNumber:     input.ContractNumber,
-		OnTheRoad:          otr_decimal,
-		AdminFee:           admin_decimal,
-		MonthlyInstallment: monthly_decimal,
-		InterestAmount:     interest_decimal,
-		StartDate:          time.Now(),
-		EndDate:            time.Now().AddDate(0, int(input.Tenor), 1),
-		Tenor:              input.Tenor,
-	}, nil
+	transaction.ContractNumber = input.ContractNumber
+	transaction.StartDate = start_date
+
+	return transaction, nil
 }
 
-func (input *TransactionInput) Sanitize() {
+func (input *TransactionImportInput) Sanitize() {
 	sanitizer := bluemonday.StrictPolicy()
 
-	input.AssetName = sanitizer.Sanitize(input.AssetName)
+	input.TransactionInput.Sanitize()
+	input.UserUUID = sanitizer.Sanitize(input.UserUUID)
 	input.ContractNumber = sanitizer.Sanitize(input.ContractNumber)
-	input.OnTheRoad = sanitizer.Sanitize(input.OnTheRoad)
-	input.AdminFee = sanitizer.Sanitize(input.AdminFee)
-	input.InterestAmount = sanitizer.Sanitize(input.InterestAmount)
+	input.StartDate = sanitizer.Sanitize(input.StartDate)
+}
+
+// transactionProductCode is empty for contract made before product exist
+func transactionProductCode(transaction *entity.Transaction) string {
+	if transaction.Product == nil {
+		return ""
+	}
+
+	return transaction.Product.Code
+}
+
+// transactionMerchantCode is empty for contract without dealer
+func transactionMerchantCode(transaction *entity.Transaction) string {
+	if transaction.Merchant == nil {
+		return ""
+	}
+
+	return transaction.Merchant.Code
+}
+
+// transactionDisbursementStatus is empty until a contract with dealer become active
+func transactionDisbursementStatus(transaction *entity.Transaction) entity.DisbursementStatus {
+	if transaction.Disbursement == nil {
+		return ""
+	}
+
+	return transaction.Disbursement.Status
 }
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	TransactionRevisionList struct {
+		ID                 uint                  `json:"id"`
+		TransactionID      uint                  `json:"transaction_id"`
+		Revision           uint                  `json:"revision"`
+		AssetName          string                `json:"asset_name"`
+		OnTheRoad          decimal.Decimal       `json:"on_the_road"`
+		AdminFee           decimal.Decimal       `json:"admin_fee"`
+		MonthlyInstallment decimal.Decimal       `json:"monthly_installment"`
+		InterestAmount     decimal.Decimal       `json:"interest_amount"`
+		InterestMethod     entity.InterestMethod `json:"interest_method"`
+		InterestRate       decimal.Decimal       `json:"interest_rate"`
+		Tenor              uint                  `json:"tenor"`
+		StartDate          time.Time             `json:"start_date"`
+		EndDate            time.Time             `json:"end_date"`
+		ChangedBy          uint                  `json:"changed_by"`
+		CreatedAt          time.Time             `json:"created_at"`
+	}
+)
+
+func TransactionRevisionToListModel(revision *entity.TransactionRevision) *TransactionRevisionList {
+	return &TransactionRevisionList{
+		ID:                 revision.ID,
+		TransactionID:      revision.TransactionID,
+		Revision:           revision.Revision,
+		AssetName:          revision.AssetName,
+		OnTheRoad:          revision.OnTheRoad,
+		AdminFee:           revision.AdminFee,
+		MonthlyInstallment: revision.MonthlyInstallment,
+		InterestAmount:     revision.InterestAmount,
+		InterestMethod:     revision.InterestMethod,
+		InterestRate:       revision.InterestRate,
+		Tenor:              revision.Tenor,
+		StartDate:          revision.StartDate,
+		EndDate:            revision.EndDate,
+		ChangedBy:          revision.ChangedBy,
+		CreatedAt:          revision.CreatedAt,
+	}
+}
+
+func TransactionRevisionToListModels(revisions []entity.TransactionRevision) (listModels []TransactionRevisionList) {
+	for _, revision := range revisions {
+		listModels = append(listModels, *TransactionRevisionToListModel(&revision))
+	}
+
+	return listModels
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	TransactionStatusLogList struct {
+		ID         uint                     `json:"id"`
+		FromStatus entity.TransactionStatus `json:"from_status"`
+		ToStatus   entity.TransactionStatus `json:"to_status"`
+		Reason     string                   `json:"reason"`
+		ChangedBy  *uint                    `json:"changed_by"`
+		CreatedAt  time.Time                `json:"created_at"`
+	}
+
+	// TransactionStatusInput is the status an admin set by hand, paid and canceled
+	// follow their own flow because money move with them
+	TransactionStatusInput struct {
+		Status string `json:"status" form:"status" xml:"status" validate:"required,oneof=active defaulted written_off"`
+		Reason string `json:"reason" form:"reason" xml:"reason" validate:"required,max=255"`
+	}
+
+	// TransactionReviewInput is the decision on a contract waiting for approval
+	TransactionReviewInput struct {
+		Decision string `json:"decision" form:"decision" xml:"decision" validate:"required,oneof=approve reject"`
+		Notes    string `json:"notes" form:"notes" xml:"notes" validate:"required,max=255"`
+	}
+)
+
+const (
+	ReviewApprove = "approve"
+	ReviewReject  = "reject"
+)
+
+func TransactionStatusLogToListModel(statusLog *entity.TransactionStatusLog) *TransactionStatusLogList {
+	return &TransactionStatusLogList{
+		ID:         statusLog.ID,
+		FromStatus: statusLog.FromStatus,
+		ToStatus:   statusLog.ToStatus,
+		Reason:     statusLog.Reason,
+		ChangedBy:  statusLog.ChangedBy,
+		CreatedAt:  statusLog.CreatedAt,
+	}
+}
+
+func TransactionStatusLogToListModels(statusLogs []entity.TransactionStatusLog) (listModels []TransactionStatusLogList) {
+	for _, statusLog := range statusLogs {
+		listModels = append(listModels, *TransactionStatusLogToListModel(&statusLog))
+	}
+
+	return listModels
+}
+
+func (input *TransactionStatusInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Status = sanitizer.Sanitize(input.Status)
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *TransactionReviewInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Decision = sanitizer.Sanitize(input.Decision)
+	input.Notes = sanitizer.Sanitize(input.Notes)
+}
@@ -7,5 +7,6 @@ const (
 	CtxKeyUsername   contextKey = "username"
 	CtxKeyUserID     contextKey = "user_id"
 	CtxKeyIsAdmin    contextKey = "is_admin"
+	CtxKeyPartnerID  contextKey = "partner_id"
 	CtxKeyFunction   contextKey = "function"
 )
@@ -479,6 +479,11 @@ func ExtractIdentifierAndUsername(c *fiber.Ctx) context.Context {
 	ctx = context.WithValue(ctx, CtxKeyUserID, user_id)
 	ctx = context.WithValue(ctx, CtxKeyIsAdmin, is_admin)
 
+	// Merchant id of a signed partner request
+	if partner_id, ok := c.Locals("partner_id").(uint); ok {
+		ctx = context.WithValue(ctx, CtxKeyPartnerID, partner_id)
+	}
+
 	return ctx
 }
 
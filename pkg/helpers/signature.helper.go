@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+// SignPayload return hex encoded HMAC SHA256 of payload
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature compare signature with the payload signed by secret in constant time
+func ValidSignature(secret string, payload []byte, signature string) bool {
+	expected := SignPayload(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// PartnerSecretKey return the app key that encrypt partner secrets at rest,
+// PARTNER_SECRET_KEY is the hex of 32 random bytes
+func PartnerSecretKey() ([]byte, error) {
+	key, err := hex.DecodeString(config.AppConfig.PartnerSecretKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("PARTNER_SECRET_KEY must be hex of 32 bytes")
+	}
+
+	return key, nil
+}
+
+// EncryptSecret seal secret with AES-256-GCM under key, the result is base64 of the
+// random nonce followed by the ciphertext
+func EncryptSecret(key []byte, secret string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret open a secret sealed by EncryptSecret under the same key
+func DecryptSecret(key []byte, sealed string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// PartnerSigningPayload is the string a partner sign: method, path with query, timestamp
+// and nonce each on its own line followed by the raw body
+func PartnerSigningPayload(method string, path string, timestamp string, nonce string, body []byte) []byte {
+	payload := []byte(method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n")
+	return append(payload, body...)
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Admin only routes must refuse a validated customer before reaching the handler
+func TestAdminRoutes_UserForbidden(t *testing.T) {
+	token := GenerateUserTestToken()
+	id := uuid.NewString()
+
+	routes := []struct {
+		method string
+		url    string
+	}{
+		{"POST", "/api/v1/transactions/limit/" + id},
+		{"POST", "/api/v1/transactions/limit/" + id + "/raise"},
+		{"POST", "/api/v1/transactions/limit/" + id + "/lower"},
+		{"POST", "/api/v1/transactions/limit/" + id + "/freeze"},
+		{"POST", "/api/v1/transactions/limit/" + id + "/unfreeze"},
+		{"DELETE", "/api/v1/transactions/limit/" + id},
+		{"POST", "/api/v1/transactions/limit-policy"},
+		{"PUT", "/api/v1/transactions/limit-policy/" + id},
+		{"DELETE", "/api/v1/transactions/limit-policy/" + id},
+		{"POST", "/api/v1/transactions/limit-policy/" + id + "/apply"},
+		{"GET", "/api/v1/transactions/credit-score/" + id},
+		{"POST", "/api/v1/transactions/credit-score/" + id},
+		{"POST", "/api/v1/transactions/product"},
+		{"PUT", "/api/v1/transactions/product/" + id},
+		{"DELETE", "/api/v1/transactions/product/" + id},
+		{"POST", "/api/v1/transactions/data/import"},
+		{"POST", "/api/v1/transactions/holiday"},
+		{"PUT", "/api/v1/transactions/holiday/" + id},
+		{"DELETE", "/api/v1/transactions/holiday/" + id},
+		{"POST", "/api/v1/transactions/data/status/" + id},
+		{"GET", "/api/v1/transactions/underwriting"},
+		{"POST", "/api/v1/transactions/underwriting/" + id},
+		{"GET", "/api/v1/transactions/disbursement"},
+		{"GET", "/api/v1/transactions/disbursement/owed"},
+		{"GET", "/api/v1/transactions/disbursement/" + id},
+		{"POST", "/api/v1/transactions/disbursement/status/" + id},
+		{"GET", "/api/v1/transactions/merchant/" + id},
+		{"POST", "/api/v1/transactions/merchant"},
+		{"PUT", "/api/v1/transactions/merchant/" + id},
+		{"DELETE", "/api/v1/transactions/merchant/" + id},
+		{"GET", "/api/v1/transactions/partner-key/merchant/" + id},
+		{"POST", "/api/v1/transactions/partner-key/merchant/" + id},
+		{"DELETE", "/api/v1/transactions/partner-key/" + id},
+	}
+
+	for _, route := range routes {
+		recorder := MakeRequest(t, route.method, route.url, map[string]interface{}{"tenor": 6, "amount": "1000000"}, token)
+		assert.Equal(t, 403, recorder.Code, "%s %s", route.method, route.url)
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/allocation"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInstallment(id uint, principal, interest int64) entity.TransactionInstallment {
+	return entity.TransactionInstallment{
+		ID:                id,
+		InstallmentNumber: id,
+		PrincipalDue:      decimal.NewFromInt(principal),
+		InterestDue:       decimal.NewFromInt(interest),
+		AmountDue:         decimal.NewFromInt(principal + interest),
+		AmountPaid:        decimal.Zero,
+	}
+}
+
+func TestAllocate_PenaltyInterestPrincipalOrder(t *testing.T) {
+	first := newTestInstallment(1, 900, 100)
+	first.Penalties = []entity.InstallmentPenalty{
+		{ID: 10, Amount: decimal.NewFromInt(50), AmountPaid: decimal.Zero},
+	}
+
+	result := allocation.Allocate([]entity.TransactionInstallment{first}, decimal.NewFromInt(200))
+
+	require.Len(t, result.Lines, 1)
+	line := result.Lines[0]
+	assert.True(t, decimal.NewFromInt(50).Equal(line.Penalty))
+	assert.True(t, decimal.NewFromInt(100).Equal(line.Interest))
+	assert.True(t, decimal.NewFromInt(50).Equal(line.Principal))
+	assert.False(t, line.Settled)
+	assert.True(t, result.Credit.IsZero())
+}
+
+func TestAllocate_MultipleInstallmentsAndCredit(t *testing.T) {
+	first := newTestInstallment(1, 900, 100)
+	first.AmountPaid = decimal.NewFromInt(40)
+	second := newTestInstallment(2, 900, 100)
+
+	result := allocation.Allocate([]entity.TransactionInstallment{first, second}, decimal.NewFromInt(2100))
+
+	require.Len(t, result.Lines, 2)
+	assert.True(t, decimal.NewFromInt(60).Equal(result.Lines[0].Interest))
+	assert.True(t, decimal.NewFromInt(900).Equal(result.Lines[0].Principal))
+	assert.True(t, result.Lines[0].Settled)
+	assert.True(t, decimal.NewFromInt(1000).Equal(result.Lines[1].Amount))
+	assert.True(t, result.Lines[1].Settled)
+	assert.True(t, decimal.NewFromInt(140).Equal(result.Credit))
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/limit"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimit(tenor uint, original, current int64, mode entity.LimitPoolMode) entity.Limit {
+	return entity.Limit{
+		ID:            tenor,
+		UserID:        2,
+		Tenor:         tenor,
+		OriginalLimit: decimal.NewFromInt(original),
+		CurrentLimit:  decimal.NewFromInt(current),
+		PoolMode:      mode,
+	}
+}
+
+func TestLimitAmend_SharedPoolRecompute(t *testing.T) {
+	// 5.000.000 on tenor 6 was taken from every limit of the shared pool
+	limits := []entity.Limit{
+		newTestLimit(3, 6000000, 1000000, ""),
+		newTestLimit(6, 10000000, 5000000, ""),
+	}
+
+	updated, mode, err := limit.Amend(limits, 6, entity.LimitPoolShared, decimal.NewFromInt(5000000), 6, decimal.NewFromInt(3000000))
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.LimitPoolShared, mode)
+	assert.True(t, decimal.NewFromInt(3000000).Equal(updated[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(7000000).Equal(updated[1].CurrentLimit))
+}
+
+func TestLimitAmend_TenorChangeUnderPerTenor(t *testing.T) {
+	limits := []entity.Limit{
+		newTestLimit(3, 4000000, 4000000, entity.LimitPoolPerTenor),
+		newTestLimit(6, 10000000, 5000000, entity.LimitPoolPerTenor),
+	}
+
+	updated, mode, err := limit.Amend(limits, 6, entity.LimitPoolPerTenor, decimal.NewFromInt(5000000), 3, decimal.NewFromInt(2000000))
+	require.NoError(t, err)
+
+	// Tenor 6 get its otr back, the amended otr is taken from tenor 3 only
+	assert.Equal(t, entity.LimitPoolPerTenor, mode)
+	assert.True(t, decimal.NewFromInt(2000000).Equal(updated[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(10000000).Equal(updated[1].CurrentLimit))
+}
+
+func TestLimitAmend_OverlimitKeepLimits(t *testing.T) {
+	limits := []entity.Limit{
+		newTestLimit(6, 10000000, 5000000, ""),
+	}
+
+	_, _, err := limit.Amend(limits, 6, entity.LimitPoolShared, decimal.NewFromInt(5000000), 6, decimal.NewFromInt(12000000))
+	assert.ErrorIs(t, err, limit.ErrOverlimit)
+
+	// The restored limit is only computed, nothing change on the current one
+	assert.True(t, decimal.NewFromInt(5000000).Equal(limits[0].CurrentLimit))
+
+	frozen := []entity.Limit{newTestLimit(6, 10000000, 5000000, "")}
+	frozen[0].FrozenAt.Valid = true
+	_, _, err = limit.Amend(frozen, 6, entity.LimitPoolShared, decimal.NewFromInt(5000000), 6, decimal.NewFromInt(1000000))
+	assert.ErrorIs(t, err, limit.ErrFrozen)
+}
+
+func TestTransactionRevision_KeepPreviousTerms(t *testing.T) {
+	transaction := &entity.Transaction{
+		ID:             7,
+		AssetName:      "Refrigerator",
+		OnTheRoad:      decimal.NewFromInt(5000000),
+		AdminFee:       decimal.NewFromInt(50000),
+		InterestMethod: entity.InterestFlat,
+		InterestRate:   decimal.NewFromInt(2),
+		Tenor:          6,
+		BillingDay:     5,
+		StartDate:      time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+	}
+
+	first := entity.NewTransactionRevision(transaction, 1, 9)
+
+	// Second amendment snapshot the terms of the first one
+	transaction.AssetName = "Washing machine"
+	transaction.OnTheRoad = decimal.NewFromInt(3000000)
+	transaction.Tenor = 3
+	second := entity.NewTransactionRevision(transaction, 2, 9)
+
+	assert.Equal(t, uint(7), first.TransactionID)
+	assert.Equal(t, uint(1), first.Revision)
+	assert.Equal(t, "Refrigerator", first.AssetName)
+	assert.True(t, decimal.NewFromInt(5000000).Equal(first.OnTheRoad))
+	assert.Equal(t, uint(6), first.Tenor)
+	assert.Equal(t, uint(9), first.ChangedBy)
+
+	assert.Equal(t, uint(2), second.Revision)
+	assert.Equal(t, "Washing machine", second.AssetName)
+	assert.True(t, decimal.NewFromInt(3000000).Equal(second.OnTheRoad))
+	assert.Equal(t, uint(3), second.Tenor)
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/amortization"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmortizationSchedule_RowsSumToPrincipal(t *testing.T) {
+	principal := decimal.NewFromInt(1000000)
+	rate := decimal.RequireFromString("1.75")
+
+	for _, method := range []entity.InterestMethod{
+		entity.InterestFlat,
+		entity.InterestAnnuity,
+		entity.InterestEffective,
+	} {
+		schedule, err := amortization.Generate(method, principal, rate, 7)
+		require.NoError(t, err)
+		require.Len(t, schedule.Rows, 7)
+
+		totalPrincipal := decimal.Zero
+		totalAmount := decimal.Zero
+		for _, row := range schedule.Rows {
+			totalPrincipal = totalPrincipal.Add(row.Principal)
+			totalAmount = totalAmount.Add(row.Amount)
+			assert.True(t, row.Amount.Equal(row.Principal.Add(row.Interest)))
+		}
+
+		assert.True(t, principal.Equal(totalPrincipal), "method %s principal %s", method, totalPrincipal)
+		assert.True(t, schedule.TotalPayable.Equal(totalAmount), "method %s", method)
+		assert.True(t, schedule.Rows[6].Balance.IsZero(), "method %s", method)
+	}
+}
+
+func TestAmortizationSchedule_Flat(t *testing.T) {
+	schedule, err := amortization.Generate(entity.InterestFlat, decimal.NewFromInt(600000), decimal.NewFromInt(2), 6)
+	require.NoError(t, err)
+
+	assert.True(t, decimal.NewFromInt(72000).Equal(schedule.TotalInterest))
+	for _, row := range schedule.Rows {
+		assert.True(t, decimal.NewFromInt(112000).Equal(row.Amount))
+	}
+}
+
+func TestAmortizationSchedule_UnknownMethod(t *testing.T) {
+	_, err := amortization.Generate("balloon", decimal.NewFromInt(1000), decimal.NewFromInt(1), 3)
+	assert.Error(t, err)
+}
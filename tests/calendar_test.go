@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/calendar"
+	"github.com/stretchr/testify/assert"
+)
+
+func calendarDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendar_DueDateClampEndOfMonth(t *testing.T) {
+	start := calendarDate(2026, time.January, 31)
+
+	assert.Equal(t, calendarDate(2026, time.February, 28), calendar.DueDate(start, 1, 0))
+	assert.Equal(t, calendarDate(2026, time.March, 31), calendar.DueDate(start, 2, 0))
+	assert.Equal(t, calendarDate(2026, time.April, 30), calendar.DueDate(start, 3, 0))
+
+	// Billing day take over the start day
+	assert.Equal(t, calendarDate(2026, time.February, 5), calendar.DueDate(start, 1, 5))
+	assert.Equal(t, calendarDate(2027, time.January, 25), calendar.DueDate(start, 12, 25))
+}
+
+func TestCalendar_DueDatesSkipWeekendAndHoliday(t *testing.T) {
+	// Mar 14 2026 is a Saturday, Mar 16 is set as holiday
+	cal := calendar.New([]time.Time{calendarDate(2026, time.March, 16)})
+	start := calendarDate(2026, time.January, 14)
+
+	dates := cal.DueDates(start, 3, 0)
+	assert.Equal(t, []time.Time{
+		calendarDate(2026, time.February, 16),
+		calendarDate(2026, time.March, 17),
+		calendarDate(2026, time.April, 14),
+	}, dates)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/limit"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckoutSession_IsOpen(t *testing.T) {
+	expires_at := time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC)
+	session := &entity.CheckoutSession{Status: entity.CheckoutPending, ExpiresAt: expires_at}
+
+	assert.True(t, session.IsOpen(expires_at.Add(-time.Minute)))
+
+	// Expired by time even before the worker release it
+	assert.False(t, session.IsOpen(expires_at))
+	assert.False(t, session.IsOpen(expires_at.Add(time.Minute)))
+
+	// Closed session is never open again
+	session.Status = entity.CheckoutConfirmed
+	assert.False(t, session.IsOpen(expires_at.Add(-time.Minute)))
+	session.Status = entity.CheckoutExpired
+	assert.False(t, session.IsOpen(expires_at.Add(-time.Minute)))
+}
+
+func TestCheckoutLimit_Hold(t *testing.T) {
+	limits := []entity.Limit{
+		newTestLimit(3, 6000000, 6000000, ""),
+		newTestLimit(6, 10000000, 10000000, ""),
+	}
+
+	// Hold take the otr under the pool mode of the tenor, stored on the session
+	held, err := limit.Apply(limits, 6, "", decimal.NewFromInt(4000000), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.LimitPoolShared, limit.PoolMode(limits, 6))
+	assert.True(t, decimal.NewFromInt(2000000).Equal(held[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(6000000).Equal(held[1].CurrentLimit))
+
+	_, err = limit.Apply(limits, 6, "", decimal.NewFromInt(11000000), true)
+	assert.ErrorIs(t, err, limit.ErrOverlimit)
+}
+
+func TestCheckoutLimit_ConfirmUnderHeldPoolMode(t *testing.T) {
+	// Held 4.000.000 on tenor 6 under shared, the limits moved to per tenor since
+	limits := []entity.Limit{
+		newTestLimit(3, 6000000, 2000000, entity.LimitPoolPerTenor),
+		newTestLimit(6, 10000000, 6000000, entity.LimitPoolPerTenor),
+	}
+
+	released, updated, err := limit.Confirm(limits, 6, entity.LimitPoolShared, decimal.NewFromInt(4000000), 6, decimal.NewFromInt(4000000))
+	require.NoError(t, err)
+
+	// The hold is given back to every limit it was taken from
+	assert.True(t, decimal.NewFromInt(6000000).Equal(released[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(10000000).Equal(released[1].CurrentLimit))
+
+	// And the contract take it again from the same limits, not only from tenor 6
+	assert.True(t, decimal.NewFromInt(2000000).Equal(updated[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(6000000).Equal(updated[1].CurrentLimit))
+
+	// Nothing is changed when the contract does not fit the released limits
+	_, _, err = limit.Confirm(limits, 6, entity.LimitPoolShared, decimal.NewFromInt(4000000), 6, decimal.NewFromInt(11000000))
+	assert.ErrorIs(t, err, limit.ErrOverlimit)
+	assert.True(t, decimal.NewFromInt(2000000).Equal(limits[0].CurrentLimit))
+}
+
+func TestCheckoutLimit_CancelAndExpireRelease(t *testing.T) {
+	held := []entity.Limit{
+		newTestLimit(3, 6000000, 2000000, ""),
+		newTestLimit(6, 10000000, 6000000, ""),
+	}
+
+	// Cancel and expire both give the hold back under the session pool mode
+	released, err := limit.Apply(held, 6, entity.LimitPoolShared, decimal.NewFromInt(4000000), false)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(6000000).Equal(released[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(10000000).Equal(released[1].CurrentLimit))
+
+	// Release is capped at the original limit, and still allowed on a frozen limit
+	held[1].CurrentLimit = decimal.NewFromInt(9000000)
+	held[1].FrozenAt.Valid = true
+	released, err = limit.Apply(held, 6, entity.LimitPoolShared, decimal.NewFromInt(4000000), false)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10000000).Equal(released[1].CurrentLimit))
+}
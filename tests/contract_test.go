@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/contract"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContract_RenderFormat(t *testing.T) {
+	format := "XYZ/{branch}/{yyyyMM}/{seq:6}"
+	at := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, contract.Validate(format))
+	assert.Equal(t, "XYZ/JKT/202603/{seq:6}", contract.Scope(format, "JKT", at))
+	assert.Equal(t, "XYZ/JKT/202603/000042", contract.Render(format, "JKT", at, 42))
+	assert.Equal(t, "XYZ/JKT/202603/1234567", contract.Render(format, "JKT", at, 1234567))
+	assert.Equal(t, "C-26-7", contract.Render("C-{yy}-{seq}", "JKT", at, 7))
+}
+
+func TestContract_ValidateFormat(t *testing.T) {
+	assert.Error(t, contract.Validate("XYZ/{branch}/{yyyyMM}"))
+	assert.Error(t, contract.Validate("{seq}/{seq:4}"))
+	assert.Error(t, contract.Validate("XYZ/{region}/{seq:6}"))
+}
+
+func TestContract_MatchesFormat(t *testing.T) {
+	format := "KTR/{branch}/{yyyyMM}/{seq:6}"
+
+	assert.True(t, contract.Matches(format, "KTR/HO/202603/000001"))
+	assert.True(t, contract.Matches(format, "KTR/JKT-01/202603/1000000"))
+	assert.False(t, contract.Matches(format, "KTR/HO/202603/001"))
+	assert.False(t, contract.Matches(format, "LEGACY-2019-0001"))
+	assert.False(t, contract.Matches("K.{seq}", "KX1"))
+}
+
+func TestContract_Document(t *testing.T) {
+	start := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	transaction := &entity.Transaction{
+		ContractNumber:     "KTR/HO/202603/000001",
+		AssetName:          "Motorcycle",
+		OnTheRoad:          decimal.NewFromInt(3000000),
+		MonthlyInstallment: decimal.NewFromInt(1000000),
+		Tenor:              3,
+		StartDate:          start,
+		EndDate:            start.AddDate(0, 3, 1),
+		User: entity.User{
+			Profile: &entity.UserProfile{LegalName: "Budi Santoso", Nik: "3171000000000001", BirthDate: start.AddDate(-30, 0, 0)},
+		},
+	}
+
+	installments := []entity.TransactionInstallment{}
+	for i := 3; i >= 1; i-- {
+		installments = append(installments, entity.TransactionInstallment{
+			InstallmentNumber: uint(i),
+			DueDate:           start.AddDate(0, i, 0),
+			PrincipalDue:      decimal.NewFromInt(1000000),
+			AmountDue:         decimal.NewFromInt(1000000),
+			Outstanding:       decimal.NewFromInt(int64(3-i) * 1000000),
+		})
+	}
+
+	document, err := contract.Document(transaction, installments)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF")))
+}
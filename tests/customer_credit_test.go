@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/allocation"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerCredit_Available(t *testing.T) {
+	credit := &entity.CustomerCredit{Amount: decimal.NewFromInt(500), AmountUsed: decimal.Zero}
+	assert.True(t, decimal.NewFromInt(500).Equal(credit.Available()))
+
+	credit.AmountUsed = decimal.NewFromInt(500)
+	assert.False(t, credit.Available().IsPositive())
+}
+
+func TestCustomerCredit_ApplyCappedAtOutstanding(t *testing.T) {
+	first := newTestInstallment(1, 900, 100)
+	first.AmountPaid = decimal.NewFromInt(400)
+	first.Penalties = []entity.InstallmentPenalty{{ID: 1, Amount: decimal.NewFromInt(25), AmountPaid: decimal.Zero}}
+	second := newTestInstallment(2, 900, 100)
+	installments := []entity.TransactionInstallment{first, second}
+
+	// 600 left on the first, its penalty and 1000 on the second
+	outstanding := allocation.Outstanding(installments)
+	assert.True(t, decimal.NewFromInt(1625).Equal(outstanding))
+
+	credit := &entity.CustomerCredit{Amount: decimal.NewFromInt(2000), AmountUsed: decimal.Zero}
+	amount := decimal.Min(credit.Available(), outstanding)
+	assert.True(t, outstanding.Equal(amount))
+
+	// Applying no more than outstanding never leave a new credit behind
+	result := allocation.Allocate(installments, amount)
+	assert.True(t, result.Credit.IsZero())
+	for _, line := range result.Lines {
+		assert.True(t, line.Settled)
+	}
+}
+
+func TestLedger_PaymentFromCreditAndReverse(t *testing.T) {
+	credit_id := uint(3)
+	payment := &entity.Payment{
+		ID:             2,
+		Amount:         decimal.NewFromInt(1000),
+		PaymentMethod:  entity.PaymentMethodCustomerCredit,
+		CreditSourceID: &credit_id,
+		Allocations: []entity.PaymentAllocation{
+			{InterestAmount: decimal.NewFromInt(100), PrincipalAmount: decimal.NewFromInt(900)},
+		},
+	}
+
+	entry := ledger.Payment(payment)
+
+	// The credit liability is used instead of cash
+	require.NoError(t, ledger.Validate(entry))
+	cashDebit, _ := postingOf(entry, ledger.AccountCash)
+	assert.True(t, cashDebit.IsZero())
+	creditDebit, _ := postingOf(entry, ledger.AccountCustomerCredit)
+	assert.True(t, decimal.NewFromInt(1000).Equal(creditDebit))
+	_, receivable := postingOf(entry, ledger.AccountLoanReceivable)
+	assert.True(t, decimal.NewFromInt(900).Equal(receivable))
+
+	// Reversal give the liability back
+	reversed := ledger.Reverse(entry, ledger.SourcePayment, 4, "Reversal")
+	require.NoError(t, ledger.Validate(reversed))
+	_, creditBack := postingOf(reversed, ledger.AccountCustomerCredit)
+	assert.True(t, decimal.NewFromInt(1000).Equal(creditBack))
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisbursement_Transition(t *testing.T) {
+	sent_at := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	disbursement := &entity.Disbursement{Status: entity.DisbursementPending}
+
+	assert.NoError(t, disbursement.Transition(entity.DisbursementFailed, "", "Account closed", sent_at))
+	assert.Nil(t, disbursement.SentAt)
+	assert.True(t, disbursement.Status.IsOpen())
+
+	// Failed one can be sent again
+	assert.NoError(t, disbursement.Transition(entity.DisbursementSent, "TRF-0001", "", sent_at))
+	assert.Equal(t, "TRF-0001", disbursement.Reference)
+	assert.Equal(t, sent_at, *disbursement.SentAt)
+	assert.False(t, disbursement.Status.IsOpen())
+
+	// Sent is final
+	assert.Error(t, disbursement.Transition(entity.DisbursementCanceled, "", "", sent_at))
+	assert.Equal(t, entity.DisbursementSent, disbursement.Status)
+}
+
+func TestMerchant_SettlementDate(t *testing.T) {
+	disbursed_at := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, disbursed_at, (&entity.Merchant{}).SettlementDate(disbursed_at))
+	assert.Equal(t, time.Date(2024, 2, 6, 0, 0, 0, 0, time.UTC), (&entity.Merchant{SettlementDays: 7}).SettlementDate(disbursed_at))
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyStore keep idempotency records in memory in place of redis
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{data: map[string][]byte{}}
+}
+
+func (m *memoryIdempotencyStore) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[key]; ok {
+		return false, nil
+	}
+	m.data[key] = value.([]byte)
+	return true, nil
+}
+
+func (m *memoryIdempotencyStore) GetObject(ctx context.Context, key string, dest interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.data[key]
+	if !ok {
+		return errors.New("key not found")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (m *memoryIdempotencyStore) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[key] = value.([]byte)
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Del(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, key)
+	return nil
+}
+
+// newIdempotencyApp return an app whose handler count its calls and answer with status
+func newIdempotencyApp(t *testing.T, status *int, calls *int, block chan struct{}) *fiber.App {
+	middleware.SetIdempotencyStore(newMemoryIdempotencyStore())
+	t.Cleanup(func() { middleware.SetIdempotencyStore(nil) })
+
+	app := fiber.New()
+	app.Post("/orders", middleware.Idempotency(), func(c *fiber.Ctx) error {
+		*calls++
+		if block != nil {
+			<-block
+		}
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  *status,
+			Success: *status < fiber.StatusBadRequest,
+			Message: "Order created",
+			Data:    *calls,
+		})
+	})
+
+	return app
+}
+
+func sendIdempotent(t *testing.T, app *fiber.App, key, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	raw, _ := io.ReadAll(resp.Body)
+	var result map[string]interface{}
+	json.Unmarshal(raw, &result)
+
+	return resp.StatusCode, result
+}
+
+func TestIdempotency_ReplaySameKeyAndBody(t *testing.T) {
+	status, calls := fiber.StatusCreated, 0
+	app := newIdempotencyApp(t, &status, &calls, nil)
+
+	code, first := sendIdempotent(t, app, "key-1", `{"amount":100}`)
+	assert.Equal(t, fiber.StatusCreated, code)
+
+	code, second := sendIdempotent(t, app, "key-1", `{"amount":100}`)
+	assert.Equal(t, fiber.StatusCreated, code)
+	assert.Equal(t, first["data"], second["data"])
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_DifferentBodyRejected(t *testing.T) {
+	status, calls := fiber.StatusCreated, 0
+	app := newIdempotencyApp(t, &status, &calls, nil)
+
+	sendIdempotent(t, app, "key-1", `{"amount":100}`)
+	code, _ := sendIdempotent(t, app, "key-1", `{"amount":200}`)
+
+	assert.Equal(t, fiber.StatusUnprocessableEntity, code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_ConflictWhileProcessing(t *testing.T) {
+	status, calls := fiber.StatusCreated, 0
+	block := make(chan struct{})
+	app := newIdempotencyApp(t, &status, &calls, block)
+
+	done := make(chan int)
+	go func() {
+		code, _ := sendIdempotent(t, app, "key-1", `{"amount":100}`)
+		done <- code
+	}()
+
+	// Wait for the first request to reserve the key
+	require.Eventually(t, func() bool {
+		code, _ := sendIdempotent(t, app, "key-1", `{"amount":100}`)
+		return code == fiber.StatusConflict
+	}, time.Second, 10*time.Millisecond)
+
+	close(block)
+	assert.Equal(t, fiber.StatusCreated, <-done)
+}
+
+func TestIdempotency_ServerErrorNotCached(t *testing.T) {
+	status, calls := fiber.StatusInternalServerError, 0
+	app := newIdempotencyApp(t, &status, &calls, nil)
+
+	code, _ := sendIdempotent(t, app, "key-1", `{"amount":100}`)
+	assert.Equal(t, fiber.StatusInternalServerError, code)
+
+	// Retry with the same key reach the handler again
+	status = fiber.StatusCreated
+	code, _ = sendIdempotent(t, app, "key-1", `{"amount":100}`)
+	assert.Equal(t, fiber.StatusCreated, code)
+	assert.Equal(t, 2, calls)
+}
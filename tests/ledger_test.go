@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postingOf(entry *entity.JournalEntry, account string) (debit, credit decimal.Decimal) {
+	debit, credit = decimal.Zero, decimal.Zero
+	for _, posting := range entry.Postings {
+		if posting.AccountCode == account {
+			debit = debit.Add(posting.Debit)
+			credit = credit.Add(posting.Credit)
+		}
+	}
+	return
+}
+
+func TestLedger_DisbursementIsBalanced(t *testing.T) {
+	transaction := &entity.Transaction{
+		ID:        1,
+		OnTheRoad: decimal.NewFromInt(10000),
+		AdminFee:  decimal.NewFromInt(500),
+	}
+
+	entry := ledger.Disbursement(transaction)
+
+	require.NoError(t, ledger.Validate(entry))
+	debit, _ := postingOf(entry, ledger.AccountLoanReceivable)
+	assert.True(t, decimal.NewFromInt(10500).Equal(debit))
+	_, credit := postingOf(entry, ledger.AccountFeeIncome)
+	assert.True(t, decimal.NewFromInt(500).Equal(credit))
+}
+
+func TestLedger_PaymentSplitAllocationCreditAndFee(t *testing.T) {
+	payment := &entity.Payment{
+		ID:     1,
+		Amount: decimal.NewFromInt(1300),
+		Allocations: []entity.PaymentAllocation{
+			{PenaltyAmount: decimal.NewFromInt(50), InterestAmount: decimal.NewFromInt(100), PrincipalAmount: decimal.NewFromInt(900)},
+		},
+		Credit: &entity.CustomerCredit{Amount: decimal.NewFromInt(200)},
+	}
+
+	entry := ledger.Payment(payment)
+
+	require.NoError(t, ledger.Validate(entry))
+	_, credit := postingOf(entry, ledger.AccountCustomerCredit)
+	assert.True(t, decimal.NewFromInt(200).Equal(credit))
+	_, fee := postingOf(entry, ledger.AccountFeeIncome)
+	assert.True(t, decimal.NewFromInt(50).Equal(fee))
+}
+
+func TestLedger_ReverseAndAmendment(t *testing.T) {
+	transaction := &entity.Transaction{
+		ID:        1,
+		OnTheRoad: decimal.NewFromInt(8000),
+		AdminFee:  decimal.NewFromInt(500),
+	}
+
+	cancellation := ledger.Cancellation(transaction)
+	require.NoError(t, ledger.Validate(cancellation))
+	_, credit := postingOf(cancellation, ledger.AccountLoanReceivable)
+	assert.True(t, decimal.NewFromInt(8500).Equal(credit))
+
+	// Lower otr is posted on the opposite side
+	amendment := ledger.Amendment(transaction, decimal.NewFromInt(10000), decimal.NewFromInt(500))
+	require.NoError(t, ledger.Validate(amendment))
+	debit, _ := postingOf(amendment, ledger.AccountCash)
+	assert.True(t, decimal.NewFromInt(2000).Equal(debit))
+
+	unchanged := ledger.Amendment(transaction, transaction.OnTheRoad, transaction.AdminFee)
+	assert.Empty(t, unchanged.Postings)
+	assert.Error(t, ledger.Validate(unchanged))
+}
+
+func TestLedger_CancelAfterReversedPayment(t *testing.T) {
+	transaction := &entity.Transaction{
+		ID:        1,
+		OnTheRoad: decimal.NewFromInt(8000),
+		AdminFee:  decimal.NewFromInt(500),
+	}
+	payment := &entity.Payment{
+		ID:     1,
+		Type:   entity.PaymentTypePayment,
+		Amount: decimal.NewFromInt(1000),
+		Allocations: []entity.PaymentAllocation{
+			{InterestAmount: decimal.NewFromInt(150), PrincipalAmount: decimal.NewFromInt(850)},
+		},
+	}
+
+	// Payment still effective block the cancellation
+	assert.True(t, payment.IsEffective())
+
+	entries := []*entity.JournalEntry{ledger.Disbursement(transaction), ledger.Payment(payment)}
+	entries = append(entries, ledger.Reverse(entries[1], ledger.SourcePayment, 2, "Reversal"))
+	payment.ReversedAt.Valid = true
+	assert.False(t, payment.IsEffective())
+	assert.False(t, (&entity.Payment{Type: entity.PaymentTypeReversal}).IsEffective())
+
+	entries = append(entries, ledger.Cancellation(transaction))
+
+	// Once reversed the cancellation bring every account back to zero
+	for _, account := range []string{ledger.AccountLoanReceivable, ledger.AccountCash, ledger.AccountInterestIncome, ledger.AccountFeeIncome} {
+		balance := decimal.Zero
+		for _, entry := range entries {
+			require.NoError(t, ledger.Validate(entry))
+			debit, credit := postingOf(entry, account)
+			balance = balance.Add(debit).Sub(credit)
+		}
+		assert.True(t, balance.IsZero(), "account %s", account)
+	}
+}
@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitPolicy_EligibleBySalaryAndAge(t *testing.T) {
+	min_age, max_age := uint(21), uint(55)
+	policy := &entity.LimitPolicy{
+		MinSalary: decimal.NewNullDecimal(decimal.NewFromInt(5000000)),
+		MaxSalary: decimal.NewNullDecimal(decimal.NewFromInt(10000000)),
+		MinAge:    &min_age,
+		MaxAge:    &max_age,
+	}
+
+	assert.True(t, policy.Eligible(decimal.NewFromInt(5000000), 21))
+	assert.True(t, policy.Eligible(decimal.NewFromInt(10000000), 55))
+	assert.False(t, policy.Eligible(decimal.NewFromInt(4999999), 30))
+	assert.False(t, policy.Eligible(decimal.NewFromInt(7000000), 56))
+	assert.True(t, (&entity.LimitPolicy{}).Eligible(decimal.Zero, 0))
+}
+
+func TestUserProfile_AgeBeforeAndAfterBirthday(t *testing.T) {
+	profile := &entity.UserProfile{BirthDate: time.Date(2000, 3, 15, 0, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, uint(25), profile.Age(time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, uint(26), profile.Age(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)))
+}
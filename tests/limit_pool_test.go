@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitPoolMode_Consumes(t *testing.T) {
+	// Shared pool use every limit
+	assert.True(t, entity.LimitPoolShared.Consumes(3, 6))
+	assert.True(t, entity.LimitPoolShared.Consumes(6, 6))
+
+	// Per tenor only use the limit of the transaction tenor
+	assert.False(t, entity.LimitPoolPerTenor.Consumes(3, 6))
+	assert.True(t, entity.LimitPoolPerTenor.Consumes(6, 6))
+	assert.False(t, entity.LimitPoolPerTenor.Consumes(entity.GlobalLimitTenor, 6))
+
+	// Global cap use the tenor sub cap and the global limit
+	assert.False(t, entity.LimitPoolGlobalCap.Consumes(3, 6))
+	assert.True(t, entity.LimitPoolGlobalCap.Consumes(6, 6))
+	assert.True(t, entity.LimitPoolGlobalCap.Consumes(entity.GlobalLimitTenor, 6))
+}
+
+func TestLimitPolicy_LimitItemsAddGlobalCap(t *testing.T) {
+	policy := entity.LimitPolicy{
+		PoolMode:  entity.LimitPoolGlobalCap,
+		GlobalCap: decimal.NewNullDecimal(decimal.NewFromInt(1000000)),
+		Items: []entity.LimitPolicyItem{
+			{Tenor: 3, Amount: decimal.NewFromInt(500000)},
+			{Tenor: 6, Amount: decimal.NewFromInt(700000)},
+		},
+	}
+
+	items := policy.LimitItems()
+	assert.Len(t, items, 3)
+	assert.Equal(t, entity.GlobalLimitTenor, items[2].Tenor)
+	assert.True(t, decimal.NewFromInt(1000000).Equal(items[2].Amount))
+	assert.Len(t, policy.Items, 2)
+
+	policy.PoolMode = entity.LimitPoolPerTenor
+	assert.Len(t, policy.LimitItems(), 2)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createOverdueTestTransaction(t *testing.T, contract_number string, status entity.TransactionStatus, due_date time.Time) entity.TransactionInstallment {
+	transaction := entity.Transaction{
+		UserID:             2,
+		AssetName:          "Television",
+		ContractNumber:     contract_number,
+		OnTheRoad:          decimal.NewFromInt(3000000),
+		AdminFee:           decimal.Zero,
+		MonthlyInstallment: decimal.NewFromInt(1000000),
+		InterestAmount:     decimal.Zero,
+		Tenor:              3,
+		StartDate:          due_date.AddDate(0, -1, 0),
+		Status:             status,
+	}
+	require.NoError(t, TestDB.Create(&transaction).Error)
+
+	installment := entity.TransactionInstallment{
+		TransactionID:     transaction.ID,
+		InstallmentNumber: 1,
+		DueDate:           due_date,
+		AmountDue:         decimal.NewFromInt(1000000),
+		AmountPaid:        decimal.Zero,
+		PaymentStatus:     entity.PaymentStatusPending,
+	}
+	require.NoError(t, TestDB.Create(&installment).Error)
+
+	return installment
+}
+
+func TestInstallmentRepository_MarkOverdue(t *testing.T) {
+	as_of := time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC)
+	active := createOverdueTestTransaction(t, "TEST/OVERDUE/ACTIVE", entity.TransactionActive, as_of.AddDate(0, 0, -10))
+	notDue := createOverdueTestTransaction(t, "TEST/OVERDUE/NOTDUE", entity.TransactionActive, as_of)
+	canceled := createOverdueTestTransaction(t, "TEST/OVERDUE/CANCELED", entity.TransactionCanceled, as_of.AddDate(0, 0, -10))
+
+	installmentRepository := repository.NewIntallmentRepository(TestDB)
+	_, err := installmentRepository.MarkOverdue(context.Background(), as_of)
+	require.NoError(t, err)
+
+	var installment entity.TransactionInstallment
+	TestDB.First(&installment, active.ID)
+	assert.Equal(t, entity.PaymentStatusOverdue, installment.PaymentStatus)
+	assert.Equal(t, uint(10), installment.DaysPastDue)
+
+	// Due on as_of itself is not past due yet
+	TestDB.First(&installment, notDue.ID)
+	assert.Equal(t, entity.PaymentStatusPending, installment.PaymentStatus)
+	assert.Equal(t, uint(0), installment.DaysPastDue)
+
+	// Cancelled contract is not repaid anymore
+	TestDB.First(&installment, canceled.ID)
+	assert.Equal(t, entity.PaymentStatusPending, installment.PaymentStatus)
+
+	// Running again a day later only refresh the days past due
+	_, err = installmentRepository.MarkOverdue(context.Background(), as_of.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	TestDB.First(&installment, active.ID)
+	assert.Equal(t, uint(11), installment.DaysPastDue)
+
+	overdue, err := installmentRepository.FindAllOverdue(context.Background())
+	require.NoError(t, err)
+	for _, item := range *overdue {
+		assert.NotEqual(t, canceled.ID, item.ID)
+	}
+}
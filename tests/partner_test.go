@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartner_SecretEncryptedAtRest(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	secret := "partner-secret"
+
+	sealed, err := helpers.EncryptSecret(key, secret)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, secret)
+
+	// Random nonce, the same secret is never stored twice the same way
+	again, err := helpers.EncryptSecret(key, secret)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	opened, err := helpers.DecryptSecret(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, secret, opened)
+
+	// The stored value is not a signing key and does not open under another key
+	other := make([]byte, 32)
+	_, err = helpers.DecryptSecret(other, sealed)
+	assert.Error(t, err)
+}
+
+func TestPartner_Signature(t *testing.T) {
+	secret := "partner-secret"
+	sealed, err := helpers.EncryptSecret(make([]byte, 32), secret)
+	require.NoError(t, err)
+
+	body := []byte(`{"customer_uuid":"7c9e6679-7425-40de-944b-e07fc1f90ae7"}`)
+	payload := helpers.PartnerSigningPayload("POST", "/api/v1/partner/transactions", "1700000000", "n-1", body)
+	assert.Equal(t, "POST\n/api/v1/partner/transactions\n1700000000\nn-1\n"+string(body), string(payload))
+
+	signature := helpers.SignPayload(secret, payload)
+	assert.True(t, helpers.ValidSignature(secret, payload, signature))
+
+	// Signing with the stored value or a changed nonce does not verify
+	assert.False(t, helpers.ValidSignature(secret, payload, helpers.SignPayload(sealed, payload)))
+	replayed := helpers.PartnerSigningPayload("POST", "/api/v1/partner/transactions", "1700000000", "n-2", body)
+	assert.False(t, helpers.ValidSignature(secret, replayed, signature))
+}
+
+func TestPartner_IsActive(t *testing.T) {
+	revoked_at := time.Now()
+
+	assert.True(t, (&entity.PartnerKey{}).IsActive())
+	assert.False(t, (&entity.PartnerKey{RevokedAt: &revoked_at}).IsActive())
+	assert.True(t, (&entity.PartnerConsent{}).IsActive())
+	assert.False(t, (&entity.PartnerConsent{RevokedAt: &revoked_at}).IsActive())
+}
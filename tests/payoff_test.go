@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/allocation"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPayoffInstallments(now time.Time) []entity.TransactionInstallment {
+	installments := []entity.TransactionInstallment{
+		newTestInstallment(1, 1000, 100),
+		newTestInstallment(2, 1000, 100),
+		newTestInstallment(3, 1000, 100),
+	}
+	installments[0].DueDate = now.AddDate(0, 0, -3)
+	installments[0].Penalties = []entity.InstallmentPenalty{
+		{ID: 11, Amount: decimal.NewFromInt(30), AmountPaid: decimal.NewFromInt(10)},
+	}
+	installments[1].DueDate = now.AddDate(0, 1, 0)
+	installments[2].DueDate = now.AddDate(0, 2, 0)
+
+	return installments
+}
+
+func TestPayoff_Quote(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	// 50% rebate on interest not yet due, 2% termination fee
+	payoff := allocation.QuotePayoff(newTestPayoffInstallments(now), now, decimal.NewFromInt(50), decimal.NewFromInt(2))
+
+	assert.True(t, decimal.NewFromInt(3000).Equal(payoff.RemainingPrincipal))
+	assert.True(t, decimal.NewFromInt(200).Equal(payoff.AccruedInterest))
+	assert.True(t, decimal.NewFromInt(100).Equal(payoff.InterestRebate))
+	assert.True(t, decimal.NewFromInt(20).Equal(payoff.OutstandingPenalty))
+	assert.True(t, decimal.NewFromInt(60).Equal(payoff.TerminationFee))
+	assert.True(t, decimal.NewFromInt(3280).Equal(payoff.TotalPayoff))
+
+	// Overdue installment keep its full interest and settle its penalty
+	require.Len(t, payoff.Lines, 3)
+	assert.True(t, decimal.NewFromInt(1120).Equal(payoff.Lines[0].Amount))
+	assert.True(t, decimal.NewFromInt(1050).Equal(payoff.Lines[1].Amount))
+	for _, line := range payoff.Lines {
+		assert.True(t, line.Settled)
+	}
+}
+
+func TestPayoff_UnderPaymentIsNotCovered(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	payoff := allocation.QuotePayoff(newTestPayoffInstallments(now), now, decimal.NewFromInt(50), decimal.NewFromInt(2))
+
+	assert.False(t, payoff.Covered(decimal.NewFromInt(3279)))
+	assert.True(t, payoff.Excess(decimal.NewFromInt(3279)).IsZero())
+
+	assert.True(t, payoff.Covered(decimal.NewFromInt(3280)))
+	assert.True(t, payoff.Excess(decimal.NewFromInt(3280)).IsZero())
+}
+
+func TestPayoff_OverPaymentKeptAsCredit(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	payoff := allocation.QuotePayoff(newTestPayoffInstallments(now), now, decimal.NewFromInt(50), decimal.NewFromInt(2))
+
+	amount := decimal.NewFromInt(3500)
+	excess := payoff.Excess(amount)
+	assert.True(t, decimal.NewFromInt(220).Equal(excess))
+
+	// The full amount is recorded, the ledger credit the excess and charge only the fee
+	payment := &entity.Payment{ID: 1, Amount: amount, Credit: &entity.CustomerCredit{Amount: excess}}
+	for _, line := range payoff.Lines {
+		payment.Allocations = append(payment.Allocations, entity.PaymentAllocation{
+			InstallmentID:   line.InstallmentID,
+			PenaltyAmount:   line.Penalty,
+			InterestAmount:  line.Interest,
+			PrincipalAmount: line.Principal,
+			Amount:          line.Amount,
+		})
+	}
+
+	entry := ledger.Payment(payment)
+
+	require.NoError(t, ledger.Validate(entry))
+	cash, _ := postingOf(entry, ledger.AccountCash)
+	assert.True(t, amount.Equal(cash))
+	_, credit := postingOf(entry, ledger.AccountCustomerCredit)
+	assert.True(t, decimal.NewFromInt(220).Equal(credit))
+	_, fee := postingOf(entry, ledger.AccountFeeIncome)
+	assert.True(t, payoff.TerminationFee.Equal(fee))
+}
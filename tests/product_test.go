@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduct_AdminFee(t *testing.T) {
+	otr := decimal.NewFromInt(3000000)
+
+	flat := entity.Product{AdminFeeType: entity.AdminFeeFlat, AdminFeeAmount: decimal.NewFromInt(50000)}
+	assert.True(t, decimal.NewFromInt(50000).Equal(flat.AdminFee(otr)))
+
+	percentage := entity.Product{AdminFeeType: entity.AdminFeePercentage, AdminFeeRate: decimal.NewFromFloat(1.5)}
+	assert.True(t, decimal.NewFromInt(45000).Equal(percentage.AdminFee(otr)))
+
+	tiered := entity.Product{
+		AdminFeeType: entity.AdminFeeTiered,
+		FeeTiers: []entity.ProductFeeTier{
+			{MinOnTheRoad: decimal.NewFromInt(5000000), Amount: decimal.NewFromInt(100000)},
+			{MinOnTheRoad: decimal.NewFromInt(1000000), Amount: decimal.NewFromInt(25000)},
+			{MinOnTheRoad: decimal.NewFromInt(2000000), Amount: decimal.NewFromInt(40000)},
+		},
+	}
+	assert.True(t, decimal.NewFromInt(40000).Equal(tiered.AdminFee(otr)))
+	assert.True(t, decimal.Zero.Equal(tiered.AdminFee(decimal.NewFromInt(500000))))
+}
+
+func TestProduct_AllowTenorAndOnTheRoad(t *testing.T) {
+	product := entity.Product{
+		MinOnTheRoad: decimal.NewFromInt(1000000),
+		MaxOnTheRoad: decimal.NewNullDecimal(decimal.NewFromInt(5000000)),
+		Tenors:       []entity.ProductTenor{{Tenor: 3}, {Tenor: 6}},
+	}
+
+	assert.True(t, product.AllowTenor(6))
+	assert.False(t, product.AllowTenor(12))
+	assert.True(t, product.AllowOnTheRoad(decimal.NewFromInt(5000000)))
+	assert.False(t, product.AllowOnTheRoad(decimal.NewFromInt(999999)))
+	assert.False(t, product.AllowOnTheRoad(decimal.NewFromInt(5000001)))
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/allocation"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/ledger"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/limit"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverse_RecomputePaidAmountAndStatus(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	// Paid in full by the payment, including its two penalties
+	installment := newTestInstallment(1, 900, 100)
+	installment.AmountPaid = decimal.NewFromInt(1000)
+	installment.PaymentStatus = entity.PaymentStatusPaid
+	installment.PaidAt = sql.NullTime{Time: now, Valid: true}
+	installment.DueDate = now.AddDate(0, 0, 5)
+	installment.Penalties = []entity.InstallmentPenalty{
+		{ID: 2, Amount: decimal.NewFromInt(20), AmountPaid: decimal.NewFromInt(20), AccrualDate: now.AddDate(0, 0, -1)},
+		{ID: 1, Amount: decimal.NewFromInt(20), AmountPaid: decimal.NewFromInt(20), AccrualDate: now.AddDate(0, 0, -2)},
+	}
+
+	// Only part of the payment was on this installment
+	line := entity.PaymentAllocation{
+		InstallmentID:   1,
+		PenaltyAmount:   decimal.NewFromInt(30),
+		InterestAmount:  decimal.NewFromInt(100),
+		PrincipalAmount: decimal.NewFromInt(500),
+	}
+	reopened := allocation.Reverse(&installment, line, now)
+
+	// Penalty accrued last is reopened first
+	require.Len(t, reopened, 2)
+	assert.Equal(t, uint(2), reopened[0].ID)
+	assert.True(t, reopened[0].AmountPaid.IsZero())
+	assert.Equal(t, uint(1), reopened[1].ID)
+	assert.True(t, decimal.NewFromInt(10).Equal(reopened[1].AmountPaid))
+
+	assert.True(t, decimal.NewFromInt(400).Equal(installment.AmountPaid))
+	assert.Equal(t, entity.PaymentStatusPartial, installment.PaymentStatus)
+	assert.False(t, installment.PaidAt.Valid)
+
+	// Past due installment go back to overdue, nothing left paid never go negative
+	installment.DueDate = now.AddDate(0, 0, -5)
+	allocation.Reverse(&installment, entity.PaymentAllocation{PrincipalAmount: decimal.NewFromInt(1000)}, now)
+	assert.True(t, installment.AmountPaid.IsZero())
+	assert.Equal(t, entity.PaymentStatusOverdue, installment.PaymentStatus)
+
+	installment.DueDate = now.AddDate(0, 0, 5)
+	assert.Equal(t, entity.PaymentStatusPending, allocation.ReversedStatus(&installment, now))
+}
+
+func TestReverse_ReopenPaidContractTakeLimitBack(t *testing.T) {
+	assert.True(t, entity.TransactionPaid.CanTransitionTo(entity.TransactionActive))
+
+	transaction := &entity.Transaction{Status: entity.TransactionPaid}
+	statusLog, err := transaction.Transition(entity.TransactionActive, "Contract reopened by payment reversal", nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.TransactionActive, transaction.Status)
+	assert.Equal(t, entity.TransactionPaid, statusLog.FromStatus)
+
+	// Limit given back on payoff is taken again under the contract pool mode, even frozen
+	limits := []entity.Limit{
+		newTestLimit(3, 6000000, 6000000, entity.LimitPoolPerTenor),
+		newTestLimit(6, 10000000, 3000000, entity.LimitPoolPerTenor),
+	}
+	limits[1].FrozenAt.Valid = true
+
+	updated := limit.TakeBack(limits, 6, entity.LimitPoolPerTenor, decimal.NewFromInt(5000000))
+	assert.True(t, decimal.NewFromInt(6000000).Equal(updated[0].CurrentLimit))
+	assert.True(t, updated[1].CurrentLimit.IsZero())
+	assert.True(t, updated[1].FrozenAt.Valid)
+
+	updated = limit.TakeBack(limits, 6, entity.LimitPoolShared, decimal.NewFromInt(2000000))
+	assert.True(t, decimal.NewFromInt(4000000).Equal(updated[0].CurrentLimit))
+	assert.True(t, decimal.NewFromInt(1000000).Equal(updated[1].CurrentLimit))
+}
+
+func TestReverse_OppositeLedgerEntry(t *testing.T) {
+	payment := &entity.Payment{
+		ID:     5,
+		Amount: decimal.NewFromInt(1030),
+		Allocations: []entity.PaymentAllocation{{
+			PenaltyAmount:   decimal.NewFromInt(30),
+			InterestAmount:  decimal.NewFromInt(100),
+			PrincipalAmount: decimal.NewFromInt(900),
+		}},
+	}
+
+	entry := ledger.Payment(payment)
+	reversed := ledger.Reverse(entry, ledger.SourcePayment, 6, "Reversal of payment")
+
+	require.NoError(t, ledger.Validate(reversed))
+	assert.Equal(t, uint(6), reversed.SourceID)
+	require.Len(t, reversed.Postings, len(entry.Postings))
+	for i, posting := range entry.Postings {
+		assert.Equal(t, posting.AccountCode, reversed.Postings[i].AccountCode)
+		assert.True(t, posting.Debit.Equal(reversed.Postings[i].Credit))
+		assert.True(t, posting.Credit.Equal(reversed.Postings[i].Debit))
+	}
+
+	_, cash := postingOf(reversed, ledger.AccountCash)
+	assert.True(t, decimal.NewFromInt(1030).Equal(cash))
+	receivable, _ := postingOf(reversed, ledger.AccountLoanReceivable)
+	assert.True(t, decimal.NewFromInt(900).Equal(receivable))
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/scoring"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoring_NewUserWithCompleteProfileIsGradeC(t *testing.T) {
+	as_of := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	score := scoring.Calculate(scoring.Input{
+		Profile: &entity.UserProfile{
+			BirthDate: time.Date(1995, 5, 1, 0, 0, 0, 0, time.UTC),
+			Salary:    decimal.NewNullDecimal(decimal.NewFromInt(7000000)),
+		},
+		Document: &entity.UserDocument{KtpFile: "ktp.jpg", SelfieFile: "selfie.jpg"},
+		AsOf:     as_of,
+	})
+
+	// base 250 + income 120 + age 80 + document 100 + no history 80
+	assert.Equal(t, uint(630), score.Score)
+	assert.Equal(t, entity.CreditGradeC, score.Grade)
+	assert.True(t, decimal.NewFromInt(1).Equal(score.LimitMultiplier))
+	assert.Len(t, score.Factors, 5)
+}
+
+func TestScoring_OverdueInstallmentLowerScore(t *testing.T) {
+	as_of := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	installments := []entity.TransactionInstallment{
+		{DueDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), PaidAt: sql.NullTime{Time: time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC), Valid: true}},
+		{DueDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), PaymentStatus: entity.PaymentStatusOverdue},
+		{DueDate: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), PaymentStatus: entity.PaymentStatusPending},
+	}
+
+	score := scoring.Calculate(scoring.Input{Installments: installments, AsOf: as_of})
+
+	for _, factor := range score.Factors {
+		switch factor.Code {
+		case scoring.FactorRepayment:
+			assert.Equal(t, "1/2", factor.Value)
+			assert.Equal(t, 100, factor.Points)
+		case scoring.FactorOverdue:
+			assert.Equal(t, -60, factor.Points)
+		}
+	}
+	assert.Equal(t, entity.CreditGradeE, score.Grade)
+}
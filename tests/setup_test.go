@@ -57,9 +57,34 @@ func teardownTestEnvironment() {
 		&entity.UserDocument{},
 		&entity.RefreshToken{},
 		&entity.Limit{},
+		&entity.CheckoutSession{},
 		&entity.Transaction{},
 		&entity.TransactionInstallment{},
 		&entity.Payment{},
+		&entity.TransactionRevision{},
+		&entity.TransactionStatusLog{},
+		&entity.Disbursement{},
+		&entity.InstallmentPenalty{},
+		&entity.PenaltyPolicy{},
+		&entity.PaymentAllocation{},
+		&entity.CustomerCredit{},
+		&entity.PaymentCallback{},
+		&entity.JournalPosting{},
+		&entity.JournalEntry{},
+		&entity.LedgerAccount{},
+		&entity.LimitChange{},
+		&entity.LimitPolicyItem{},
+		&entity.LimitPolicy{},
+		&entity.CreditScoreFactor{},
+		&entity.CreditScore{},
+		&entity.ProductFeeTier{},
+		&entity.ProductTenor{},
+		&entity.Product{},
+		&entity.ContractSequence{},
+		&entity.Holiday{},
+		&entity.PartnerConsent{},
+		&entity.PartnerKey{},
+		&entity.Merchant{},
 	); err != nil {
 		panic("failed to clean database: " + err.Error())
 	}
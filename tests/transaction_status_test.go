@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionStatus_Transition(t *testing.T) {
+	admin_id := uint(1)
+	transaction := &entity.Transaction{ID: 7, Status: entity.TransactionActive}
+
+	statusLog, err := transaction.Transition(entity.TransactionDefaulted, "Three installment overdue", &admin_id)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TransactionDefaulted, transaction.Status)
+	assert.Equal(t, uint(7), statusLog.TransactionID)
+	assert.Equal(t, entity.TransactionActive, statusLog.FromStatus)
+	assert.Equal(t, entity.TransactionDefaulted, statusLog.ToStatus)
+
+	// Illegal move keep the status
+	_, err = transaction.Transition(entity.TransactionCanceled, "Cancel", nil)
+	assert.Error(t, err)
+	assert.Equal(t, entity.TransactionDefaulted, transaction.Status)
+
+	_, err = transaction.Transition(entity.TransactionWrittenOff, "Uncollectible", nil)
+	assert.NoError(t, err)
+	assert.False(t, transaction.Status.CanTransitionTo(entity.TransactionActive))
+	assert.False(t, transaction.Status.IsRepaying())
+}
+
+func TestTransactionStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, entity.TransactionDraft.CanTransitionTo(entity.TransactionPendingApproval))
+	assert.True(t, entity.TransactionApproved.CanTransitionTo(entity.TransactionActive))
+	assert.True(t, entity.TransactionPaid.CanTransitionTo(entity.TransactionActive))
+	assert.False(t, entity.TransactionDraft.CanTransitionTo(entity.TransactionActive))
+	assert.False(t, entity.TransactionCanceled.CanTransitionTo(entity.TransactionActive))
+	assert.False(t, entity.TransactionPaid.CanTransitionTo(entity.TransactionCanceled))
+}
@@ -4,7 +4,9 @@ import (
 	"log"
 	"testing"
 
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/contract"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -24,10 +26,10 @@ func TestCreateTransaction_Success(t *testing.T) {
 
 	// Create request
 	input := model.TransactionInput{
-		AssetName: "Refrigerator",
-		OnTheRoad: "5000000",
-		AdminFee:  "50000",
-		Tenor:     6,
+		AssetName:   "Refrigerator",
+		OnTheRoad:   "5000000",
+		ProductCode: "DEFAULT",
+		Tenor:       6,
 	}
 
 	recorder := MakeRequest(t, "POST", "/api/v1/transactions/data", input, token)
@@ -46,6 +48,8 @@ func TestCreateTransaction_Success(t *testing.T) {
 	TestDB.Where("user_id = ?", 2).First(&transaction)
 
 	assert.Equal(t, "Refrigerator", transaction.AssetName)
+	assert.Equal(t, contract.Render(config.AppConfig.ContractNumberFormat, config.AppConfig.ContractBranch, transaction.StartDate, 1),
+		transaction.ContractNumber)
 	assert.True(t, decimal.NewFromInt(5000000).Equal(transaction.OnTheRoad))
 
 	// Verify limit updated
@@ -54,4 +58,67 @@ func TestCreateTransaction_Success(t *testing.T) {
 
 	expectedLimit := decimal.NewFromInt(10000000).Sub(decimal.NewFromInt(5000000))
 	assert.True(t, expectedLimit.Equal(updatedLimit.CurrentLimit))
+
+	// Verify limit movement recorded
+	var movement entity.LimitChange
+	TestDB.Where("limit_id = ? AND action = ?", limit.ID, entity.LimitTransactionCreate).First(&movement)
+
+	assert.True(t, decimal.NewFromInt(10000000).Equal(movement.PreviousCurrentLimit))
+	assert.True(t, expectedLimit.Equal(movement.CurrentLimit))
+	if assert.NotNil(t, movement.TransactionUUID) {
+		assert.Equal(t, transaction.UUID, *movement.TransactionUUID)
+	}
+}
+
+func TestSimulateTransaction_DoesNotWrite(t *testing.T) {
+	limit := entity.Limit{
+		UserID:        2,
+		Tenor:         3,
+		CurrentLimit:  decimal.NewFromInt(3000000),
+		OriginalLimit: decimal.NewFromInt(3000000),
+	}
+	TestDB.Create(&limit)
+
+	product := entity.Product{
+		Code:           "SIM-ANNUITY",
+		Name:           "Simulation annuity",
+		InterestMethod: entity.InterestAnnuity,
+		InterestRate:   decimal.NewFromInt(2),
+		AdminFeeType:   entity.AdminFeePercentage,
+		AdminFeeRate:   decimal.NewFromFloat(2.5),
+		IsActive:       true,
+		Tenors:         []entity.ProductTenor{{Tenor: 3}},
+	}
+	TestDB.Create(&product)
+
+	var totalBefore int64
+	TestDB.Model(&entity.Transaction{}).Count(&totalBefore)
+
+	token := GenerateUserTestToken()
+
+	input := model.TransactionInput{
+		AssetName:   "Television",
+		OnTheRoad:   "1200000",
+		ProductCode: product.Code,
+		Tenor:       3,
+	}
+
+	recorder := MakeRequest(t, "POST", "/api/v1/transactions/simulate", input, token)
+
+	assert.Equal(t, 200, recorder.Code)
+	response := ParseResponse(t, recorder)
+	assert.True(t, response.Success)
+
+	// Admin fee and interest come from the product, 2.5% of otr
+	simulation := response.Data.(map[string]interface{})
+	assert.Equal(t, "30000", simulation["admin_fee"])
+	assert.Equal(t, "annuity", simulation["interest_method"])
+
+	var totalAfter int64
+	TestDB.Model(&entity.Transaction{}).Count(&totalAfter)
+	assert.Equal(t, totalBefore, totalAfter)
+
+	var unchangedLimit entity.Limit
+	TestDB.Where("id = ?", limit.ID).First(&unchangedLimit)
+	assert.True(t, decimal.NewFromInt(3000000).Equal(unchangedLimit.CurrentLimit))
 }
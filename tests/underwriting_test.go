@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Review lock the limit and the transaction, so the service need a working lock client
+func newUnderwritingTestService() service.TransactionService {
+	return service.NewTransactionService(
+		repository.NewTransactionRepository(TestDB),
+		repository.NewUserRepository(TestDB),
+		repository.NewLimitRepository(TestDB),
+		repository.NewIntallmentRepository(TestDB),
+		repository.NewLedgerRepository(TestDB),
+		repository.NewProductRepository(TestDB),
+		repository.NewHolidayRepository(TestDB),
+		repository.NewCreditScoreRepository(TestDB),
+		repository.NewMerchantRepository(TestDB),
+		repository.NewDisbursementRepository(TestDB),
+		repository.NewPartnerConsentRepository(TestDB),
+		repository.NewCheckoutSessionRepository(TestDB),
+		redis.NewLockClient(config.AppConfig),
+	)
+}
+
+func reviewerContext(user_id uint) context.Context {
+	ctx := context.WithValue(context.Background(), helpers.CtxKeyUserID, float64(user_id))
+	return context.WithValue(ctx, helpers.CtxKeyIsAdmin, true)
+}
+
+// createPendingTestTransaction book a contract of user 2 waiting for approval with its otr
+// held on a per tenor limit, every test use its own tenor
+func createPendingTestTransaction(t *testing.T, contract_number string, tenor uint, created_by uint) (entity.Transaction, entity.Limit) {
+	otr := decimal.NewFromInt(3000000)
+	limit := entity.Limit{
+		UserID:        2,
+		Tenor:         tenor,
+		CurrentLimit:  decimal.NewFromInt(10000000).Sub(otr),
+		OriginalLimit: decimal.NewFromInt(10000000),
+		PoolMode:      entity.LimitPoolPerTenor,
+	}
+	require.NoError(t, TestDB.Create(&limit).Error)
+
+	transaction := entity.Transaction{
+		UserID:         2,
+		AssetName:      "Laptop",
+		ContractNumber: contract_number,
+		OnTheRoad:      otr,
+		AdminFee:       decimal.Zero,
+		InterestMethod: entity.InterestFlat,
+		InterestRate:   decimal.Zero,
+		Tenor:          tenor,
+		StartDate:      time.Now().AddDate(0, 0, -3),
+		LimitPoolMode:  entity.LimitPoolPerTenor,
+		CreatedBy:      &created_by,
+		Status:         entity.TransactionPendingApproval,
+	}
+	require.NoError(t, TestDB.Create(&transaction).Error)
+
+	return transaction, limit
+}
+
+func TestReview_CreatorCanNotApproveOwnContract(t *testing.T) {
+	transaction, _ := createPendingTestTransaction(t, "TEST/REVIEW/SELF", 7, 1)
+
+	response := newUnderwritingTestService().Review(reviewerContext(1),
+		&model.TransactionReviewInput{Decision: model.ReviewApprove, Notes: "Documents verified"}, transaction.UUID)
+	assert.Equal(t, 403, response.Status)
+	assert.Equal(t, "Creator can not review own contract", response.Message)
+
+	var current entity.Transaction
+	TestDB.First(&current, transaction.ID)
+	assert.Equal(t, entity.TransactionPendingApproval, current.Status)
+}
+
+func TestReview_RejectReleaseHeldLimit(t *testing.T) {
+	transaction, limit := createPendingTestTransaction(t, "TEST/REVIEW/REJECT", 8, 2)
+
+	response := newUnderwritingTestService().Review(reviewerContext(1),
+		&model.TransactionReviewInput{Decision: model.ReviewReject, Notes: "Income not verified"}, transaction.UUID)
+	require.Equal(t, 200, response.Status, response.Message)
+
+	var current entity.Transaction
+	TestDB.First(&current, transaction.ID)
+	assert.Equal(t, entity.TransactionCanceled, current.Status)
+
+	var released entity.Limit
+	TestDB.First(&released, limit.ID)
+	assert.True(t, limit.OriginalLimit.Equal(released.CurrentLimit), released.CurrentLimit.String())
+
+	var installments int64
+	TestDB.Model(&entity.TransactionInstallment{}).Where("transaction_id = ?", transaction.ID).Count(&installments)
+	assert.Equal(t, int64(0), installments)
+}
+
+func TestReview_ApproveDisburse(t *testing.T) {
+	transaction, limit := createPendingTestTransaction(t, "TEST/REVIEW/APPROVE", 10, 2)
+
+	response := newUnderwritingTestService().Review(reviewerContext(1),
+		&model.TransactionReviewInput{Decision: model.ReviewApprove, Notes: "Documents verified"}, transaction.UUID)
+	require.Equal(t, 200, response.Status, response.Message)
+
+	var current entity.Transaction
+	TestDB.First(&current, transaction.ID)
+	assert.Equal(t, entity.TransactionActive, current.Status)
+	assert.Equal(t, time.Now().Format(time.DateOnly), current.StartDate.Format(time.DateOnly))
+	assert.NotEmpty(t, current.ContractFile)
+
+	var installments int64
+	TestDB.Model(&entity.TransactionInstallment{}).Where("transaction_id = ?", transaction.ID).Count(&installments)
+	assert.Equal(t, int64(transaction.Tenor), installments)
+
+	// Limit stay held by the disbursed contract
+	var held entity.Limit
+	TestDB.First(&held, limit.ID)
+	assert.True(t, limit.CurrentLimit.Equal(held.CurrentLimit), held.CurrentLimit.String())
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useWebhookSecret(t *testing.T, secret string) {
+	previous := config.AppConfig.PaymentWebhookSecret
+	config.AppConfig.PaymentWebhookSecret = secret
+	t.Cleanup(func() { config.AppConfig.PaymentWebhookSecret = previous })
+}
+
+func sendWebhook(t *testing.T, app *fiber.App, body []byte, signature string) int {
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/webhooks/payment", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(middleware.WebhookSignatureHeader, signature)
+	}
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	return resp.StatusCode
+}
+
+func TestWebhook_RejectInvalidSignature(t *testing.T) {
+	useWebhookSecret(t, "webhook-secret")
+
+	app := fiber.New()
+	app.Post("/api/v1/webhooks/payment", middleware.WebhookSignature(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	body := []byte(`{"provider":"xendit","provider_transaction_id":"cb-1","event":"expired"}`)
+
+	assert.Equal(t, fiber.StatusUnauthorized, sendWebhook(t, app, body, ""))
+	assert.Equal(t, fiber.StatusUnauthorized, sendWebhook(t, app, body, helpers.SignPayload("other-secret", body)))
+
+	// Body changed after signing
+	signature := helpers.SignPayload("webhook-secret", body)
+	assert.Equal(t, fiber.StatusUnauthorized, sendWebhook(t, app, append(body, ' '), signature))
+
+	assert.Equal(t, fiber.StatusOK, sendWebhook(t, app, body, signature))
+
+	// No secret configured reject every callback
+	config.AppConfig.PaymentWebhookSecret = ""
+	assert.Equal(t, fiber.StatusUnauthorized, sendWebhook(t, app, body, signature))
+}
+
+func TestWebhook_ExpiredIgnoredAndDeduplicated(t *testing.T) {
+	useWebhookSecret(t, "webhook-secret")
+
+	body, _ := json.Marshal(map[string]string{
+		"provider":                "xendit",
+		"provider_transaction_id": "cb-expired-1",
+		"event":                   "expired",
+		"transaction_uuid":        uuid.NewString(),
+		"amount":                  "150000",
+	})
+	signature := helpers.SignPayload("webhook-secret", body)
+
+	assert.Equal(t, fiber.StatusOK, sendWebhook(t, TestApp, body, signature))
+	assert.Equal(t, fiber.StatusOK, sendWebhook(t, TestApp, body, signature))
+
+	// Retried callback is recorded once
+	var callbacks []entity.PaymentCallback
+	TestDB.Where("provider = ? AND provider_transaction_id = ?", "xendit", "cb-expired-1").Find(&callbacks)
+	require.Len(t, callbacks, 1)
+	assert.Equal(t, entity.PaymentCallbackIgnored, callbacks[0].Status)
+	assert.Nil(t, callbacks[0].PaymentID)
+}
+
+func TestWebhook_UnknownTransactionRejected(t *testing.T) {
+	useWebhookSecret(t, "webhook-secret")
+
+	body, _ := json.Marshal(map[string]string{
+		"provider":                "xendit",
+		"provider_transaction_id": "cb-unknown-1",
+		"event":                   "va.paid",
+		"transaction_uuid":        uuid.NewString(),
+		"amount":                  "150000",
+	})
+	signature := helpers.SignPayload("webhook-secret", body)
+
+	// Rejected callback is answered with success so the gateway stop retrying
+	assert.Equal(t, fiber.StatusOK, sendWebhook(t, TestApp, body, signature))
+
+	var callback entity.PaymentCallback
+	TestDB.Where("provider = ? AND provider_transaction_id = ?", "xendit", "cb-unknown-1").First(&callback)
+	assert.Equal(t, entity.PaymentCallbackRejected, callback.Status)
+	assert.Equal(t, "Transaction Not Found", callback.Message)
+	assert.Nil(t, callback.PaymentID)
+
+	// The same provider transaction id is not posted twice even with another body
+	other, _ := json.Marshal(map[string]string{
+		"provider":                "xendit",
+		"provider_transaction_id": "cb-unknown-1",
+		"event":                   "va.paid",
+		"transaction_uuid":        uuid.NewString(),
+		"amount":                  "990000",
+	})
+	assert.Equal(t, fiber.StatusOK, sendWebhook(t, TestApp, other, helpers.SignPayload("webhook-secret", other)))
+
+	var total int64
+	TestDB.Model(&entity.PaymentCallback{}).Where("provider_transaction_id = ?", "cb-unknown-1").Count(&total)
+	assert.Equal(t, int64(1), total)
+}